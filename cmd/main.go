@@ -1,18 +1,34 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
 	"github.com/skiba-mateusz/ecom-api/internal/app/service"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/auth"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/cache"
 	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
 	"github.com/skiba-mateusz/ecom-api/internal/infra/handler/http"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/imaging"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/mail"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/payment"
 	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
 	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/storage"
 	"go.uber.org/zap"
+	"time"
 )
 
 func main() {
 	cfg := config.Load()
 	logger := zap.Must(zap.NewProduction()).Sugar()
 
+	if err := domain.SetMoneyFormat(domain.MoneyFormat(cfg.Money.Format)); err != nil {
+		logger.Fatal(err)
+	}
+
 	db, err := postgres.New(
 		cfg.Database.Addr,
 		cfg.Database.MaxOpenConns,
@@ -27,15 +43,238 @@ func main() {
 
 	productRepo := repository.NewProductRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
+	brandRepo := repository.NewBrandRepository(db)
+	variantRepo := repository.NewVariantRepository(db)
+	attributeRepo := repository.NewAttributeRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	imageRepo := repository.NewImageRepository(db)
+	cartRepo := repository.NewCartRepository(db)
+	orderRepo := repository.NewOrderRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	apiKeyRepo := repository.NewApiKeyRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	transactor := postgres.NewTransactor(db)
+
+	fileStorage := storage.NewLocalStorage(cfg.Storage.LocalDir, cfg.Storage.PublicUrl)
+	imageProcessor := imaging.NewProcessor()
+
+	paymentGateway, err := newPaymentGateway(cfg)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	tokenIssuer, err := newTokenIssuer(cfg.Auth)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	var suggestionCache port.SuggestionCache
+	if cfg.Search.SuggestCacheEnabled {
+		ttl, err := time.ParseDuration(cfg.Search.SuggestCacheTTL)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		suggestionCache = cache.NewSuggestionCache(ttl, cfg.Search.SuggestCacheSize)
+	}
+
+	productServ := service.NewProductService(productRepo, categoryRepo, attributeRepo, transactor, suggestionCache)
+	brandServ := service.NewBrandService(brandRepo, productRepo, suggestionCache)
+	categoryServ := service.NewCategoryService(categoryRepo, transactor, suggestionCache)
+	variantServ := service.NewVariantService(variantRepo, productRepo)
+	attributeServ := service.NewAttributeService(attributeRepo, categoryRepo)
+	searchServ := service.NewSearchService(searchRepo, suggestionCache)
+	imageServ := service.NewImageService(imageRepo, productRepo, fileStorage, imageProcessor)
+
+	cartTTL, err := time.ParseDuration(cfg.Cart.TTL)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	cartServ := service.NewCartService(cartRepo, productRepo, cartTTL)
+
+	cartPurgeInterval, err := time.ParseDuration(cfg.Cart.PurgeInterval)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	go purgeExpiredCarts(cartServ, cartPurgeInterval, logger)
 
-	productServ := service.NewProductService(productRepo, categoryRepo)
+	orderServ := service.NewOrderService(orderRepo, cartRepo)
+	paymentServ := service.NewPaymentService(paymentRepo, orderRepo, paymentGateway, transactor)
+
+	refreshTokenTTL, err := time.ParseDuration(cfg.Auth.RefreshTokenTTL)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	accountMail, err := newAccountMail(cfg)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	twoFactorPolicy, err := domain.ParseTwoFactorPolicy(cfg.Auth.TwoFactorRoles)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	userServ := service.NewUserService(userRepo, sessionRepo, userTokenRepo, twoFactorRepo, transactor, tokenIssuer, refreshTokenTTL, accountMail)
+	sessionServ := service.NewSessionService(sessionRepo, userRepo, tokenIssuer, refreshTokenTTL)
+	apiKeyServ := service.NewApiKeyService(apiKeyRepo)
+	accountServ := service.NewAccountService(userRepo, sessionRepo, userTokenRepo, transactor, accountMail)
+	twoFactorServ := service.NewTwoFactorService(twoFactorRepo, userRepo, cfg.Auth.TOTPIssuer, twoFactorPolicy)
+
+	emailTransport, err := newEmailTransport(cfg, logger)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	outboxServ := service.NewOutboxService(outboxRepo, emailTransport)
+
+	mailDispatchInterval, err := time.ParseDuration(cfg.Mail.DispatchInterval)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	go dispatchOutbox(outboxServ, mailDispatchInterval, cfg.Mail.DispatchBatch, logger)
 
 	handlers := &http.Handlers{
-		Health:  http.NewHealthHandler(cfg, logger),
-		Product: http.NewProductHandler(cfg, logger, productServ),
+		Health:    http.NewHealthHandler(cfg, logger),
+		Product:   http.NewProductHandler(cfg, logger, productServ),
+		Brand:     http.NewBrandHandler(cfg, logger, brandServ),
+		Category:  http.NewCategoryHandler(cfg, logger, categoryServ),
+		Variant:   http.NewVariantHandler(cfg, logger, variantServ),
+		Attribute: http.NewAttributeHandler(cfg, logger, attributeServ),
+		Search:    http.NewSearchHandler(cfg, logger, searchServ),
+		Image:     http.NewImageHandler(cfg, logger, imageServ),
+		Cart:      http.NewCartHandler(cfg, logger, cartServ),
+		Order:     http.NewOrderHandler(cfg, logger, orderServ),
+		Payment:   http.NewPaymentHandler(cfg, logger, paymentServ),
+		Auth:      http.NewAuthHandler(cfg, logger, sessionServ, apiKeyServ, twoFactorPolicy),
+		User:      http.NewUserHandler(cfg, logger, userServ, cartServ),
+		Session:   http.NewSessionHandler(cfg, logger, sessionServ),
+		ApiKey:    http.NewApiKeyHandler(cfg, logger, apiKeyServ),
+		Account:   http.NewAccountHandler(cfg, logger, accountServ),
+		TwoFactor: http.NewTwoFactorHandler(cfg, logger, twoFactorServ),
 	}
 
 	server := http.NewServer(cfg, logger, handlers)
 	mux := server.Mount()
 	logger.Fatal(server.Run(mux))
 }
+
+// purgeExpiredCarts deletes abandoned carts every interval.
+func purgeExpiredCarts(cartServ port.CartService, interval time.Duration, logger *zap.SugaredLogger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		purged, err := cartServ.PurgeExpired(context.Background())
+		if err != nil {
+			logger.Errorw("purging expired carts failed", "error", err.Error())
+			continue
+		}
+		if purged > 0 {
+			logger.Infow("purged expired carts", "count", purged)
+		}
+	}
+}
+
+// dispatchOutbox sends queued emails every interval, draining the outbox
+// in batches so a backlog clears without waiting for further ticks.
+func dispatchOutbox(outboxServ port.OutboxService, interval time.Duration, batchSize int, logger *zap.SugaredLogger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for {
+			sent, err := outboxServ.Dispatch(context.Background(), batchSize)
+			if err != nil {
+				logger.Errorw("dispatching outbox failed", "error", err.Error())
+				break
+			}
+			if sent < batchSize {
+				break
+			}
+		}
+	}
+}
+
+func newAccountMail(cfg *config.Config) (domain.AccountMail, error) {
+	resetTTL, err := time.ParseDuration(cfg.Auth.PasswordResetTTL)
+	if err != nil {
+		return domain.AccountMail{}, err
+	}
+
+	verificationTTL, err := time.ParseDuration(cfg.Auth.VerificationTTL)
+	if err != nil {
+		return domain.AccountMail{}, err
+	}
+
+	return domain.AccountMail{
+		AppUrl:               cfg.Mail.AppUrl,
+		PasswordResetTTL:     resetTTL,
+		EmailVerificationTTL: verificationTTL,
+	}, nil
+}
+
+// newEmailTransport picks the configured transport. The file transport
+// would leave live account links on local disk, so production refuses it.
+func newEmailTransport(cfg *config.Config, logger *zap.SugaredLogger) (port.EmailTransport, error) {
+	mailCfg := cfg.Mail
+	switch mailCfg.Transport {
+	case "smtp":
+		return mail.NewSMTPTransport(mailCfg.SMTPHost, mailCfg.SMTPPort, mailCfg.SMTPUsername, mailCfg.SMTPPassword, mailCfg.From), nil
+	case "file":
+		if cfg.Env == "production" {
+			return nil, errors.New("the file mail transport is not allowed in production; set MAIL_TRANSPORT=smtp")
+		}
+		return mail.NewFileTransport(mailCfg.FileDir, mailCfg.From, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mail transport %q", mailCfg.Transport)
+	}
+}
+
+func newTokenIssuer(cfg *config.Auth) (*auth.JWTIssuer, error) {
+	ttl, err := time.ParseDuration(cfg.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.TokenAlgorithm {
+	case "HS256":
+		if cfg.TokenSecret == "" {
+			return nil, errors.New("AUTH_TOKEN_SECRET must be set for HS256")
+		}
+		return auth.NewHS256Issuer([]byte(cfg.TokenSecret), cfg.TokenIssuer, ttl), nil
+	case "RS256":
+		if cfg.TokenPrivateKeyFile == "" {
+			return nil, errors.New("AUTH_TOKEN_PRIVATE_KEY_FILE must be set for RS256")
+		}
+		privateKey, publicKey, err := auth.LoadRSAKeys(cfg.TokenPrivateKeyFile, cfg.TokenPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return auth.NewRS256Issuer(privateKey, publicKey, cfg.TokenIssuer, ttl), nil
+	default:
+		return nil, fmt.Errorf("unknown token algorithm %q", cfg.TokenAlgorithm)
+	}
+}
+
+// newPaymentGateway picks the configured gateway. In production the fake
+// one would mark every order paid, and a default or empty secret would let
+// anyone sign webhooks, so both are refused.
+func newPaymentGateway(cfg *config.Config) (port.PaymentGateway, error) {
+	paymentCfg := cfg.Payment
+	if cfg.Env == "production" {
+		if paymentCfg.Provider == payment.FakeProvider {
+			return nil, errors.New("the fake payment provider is not allowed in production; set PAYMENT_PROVIDER")
+		}
+		if paymentCfg.WebhookSecret == "" || paymentCfg.WebhookSecret == config.DefaultPaymentWebhookSecret {
+			return nil, errors.New("PAYMENT_WEBHOOK_SECRET must be set to a private value in production")
+		}
+	}
+
+	switch paymentCfg.Provider {
+	case payment.FakeProvider:
+		return payment.NewFakeGateway(paymentCfg.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", paymentCfg.Provider)
+	}
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewApiKey(t *testing.T) {
+	t.Run("should_generate_key_matching_its_hash", func(t *testing.T) {
+		plain, key, err := NewApiKey("erp", []Permission{PermissionProductsRead}, nil, nil)
+		assert.NoError(t, err)
+
+		prefix, secret, err := ParseApiKey(plain)
+
+		assert.NoError(t, err)
+		assert.Equal(t, key.Prefix, prefix)
+		assert.True(t, key.Matches(secret))
+		assert.False(t, key.Matches(secret+"0"))
+		assert.NotContains(t, key.KeyHash, secret)
+	})
+
+	t.Run("should_reject_unknown_scope", func(t *testing.T) {
+		_, _, err := NewApiKey("erp", []Permission{PermissionOrdersManage}, nil, nil)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestParseApiKey(t *testing.T) {
+	for _, key := range []string{"", "ecom_", "ecom_abcd", "ecom__secret", "other_abcd_secret"} {
+		t.Run(key, func(t *testing.T) {
+			_, _, err := ParseApiKey(key)
+			assert.ErrorIs(t, err, ErrInvalidApiKey)
+		})
+	}
+}
+
+func TestApiKeyIsActive(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	assert.True(t, (&ApiKey{}).IsActive(now))
+	assert.True(t, (&ApiKey{ExpiresAt: &future}).IsActive(now))
+	assert.False(t, (&ApiKey{ExpiresAt: &past}).IsActive(now))
+	assert.False(t, (&ApiKey{RevokedAt: &past}).IsActive(now))
+}
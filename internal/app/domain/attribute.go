@@ -0,0 +1,209 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidAttribute = errors.New("invalid attribute")
+
+type AttributeType string
+
+const (
+	AttributeTypeNumber  AttributeType = "number"
+	AttributeTypeEnum    AttributeType = "enum"
+	AttributeTypeBoolean AttributeType = "boolean"
+	AttributeTypeText    AttributeType = "text"
+)
+
+// AttributeDefinition describes a specification field, such as ram_gb, that
+// products in a category (and its subcategories) may or must carry.
+type AttributeDefinition struct {
+	Id         int64         `json:"id"`
+	CategoryId int64         `json:"category_id"`
+	Code       string        `json:"code"`
+	Name       string        `json:"name"`
+	Type       AttributeType `json:"type"`
+	Unit       *string       `json:"unit"`
+	Values     []string      `json:"values"`
+	Required   bool          `json:"required"`
+}
+
+// ProductAttribute is a product's value for an attribute definition. Value
+// holds a float64, bool or string depending on the attribute type.
+type ProductAttribute struct {
+	AttributeId int64         `json:"-"`
+	Code        string        `json:"code"`
+	Name        string        `json:"name"`
+	Type        AttributeType `json:"type"`
+	Unit        *string       `json:"unit"`
+	Value       any           `json:"value"`
+}
+
+// Parse checks raw, as decoded from JSON, against the definition and returns
+// the typed product attribute.
+func (d AttributeDefinition) Parse(raw any) (ProductAttribute, error) {
+	attribute := ProductAttribute{
+		AttributeId: d.Id,
+		Code:        d.Code,
+		Name:        d.Name,
+		Type:        d.Type,
+		Unit:        d.Unit,
+	}
+
+	switch d.Type {
+	case AttributeTypeNumber:
+		value, ok := raw.(float64)
+		if !ok {
+			return attribute, fmt.Errorf("%w: %s must be a number", ErrInvalidAttribute, d.Code)
+		}
+		attribute.Value = value
+	case AttributeTypeBoolean:
+		value, ok := raw.(bool)
+		if !ok {
+			return attribute, fmt.Errorf("%w: %s must be a boolean", ErrInvalidAttribute, d.Code)
+		}
+		attribute.Value = value
+	case AttributeTypeText:
+		value, ok := raw.(string)
+		if !ok || value == "" {
+			return attribute, fmt.Errorf("%w: %s must be a non-empty string", ErrInvalidAttribute, d.Code)
+		}
+		attribute.Value = value
+	case AttributeTypeEnum:
+		value, ok := raw.(string)
+		if !ok || !slices.Contains(d.Values, value) {
+			return attribute, fmt.Errorf("%w: %s must be one of %s", ErrInvalidAttribute, d.Code, strings.Join(d.Values, ", "))
+		}
+		attribute.Value = value
+	default:
+		return attribute, fmt.Errorf("%w: %s has unknown type %q", ErrInvalidAttribute, d.Code, d.Type)
+	}
+
+	return attribute, nil
+}
+
+// ParseAttributes validates raw attribute values keyed by code against the
+// definitions that apply to a product's category.
+func ParseAttributes(definitions []AttributeDefinition, raw map[string]any) ([]ProductAttribute, error) {
+	byCode := make(map[string]AttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		byCode[definition.Code] = definition
+	}
+
+	for code := range raw {
+		if _, exists := byCode[code]; !exists {
+			return nil, fmt.Errorf("%w: %s is not defined for this category", ErrInvalidAttribute, code)
+		}
+	}
+
+	attributes := []ProductAttribute{}
+	for _, definition := range definitions {
+		value, exists := raw[definition.Code]
+		if !exists || value == nil {
+			if definition.Required {
+				return nil, fmt.Errorf("%w: %s is required", ErrInvalidAttribute, definition.Code)
+			}
+			continue
+		}
+
+		attribute, err := definition.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, attribute)
+	}
+
+	return attributes, nil
+}
+
+// Validate checks that the definition itself is consistent.
+func (d AttributeDefinition) Validate() error {
+	switch d.Type {
+	case AttributeTypeEnum:
+		if len(d.Values) == 0 {
+			return fmt.Errorf("%w: enum attribute %s needs at least one value", ErrInvalidAttribute, d.Code)
+		}
+	case AttributeTypeNumber, AttributeTypeBoolean, AttributeTypeText:
+		if len(d.Values) > 0 {
+			return fmt.Errorf("%w: only enum attributes can list values", ErrInvalidAttribute)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidAttribute, d.Type)
+	}
+	return nil
+}
+
+type AttributeOperator string
+
+const (
+	AttributeOperatorEq  AttributeOperator = "="
+	AttributeOperatorNeq AttributeOperator = "!="
+	AttributeOperatorGt  AttributeOperator = ">"
+	AttributeOperatorGte AttributeOperator = ">="
+	AttributeOperatorLt  AttributeOperator = "<"
+	AttributeOperatorLte AttributeOperator = "<="
+)
+
+// AttributeFilter is a specification filter such as attr.ram_gb>=16.
+type AttributeFilter struct {
+	Code     string            `json:"code"`
+	Operator AttributeOperator `json:"operator"`
+	Value    string            `json:"value"`
+}
+
+// IsNumeric reports whether the filter compares numbers rather than values.
+func (f AttributeFilter) IsNumeric() bool {
+	switch f.Operator {
+	case AttributeOperatorGt, AttributeOperatorGte, AttributeOperatorLt, AttributeOperatorLte:
+		return true
+	default:
+		return false
+	}
+}
+
+var attributeFilterPattern = regexp.MustCompile(`^attr\.([a-z0-9_]+)(>=|<=|!=|>|<|=)(.*)$`)
+
+// parseAttributeFilters reads attr.* filters from the raw query string.
+// They cannot be read from url.Values because an operator like >= would be
+// split at its "=".
+func parseAttributeFilters(rawQuery string) ([]AttributeFilter, error) {
+	filters := []AttributeFilter{}
+
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if !strings.HasPrefix(pair, "attr.") && !strings.HasPrefix(pair, "attr%2E") {
+			continue
+		}
+
+		decoded, err := url.QueryUnescape(pair)
+		if err != nil {
+			return nil, err
+		}
+
+		match := attributeFilterPattern.FindStringSubmatch(decoded)
+		if match == nil {
+			return nil, fmt.Errorf("%w: malformed filter %q", ErrInvalidAttribute, decoded)
+		}
+
+		filter := AttributeFilter{
+			Code:     match[1],
+			Operator: AttributeOperator(match[2]),
+			Value:    match[3],
+		}
+
+		if filter.IsNumeric() {
+			if _, err = strconv.ParseFloat(filter.Value, 64); err != nil {
+				return nil, fmt.Errorf("%w: %s%s needs a numeric value", ErrInvalidAttribute, filter.Code, filter.Operator)
+			}
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
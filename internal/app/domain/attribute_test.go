@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseAttributeFilters(t *testing.T) {
+	t.Run("should_parse_comparison_operators", func(t *testing.T) {
+		filters, err := parseAttributeFilters("search=laptop&attr.ram_gb>=16&attr.color=red&attr.screen_in%3C15.6&attr.os!=windows")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []AttributeFilter{
+			{Code: "ram_gb", Operator: AttributeOperatorGte, Value: "16"},
+			{Code: "color", Operator: AttributeOperatorEq, Value: "red"},
+			{Code: "screen_in", Operator: AttributeOperatorLt, Value: "15.6"},
+			{Code: "os", Operator: AttributeOperatorNeq, Value: "windows"},
+		}, filters)
+	})
+
+	t.Run("should_reject_non_numeric_range_values", func(t *testing.T) {
+		_, err := parseAttributeFilters("attr.ram_gb>=lots")
+		assert.ErrorIs(t, err, ErrInvalidAttribute)
+	})
+
+	t.Run("should_reject_malformed_filters", func(t *testing.T) {
+		_, err := parseAttributeFilters("attr.RAM=16")
+		assert.ErrorIs(t, err, ErrInvalidAttribute)
+	})
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid or expired access token")
+	ErrForbidden    = errors.New("not allowed to perform this action")
+)
+
+type Role string
+
+const (
+	RoleCustomer       Role = "customer"
+	RoleCatalogManager Role = "catalog_manager"
+	RoleAdmin          Role = "admin"
+)
+
+// Permission grants access to a group of operations. Reading the catalog
+// needs no permission; products:read exists for credentials that are
+// limited to it.
+type Permission string
+
+const (
+	PermissionProductsRead  Permission = "products:read"
+	PermissionProductsWrite Permission = "products:write"
+	PermissionOrdersManage  Permission = "orders:manage"
+	PermissionApiKeysManage Permission = "api_keys:manage"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleCustomer:       {},
+	RoleCatalogManager: {PermissionProductsRead, PermissionProductsWrite},
+	RoleAdmin:          {PermissionProductsRead, PermissionProductsWrite, PermissionOrdersManage, PermissionApiKeysManage},
+}
+
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+// AccessToken is a short-lived bearer credential handed out on login.
+type AccessToken struct {
+	Token     string    `json:"access_token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Principal is who an authenticated request acts as: a signed-in user,
+// whose role decides what they may do, or an API key limited to its scopes.
+type Principal struct {
+	UserId    int64
+	SessionId int64
+	Role      Role
+	TwoFactor bool
+	ApiKeyId  int64
+	Scopes    []Permission
+}
+
+func (p *Principal) IsUser() bool {
+	return p.UserId != 0
+}
+
+func (p *Principal) Can(permission Permission) bool {
+	if !p.IsUser() {
+		return slices.Contains(p.Scopes, permission)
+	}
+	return slices.Contains(p.Role.Permissions(), permission)
+}
+
+// Actor names the principal in audit trails.
+func (p *Principal) Actor() string {
+	if !p.IsUser() {
+		return fmt.Sprintf("api_key:%d", p.ApiKeyId)
+	}
+	return fmt.Sprintf("user:%d", p.UserId)
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPrincipalCan(t *testing.T) {
+	tests := []struct {
+		role       Role
+		permission Permission
+		expected   bool
+	}{
+		{RoleCustomer, PermissionProductsWrite, false},
+		{RoleCatalogManager, PermissionProductsWrite, true},
+		{RoleCatalogManager, PermissionOrdersManage, false},
+		{RoleAdmin, PermissionOrdersManage, true},
+		{Role("unknown"), PermissionProductsRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+"_"+string(tt.permission), func(t *testing.T) {
+			principal := &Principal{UserId: 1, Role: tt.role}
+			assert.Equal(t, tt.expected, principal.Can(tt.permission))
+		})
+	}
+}
+
+func TestApiKeyPrincipalCan(t *testing.T) {
+	principal := (&ApiKey{Id: 3, Scopes: []Permission{PermissionProductsWrite}}).Principal()
+
+	assert.True(t, principal.Can(PermissionProductsWrite))
+	assert.False(t, principal.Can(PermissionProductsRead))
+	assert.False(t, principal.IsUser())
+	assert.Equal(t, "api_key:3", principal.Actor())
+}
@@ -1,11 +1,19 @@
 package domain
 
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
 type Brand struct {
 	Id          int64   `json:"id"`
 	Name        string  `json:"name"`
 	Slug        string  `json:"slug"`
 	Description *string `json:"description"`
 	LogoUrl     *string `json:"logo_url"`
+	// UpdatedAt feeds the validators of products that embed the brand.
+	UpdatedAt time.Time `json:"-"`
 }
 
 type BrandSummary struct {
@@ -13,3 +21,50 @@ type BrandSummary struct {
 	Name string `json:"name"`
 	Slug string `json:"slug"`
 }
+
+type PaginatedBrandsQuery struct {
+	Offset        int    `json:"offset" validate:"min=0"`
+	Limit         int    `json:"limit" validate:"min=1,max=100"`
+	Search        string `json:"search"`
+	SortDirection string `json:"sort_direction" validate:"oneof=asc desc"`
+	SortField     string `json:"sort_field" validate:"oneof=name"`
+}
+
+func (q PaginatedBrandsQuery) Parse(r *http.Request) (PaginatedBrandsQuery, error) {
+	qs := r.URL.Query()
+
+	offset := qs.Get("offset")
+	if offset != "" {
+		o, err := strconv.Atoi(offset)
+		if err != nil {
+			return q, err
+		}
+		q.Offset = o
+	}
+
+	limit := qs.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return q, err
+		}
+		q.Limit = l
+	}
+
+	search := qs.Get("search")
+	if search != "" {
+		q.Search = search
+	}
+
+	sort := qs.Get("sort_direction")
+	if sort != "" {
+		q.SortDirection = sort
+	}
+
+	sortField := qs.Get("sort_field")
+	if sortField != "" {
+		q.SortField = sortField
+	}
+
+	return q, nil
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrInvalidCartItem   = errors.New("invalid cart item")
+)
+
+// CartOwner identifies whose cart is meant: a signed-in customer, or an
+// anonymous visitor holding a cart token. The customer takes precedence.
+type CartOwner struct {
+	Token      string
+	CustomerId int64
+}
+
+func (o CartOwner) IsAnonymous() bool {
+	return o.CustomerId == 0
+}
+
+type Cart struct {
+	Id         int64      `json:"id"`
+	Token      *string    `json:"token,omitempty"`
+	CustomerId *int64     `json:"customer_id,omitempty"`
+	Items      []CartItem `json:"items"`
+	Subtotal   Money      `json:"subtotal"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CartItem is a line of a cart. Name, price and stock are read from the
+// catalog every time the cart is loaded, so they are always current.
+type CartItem struct {
+	Id        int64   `json:"id"`
+	ProductId int64   `json:"product_id"`
+	VariantId *int64  `json:"variant_id"`
+	Name      string  `json:"name"`
+	Slug      string  `json:"slug"`
+	Sku       *string `json:"sku"`
+	UnitPrice Money   `json:"unit_price"`
+	Quantity  int64   `json:"quantity"`
+	LineTotal Money   `json:"line_total"`
+	// Available is false when there is no longer enough stock for the
+	// quantity in the cart.
+	Available bool  `json:"available"`
+	Stock     int64 `json:"-"`
+}
+
+// Recalculate derives line totals, availability and the subtotal from the
+// current unit prices and stock.
+func (c *Cart) Recalculate() error {
+	c.Subtotal = NewMoney(0, DefaultCurrency)
+
+	for i := range c.Items {
+		item := &c.Items[i]
+		item.LineTotal = item.UnitPrice.Mul(item.Quantity)
+		item.Available = item.Quantity <= item.Stock
+
+		if i == 0 {
+			c.Subtotal = NewMoney(0, item.UnitPrice.Currency)
+		}
+
+		subtotal, err := c.Subtotal.Add(item.LineTotal)
+		if err != nil {
+			return err
+		}
+		c.Subtotal = subtotal
+	}
+
+	return nil
+}
+
+// FindItem returns the line for a product and variant, if the cart has one.
+func (c *Cart) FindItem(productId int64, variantId *int64) *CartItem {
+	for i := range c.Items {
+		item := &c.Items[i]
+		if item.ProductId != productId {
+			continue
+		}
+		if (item.VariantId == nil && variantId == nil) ||
+			(item.VariantId != nil && variantId != nil && *item.VariantId == *variantId) {
+			return item
+		}
+	}
+	return nil
+}
+
+func (c *Cart) ItemById(id int64) *CartItem {
+	for i := range c.Items {
+		if c.Items[i].Id == id {
+			return &c.Items[i]
+		}
+	}
+	return nil
+}
+
+// Currency is the currency all lines of the cart are priced in, or empty
+// for an empty cart.
+func (c *Cart) Currency() string {
+	if len(c.Items) == 0 {
+		return ""
+	}
+	return c.Items[0].UnitPrice.Currency
+}
+
+// ResolveCartLine works out what adding a product to a cart refers to:
+// products with variants can only be bought as one of them. It returns the
+// current unit price and stock of the line.
+func ResolveCartLine(product *Product, variantId *int64) (Money, int64, error) {
+	if len(product.Variants) == 0 {
+		if variantId != nil {
+			return Money{}, 0, fmt.Errorf("%w: product %d has no variants", ErrInvalidCartItem, product.Id)
+		}
+		return product.EffectivePrice(), product.Stock, nil
+	}
+
+	if variantId == nil {
+		return Money{}, 0, fmt.Errorf("%w: product %d must be added as one of its variants", ErrInvalidCartItem, product.Id)
+	}
+
+	for _, variant := range product.Variants {
+		if variant.Id == *variantId {
+			return variant.EffectivePrice(product.EffectivePrice()), variant.Stock, nil
+		}
+	}
+
+	return Money{}, 0, fmt.Errorf("%w: variant %d does not belong to product %d", ErrInvalidCartItem, *variantId, product.Id)
+}
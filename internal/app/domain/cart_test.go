@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCartRecalculate(t *testing.T) {
+	t.Run("should_total_lines_and_flag_shortages", func(t *testing.T) {
+		cart := Cart{Items: []CartItem{
+			{UnitPrice: NewMoney(1999, "EUR"), Quantity: 2, Stock: 5},
+			{UnitPrice: NewMoney(500, "EUR"), Quantity: 3, Stock: 1},
+		}}
+
+		assert.NoError(t, cart.Recalculate())
+		assert.Equal(t, NewMoney(3998, "EUR"), cart.Items[0].LineTotal)
+		assert.True(t, cart.Items[0].Available)
+		assert.False(t, cart.Items[1].Available)
+		assert.Equal(t, NewMoney(5498, "EUR"), cart.Subtotal)
+	})
+}
+
+func TestResolveCartLine(t *testing.T) {
+	sale := NewMoney(1500, "USD")
+	product := &Product{BaseProduct: BaseProduct{Id: 1, Price: NewMoney(2000, "USD"), SalePrice: &sale, Stock: 4}}
+
+	t.Run("should_use_sale_price", func(t *testing.T) {
+		price, stock, err := ResolveCartLine(product, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, sale, price)
+		assert.Equal(t, int64(4), stock)
+	})
+
+	t.Run("should_reject_variant_of_simple_product", func(t *testing.T) {
+		variantId := int64(9)
+		_, _, err := ResolveCartLine(product, &variantId)
+
+		assert.ErrorIs(t, err, ErrInvalidCartItem)
+	})
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type Category struct {
 	Id          int64     `json:"id"`
 	Name        string    `json:"name"`
@@ -8,6 +10,8 @@ type Category struct {
 	ParentId    *int64    `json:"parent_id"`
 	Parent      *Category `json:"parent"`
 	ImageUrl    *string   `json:"image_url"`
+	// UpdatedAt feeds the validators of products that embed the category.
+	UpdatedAt time.Time `json:"-"`
 }
 
 type CategorySummary struct {
@@ -15,3 +19,17 @@ type CategorySummary struct {
 	Name string `json:"name"`
 	Slug string `json:"slug"`
 }
+
+type CategoryNode struct {
+	Id       int64           `json:"id"`
+	Name     string          `json:"name"`
+	Slug     string          `json:"slug"`
+	ImageUrl *string         `json:"image_url"`
+	Children []*CategoryNode `json:"children"`
+}
+
+type CategoryDetails struct {
+	Category
+	Breadcrumbs []CategorySummary `json:"breadcrumbs"`
+	Children    []CategorySummary `json:"children"`
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// numericCursorValue and realCursorValue match numeric and real sort keys
+// as Postgres prints them and accepts them back.
+var (
+	numericCursorValue = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+	realCursorValue    = regexp.MustCompile(`^-?\d+(\.\d+)?(e[-+]\d+)?$`)
+)
+
+// cursorTimeLayouts are how Postgres prints a timestamptz, with a whole
+// hour offset or not.
+var cursorTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999-07:00",
+}
+
+const (
+	PaginationOffset = "offset"
+	PaginationCursor = "cursor"
+)
+
+// ProductCursor marks a position in a product listing: the value of the
+// active sort field and the id of the product it was taken from. Clients
+// only ever see it encoded, so its shape can change freely.
+type ProductCursor struct {
+	SortField     string `json:"f"`
+	SortDirection string `json:"d"`
+	Value         string `json:"v"`
+	Id            int64  `json:"i"`
+	// Backward is set on prev cursors, which page towards the start of
+	// the listing.
+	Backward bool `json:"b,omitempty"`
+}
+
+func (c ProductCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeProductCursor(s string) (ProductCursor, error) {
+	var c ProductCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+
+	if err = json.Unmarshal(data, &c); err != nil || c.SortField == "" || c.Id == 0 {
+		return c, ErrInvalidCursor
+	}
+
+	if c.SortDirection != "asc" && c.SortDirection != "desc" {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// validValue reports whether the cursor's value has the type its sort field
+// is cast back to in SQL, so a tampered cursor is turned away as invalid
+// rather than failing the query. Relevance is a number when searching and
+// falls back to the name otherwise.
+func (c ProductCursor) validValue(search bool) bool {
+	switch c.SortField {
+	case "price", "effective_price", "stock":
+		return numericCursorValue.MatchString(c.Value)
+	case "created_at":
+		for _, layout := range cursorTimeLayouts {
+			if _, err := time.Parse(layout, c.Value); err == nil {
+				return true
+			}
+		}
+		return false
+	case "relevance":
+		if !search {
+			return true
+		}
+		_, err := strconv.ParseFloat(c.Value, 32)
+		return err == nil && realCursorValue.MatchString(c.Value)
+	default:
+		return true
+	}
+}
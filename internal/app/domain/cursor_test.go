@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProductCursor(t *testing.T) {
+	t.Run("should_round_trip", func(t *testing.T) {
+		cursor := ProductCursor{SortField: "price", SortDirection: "asc", Value: "19.99", Id: 42, Backward: true}
+
+		decoded, err := DecodeProductCursor(cursor.Encode())
+
+		assert.NoError(t, err)
+		assert.Equal(t, cursor, decoded)
+	})
+
+	t.Run("should_reject_tampered_cursor", func(t *testing.T) {
+		for _, s := range []string{"not base64!", "bm90IGpzb24", ProductCursor{SortField: "name", SortDirection: "up", Id: 1}.Encode()} {
+			_, err := DecodeProductCursor(s)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		}
+	})
+
+	t.Run("should_check_value_against_sort_field", func(t *testing.T) {
+		cases := []struct {
+			cursor ProductCursor
+			search bool
+			valid  bool
+		}{
+			{ProductCursor{SortField: "price", Value: "19.99"}, false, true},
+			{ProductCursor{SortField: "price", Value: "cheap"}, false, false},
+			{ProductCursor{SortField: "stock", Value: "12"}, false, true},
+			{ProductCursor{SortField: "created_at", Value: "2024-03-01 12:00:00.123456+00"}, false, true},
+			{ProductCursor{SortField: "created_at", Value: "2024-03-01 12:00:00+05:30"}, false, true},
+			{ProductCursor{SortField: "created_at", Value: "yesterday"}, false, false},
+			{ProductCursor{SortField: "relevance", Value: "1e-05"}, true, true},
+			{ProductCursor{SortField: "relevance", Value: "Infinity"}, true, false},
+			{ProductCursor{SortField: "relevance", Value: "iPhone 15"}, false, true},
+			{ProductCursor{SortField: "name", Value: "anything"}, false, true},
+		}
+
+		for _, c := range cases {
+			assert.Equal(t, c.valid, c.cursor.validValue(c.search), c.cursor.SortField+" "+c.cursor.Value)
+		}
+	})
+}
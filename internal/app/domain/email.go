@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OutboxMaxAttempts is how many times delivery of an email is tried before
+// it is given up on.
+const OutboxMaxAttempts = 5
+
+// Email is a message waiting in, or delivered from, the outbox.
+type Email struct {
+	Id        int64
+	To        string
+	Subject   string
+	Body      string
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// RetryAt says when to try a failed email again, backing off exponentially
+// from 30s, or nil once it is out of attempts.
+func (e *Email) RetryAt(now time.Time) *time.Time {
+	if e.Attempts >= OutboxMaxAttempts {
+		return nil
+	}
+	at := now.Add((30 * time.Second) << max(e.Attempts-1, 0))
+	return &at
+}
+
+type UserTokenPurpose string
+
+const (
+	UserTokenPasswordReset     UserTokenPurpose = "password_reset"
+	UserTokenEmailVerification UserTokenPurpose = "email_verification"
+	UserTokenTwoFactor         UserTokenPurpose = "two_factor_challenge"
+)
+
+// UserToken is a single-use, expiring secret mailed to a user to prove
+// they control their address. Like refresh tokens only its hash is kept.
+// Email verification tokens also record the address they were sent to.
+type UserToken struct {
+	Id        int64
+	UserId    int64
+	Purpose   UserTokenPurpose
+	TokenHash string
+	Email     string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// AccountMail issues the tokens behind account emails and writes the
+// emails carrying them, with links into the storefront at AppUrl.
+type AccountMail struct {
+	AppUrl               string
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+}
+
+func (m AccountMail) PasswordReset(user *User) (*UserToken, *Email, error) {
+	plain, token, err := newUserToken(user.Id, UserTokenPasswordReset, m.PasswordResetTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return token, &Email{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to reset the password for your account. If it was you, follow this link within %s:\n\n%s\n\nIf not, you can ignore this email.\n",
+			user.Name, m.PasswordResetTTL, m.link("/reset-password", plain),
+		),
+	}, nil
+}
+
+func (m AccountMail) EmailVerification(user *User) (*UserToken, *Email, error) {
+	plain, token, err := newUserToken(user.Id, UserTokenEmailVerification, m.EmailVerificationTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+	token.Email = user.Email
+
+	return token, &Email{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm this is your email address by following this link within %s:\n\n%s\n",
+			user.Name, m.EmailVerificationTTL, m.link("/verify-email", plain),
+		),
+	}, nil
+}
+
+func (m AccountMail) link(path, token string) string {
+	return strings.TrimRight(m.AppUrl, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// NewTwoFactorChallenge issues the token a client trades, together with a
+// code, for a session.
+func NewTwoFactorChallenge(userId int64) (*TwoFactorChallenge, *UserToken, error) {
+	plain, token, err := newUserToken(userId, UserTokenTwoFactor, TwoFactorChallengeTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &TwoFactorChallenge{Token: plain, ExpiresAt: token.ExpiresAt}, token, nil
+}
+
+func newUserToken(userId int64, purpose UserTokenPurpose, ttl time.Duration) (string, *UserToken, error) {
+	plain, err := randomHex(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return plain, &UserToken{
+		UserId:    userId,
+		Purpose:   purpose,
+		TokenHash: HashToken(plain),
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
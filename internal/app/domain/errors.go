@@ -3,5 +3,11 @@ package domain
 import "errors"
 
 var (
-	ErrNotFound = errors.New("resource not found")
+	ErrNotFound       = errors.New("resource not found")
+	ErrConflict       = errors.New("resource already exists")
+	ErrParentNotFound = errors.New("parent category not found")
+	ErrCategoryCycle  = errors.New("category cannot be placed under itself or its descendants")
+	// ErrVersionMismatch means a write was based on a version of the
+	// resource that has since been changed by someone else.
+	ErrVersionMismatch = errors.New("resource has been modified since it was read")
 )
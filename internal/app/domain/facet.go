@@ -0,0 +1,83 @@
+package domain
+
+const (
+	FacetBrand      = "brand"
+	FacetCategory   = "category"
+	FacetPrice      = "price"
+	FacetAttributes = "attributes"
+)
+
+// PriceHistogramBuckets is the number of equal-width buckets in the price facet.
+const PriceHistogramBuckets = 5
+
+// Facets holds value counts over the products matching a listing query.
+// Each facet ignores its own filter, so selecting a brand still shows how
+// many products the other brands have.
+type Facets struct {
+	Brands     []FacetValue     `json:"brands,omitempty"`
+	Categories []FacetValue     `json:"categories,omitempty"`
+	Price      []PriceFacet     `json:"price,omitempty"`
+	Attributes []AttributeFacet `json:"attributes,omitempty"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// PriceFacet is a histogram of effective prices in a single currency.
+type PriceFacet struct {
+	Currency string        `json:"currency"`
+	Min      Money         `json:"min"`
+	Max      Money         `json:"max"`
+	Buckets  []PriceBucket `json:"buckets"`
+}
+
+// PriceBucket counts products priced in [From, To).
+type PriceBucket struct {
+	From  Money `json:"from"`
+	To    Money `json:"to"`
+	Count int   `json:"count"`
+}
+
+type AttributeFacet struct {
+	Code   string       `json:"code"`
+	Name   string       `json:"name"`
+	Unit   *string      `json:"unit"`
+	Values []FacetValue `json:"values"`
+}
+
+// PriceBuckets splits [min, max] into n equal-width buckets in minor units.
+// The last bucket is widened so that max falls inside it.
+func PriceBuckets(min, max Money, n int) []PriceBucket {
+	if n < 1 {
+		n = 1
+	}
+
+	width := (max.Amount - min.Amount + 1 + int64(n) - 1) / int64(n)
+	if width < 1 {
+		width = 1
+	}
+
+	buckets := make([]PriceBucket, n)
+	for i := range buckets {
+		from := min.Amount + int64(i)*width
+		buckets[i] = PriceBucket{
+			From: NewMoney(from, min.Currency),
+			To:   NewMoney(from+width, min.Currency),
+		}
+	}
+
+	return buckets
+}
+
+// HasFacet reports whether the listing query asked for the named facet.
+func (q PaginatedProductsQuery) HasFacet(name string) bool {
+	for _, facet := range q.Facets {
+		if facet == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPriceBuckets(t *testing.T) {
+	t.Run("should_cover_range_with_equal_buckets", func(t *testing.T) {
+		buckets := PriceBuckets(NewMoney(1000, "USD"), NewMoney(5999, "USD"), 5)
+
+		assert.Len(t, buckets, 5)
+		assert.Equal(t, NewMoney(1000, "USD"), buckets[0].From)
+		assert.Equal(t, NewMoney(2000, "USD"), buckets[0].To)
+		assert.Equal(t, NewMoney(5000, "USD"), buckets[4].From)
+		assert.Equal(t, NewMoney(6000, "USD"), buckets[4].To)
+	})
+
+	t.Run("should_handle_single_price", func(t *testing.T) {
+		buckets := PriceBuckets(NewMoney(1999, "USD"), NewMoney(1999, "USD"), 5)
+
+		assert.Len(t, buckets, 5)
+		assert.Equal(t, NewMoney(1999, "USD"), buckets[0].From)
+		assert.True(t, NewMoney(1999, "USD").LessThan(buckets[0].To))
+	})
+}
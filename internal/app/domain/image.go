@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrUnsupportedImage  = errors.New("unsupported image type")
+	ErrImageTooLarge     = errors.New("image dimensions are too large")
+	ErrInvalidImageOrder = errors.New("image order must list every image of the product exactly once")
+)
+
+// ThumbnailSize is a named bounding width thumbnails are scaled down to,
+// keeping the aspect ratio of the original.
+type ThumbnailSize struct {
+	Name  string
+	Width int
+}
+
+var ThumbnailSizes = []ThumbnailSize{
+	{Name: "small", Width: 160},
+	{Name: "medium", Width: 480},
+	{Name: "large", Width: 1200},
+}
+
+// SummaryThumbnail is the thumbnail size shown with product summaries.
+const SummaryThumbnail = "medium"
+
+type ProductImage struct {
+	Id         int64             `json:"id"`
+	ProductId  int64             `json:"product_id"`
+	Url        string            `json:"url"`
+	MimeType   string            `json:"mime_type"`
+	Width      int               `json:"width"`
+	Height     int               `json:"height"`
+	Position   int               `json:"position"`
+	IsPrimary  bool              `json:"is_primary"`
+	Thumbnails map[string]string `json:"thumbnails"`
+	// StorageKeys locate the original and every thumbnail in file storage.
+	StorageKeys []string  `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ProcessedImage is an upload that was recognised as an image, along with
+// its encoded thumbnails.
+type ProcessedImage struct {
+	MimeType   string
+	Extension  string
+	Width      int
+	Height     int
+	Thumbnails []Thumbnail
+}
+
+type Thumbnail struct {
+	Size      string
+	MimeType  string
+	Extension string
+	Data      []byte
+}
+
+// ValidateImageOrder checks that ids is a permutation of the ids of images.
+func ValidateImageOrder(images []ProductImage, ids []int64) error {
+	if len(ids) != len(images) {
+		return fmt.Errorf("%w: expected %d ids, got %d", ErrInvalidImageOrder, len(images), len(ids))
+	}
+
+	known := map[int64]bool{}
+	for _, image := range images {
+		known[image.Id] = true
+	}
+
+	for _, id := range ids {
+		if !known[id] {
+			return fmt.Errorf("%w: image %d is missing or listed twice", ErrInvalidImageOrder, id)
+		}
+		delete(known, id)
+	}
+
+	return nil
+}
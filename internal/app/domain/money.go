@@ -0,0 +1,231 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is assumed for prices sent without a currency, e.g. by
+// clients still using the legacy decimal representation.
+const DefaultCurrency = "USD"
+
+type MoneyFormat string
+
+const (
+	// MoneyFormatMinorUnits encodes money as {"amount": 1999, "currency": "USD"}.
+	MoneyFormatMinorUnits MoneyFormat = "minor_units"
+	// MoneyFormatDecimal encodes money as a decimal string such as "19.99",
+	// which is what clients written against float prices expect.
+	MoneyFormatDecimal MoneyFormat = "decimal"
+)
+
+var moneyFormat = MoneyFormatMinorUnits
+
+// SetMoneyFormat selects how Money values are encoded in JSON responses.
+// Decoding always accepts every supported representation.
+func SetMoneyFormat(format MoneyFormat) error {
+	switch format {
+	case MoneyFormatMinorUnits, MoneyFormatDecimal:
+		moneyFormat = format
+		return nil
+	default:
+		return fmt.Errorf("unknown money format %q", format)
+	}
+}
+
+var (
+	ErrInvalidMoney       = errors.New("invalid monetary amount")
+	ErrInvalidCurrency    = errors.New("invalid currency code")
+	ErrCurrencyMismatch   = errors.New("currency mismatch")
+	zeroDecimalCurrencies = map[string]bool{
+		"JPY": true,
+		"KRW": true,
+		"VND": true,
+		"CLP": true,
+		"ISK": true,
+	}
+)
+
+// Money is an exact monetary amount expressed in the minor units of an ISO
+// 4217 currency, e.g. {1999, "USD"} is $19.99.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney converts a decimal string such as "19.99" into Money without
+// going through floating point.
+func ParseMoney(s string, currency string) (Money, error) {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if !isCurrencyCode(currency) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+	}
+
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	exponent := currencyExponent(currency)
+	trimmed := strings.TrimRight(fraction, "0")
+	if len(trimmed) > exponent {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidMoney, s, exponent)
+	}
+	fraction = trimmed + strings.Repeat("0", exponent-len(trimmed))
+
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Decimal returns the amount as a decimal string in major units, e.g. "19.99".
+func (m Money) Decimal() string {
+	exponent := currencyExponent(m.Currency)
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.Currency}
+}
+
+func (m Money) LessThan(other Money) bool {
+	return m.Amount < other.Amount
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	if moneyFormat == MoneyFormatDecimal {
+		return json.Marshal(m.Decimal())
+	}
+
+	type money Money
+	return json.Marshal(money(m))
+}
+
+// UnmarshalJSON accepts {"amount": 1999, "currency": "USD"} as well as the
+// legacy "19.99" and 19.99 forms, which are read in DefaultCurrency.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case bytes.HasPrefix(data, []byte("{")):
+		type money Money
+		var v money
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		if v.Currency == "" {
+			v.Currency = DefaultCurrency
+		}
+		if !isCurrencyCode(v.Currency) {
+			return fmt.Errorf("%w: %q", ErrInvalidCurrency, v.Currency)
+		}
+		*m = Money(v)
+		return nil
+	case bytes.HasPrefix(data, []byte(`"`)):
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		parsed, err := ParseMoney(s, DefaultCurrency)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	default:
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidMoney, data)
+		}
+		parsed, err := ParseMoney(n.String(), DefaultCurrency)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+}
+
+func currencyExponent(currency string) int {
+	if zeroDecimalCurrencies[currency] {
+		return 0
+	}
+	return 2
+}
+
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, c := range s {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	t.Run("should_parse_decimal_strings_exactly", func(t *testing.T) {
+		cases := map[string]int64{
+			"19.99":  1999,
+			"0.1":    10,
+			"120":    12000,
+			"120.50": 12050,
+			"-3.05":  -305,
+		}
+
+		for input, expected := range cases {
+			money, err := ParseMoney(input, "USD")
+			assert.NoError(t, err, input)
+			assert.Equal(t, NewMoney(expected, "USD"), money, input)
+		}
+	})
+
+	t.Run("should_reject_excess_precision", func(t *testing.T) {
+		_, err := ParseMoney("1.234", "USD")
+		assert.ErrorIs(t, err, ErrInvalidMoney)
+
+		_, err = ParseMoney("5.5", "JPY")
+		assert.ErrorIs(t, err, ErrInvalidMoney)
+	})
+
+	t.Run("should_reject_invalid_currency", func(t *testing.T) {
+		_, err := ParseMoney("1.00", "usd")
+		assert.ErrorIs(t, err, ErrInvalidCurrency)
+	})
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	assert.Equal(t, "19.99", NewMoney(1999, "USD").Decimal())
+	assert.Equal(t, "0.05", NewMoney(5, "USD").Decimal())
+	assert.Equal(t, "-0.05", NewMoney(-5, "USD").Decimal())
+	assert.Equal(t, "500", NewMoney(500, "JPY").Decimal())
+}
+
+func TestMoneyJSON(t *testing.T) {
+	t.Run("should_accept_all_representations", func(t *testing.T) {
+		inputs := []string{
+			`{"amount": 1999, "currency": "USD"}`,
+			`"19.99"`,
+			`19.99`,
+		}
+
+		for _, input := range inputs {
+			var money Money
+			err := json.Unmarshal([]byte(input), &money)
+			assert.NoError(t, err, input)
+			assert.Equal(t, NewMoney(1999, "USD"), money, input)
+		}
+	})
+
+	t.Run("should_encode_using_selected_format", func(t *testing.T) {
+		defer func() { _ = SetMoneyFormat(MoneyFormatMinorUnits) }()
+
+		data, err := json.Marshal(NewMoney(1999, "EUR"))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"amount": 1999, "currency": "EUR"}`, string(data))
+
+		assert.NoError(t, SetMoneyFormat(MoneyFormatDecimal))
+		data, err = json.Marshal(NewMoney(1999, "EUR"))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `"19.99"`, string(data))
+	})
+}
@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrEmptyCart         = errors.New("cart is empty")
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	ErrItemsUnavailable  = errors.New("some items are no longer available")
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusPendingPayment OrderStatus = "pending_payment"
+	OrderStatusPaid           OrderStatus = "paid"
+	OrderStatusFulfilling     OrderStatus = "fulfilling"
+	OrderStatusShipped        OrderStatus = "shipped"
+	OrderStatusDelivered      OrderStatus = "delivered"
+	OrderStatusCancelled      OrderStatus = "cancelled"
+	OrderStatusRefunded       OrderStatus = "refunded"
+)
+
+// orderTransitions lists the statuses each status may move to. Orders are
+// cancelled before payment and refunded after it; cancelled and refunded
+// orders are final.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPendingPayment: {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:           {OrderStatusFulfilling, OrderStatusRefunded},
+	OrderStatusFulfilling:     {OrderStatusShipped, OrderStatusRefunded},
+	OrderStatusShipped:        {OrderStatusDelivered},
+	OrderStatusDelivered:      {OrderStatusRefunded},
+}
+
+func (s OrderStatus) CanTransitionTo(to OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderTransition records a status change: who made it, why and when.
+type OrderTransition struct {
+	Id        int64       `json:"id"`
+	From      OrderStatus `json:"from"`
+	To        OrderStatus `json:"to"`
+	Actor     string      `json:"actor"`
+	Reason    *string     `json:"reason"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// RestoresStock reports whether the transition puts the order's items back
+// on the shelf. Only unpaid orders are restocked on cancellation; refunded
+// goods may already be with the customer.
+func (t OrderTransition) RestoresStock() bool {
+	return t.From == OrderStatusPendingPayment && t.To == OrderStatusCancelled
+}
+
+type Order struct {
+	Id         int64             `json:"id"`
+	CustomerId *int64            `json:"customer_id,omitempty"`
+	GuestToken *string           `json:"-"`
+	Status     OrderStatus       `json:"status"`
+	Items      []OrderItem       `json:"items"`
+	Subtotal   Money             `json:"subtotal"`
+	Total      Money             `json:"total"`
+	History    []OrderTransition `json:"history,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// OrderItem is a purchased line. Name, slug and price are copied from the
+// catalog at checkout so later catalog edits don't rewrite past orders.
+type OrderItem struct {
+	Id        int64   `json:"id"`
+	ProductId int64   `json:"product_id"`
+	VariantId *int64  `json:"variant_id"`
+	Name      string  `json:"name"`
+	Slug      string  `json:"slug"`
+	Sku       *string `json:"sku"`
+	UnitPrice Money   `json:"unit_price"`
+	Quantity  int64   `json:"quantity"`
+	LineTotal Money   `json:"line_total"`
+}
+
+// NewOrder starts an order for whoever owns the cart being checked out.
+func NewOrder(owner CartOwner) *Order {
+	order := &Order{Status: OrderStatusPendingPayment}
+	if owner.IsAnonymous() {
+		order.GuestToken = &owner.Token
+	} else {
+		order.CustomerId = &owner.CustomerId
+	}
+	return order
+}
+
+// IsOwnedBy reports whether owner placed the order.
+func (o *Order) IsOwnedBy(owner CartOwner) bool {
+	if !owner.IsAnonymous() {
+		return o.CustomerId != nil && *o.CustomerId == owner.CustomerId
+	}
+	return owner.Token != "" && o.GuestToken != nil && *o.GuestToken == owner.Token
+}
+
+// Transition moves the order to status to, returning the change to record.
+func (o *Order) Transition(to OrderStatus, actor string, reason *string) (*OrderTransition, error) {
+	if !o.Status.CanTransitionTo(to) {
+		return nil, fmt.Errorf("%w: %s order cannot become %s", ErrInvalidTransition, o.Status, to)
+	}
+
+	transition := &OrderTransition{
+		From:   o.Status,
+		To:     to,
+		Actor:  actor,
+		Reason: reason,
+	}
+	o.Status = to
+
+	return transition, nil
+}
+
+// Fill turns cart lines, priced and stocked from the current catalog, into
+// the order's items. Every line must be in stock in full.
+func (o *Order) Fill(lines []CartItem) error {
+	if len(lines) == 0 {
+		return ErrEmptyCart
+	}
+
+	o.Items = make([]OrderItem, 0, len(lines))
+	o.Subtotal = NewMoney(0, lines[0].UnitPrice.Currency)
+
+	for _, line := range lines {
+		if line.Quantity > line.Stock {
+			return fmt.Errorf("%w: %s has only %d left", ErrInsufficientStock, line.Name, line.Stock)
+		}
+
+		item := OrderItem{
+			ProductId: line.ProductId,
+			VariantId: line.VariantId,
+			Name:      line.Name,
+			Slug:      line.Slug,
+			Sku:       line.Sku,
+			UnitPrice: line.UnitPrice,
+			Quantity:  line.Quantity,
+			LineTotal: line.UnitPrice.Mul(line.Quantity),
+		}
+
+		subtotal, err := o.Subtotal.Add(item.LineTotal)
+		if err != nil {
+			return err
+		}
+		o.Subtotal = subtotal
+
+		o.Items = append(o.Items, item)
+	}
+
+	o.Total = o.Subtotal
+	return nil
+}
+
+type PaginatedOrdersQuery struct {
+	Offset        int    `json:"offset" validate:"min=0"`
+	Limit         int    `json:"limit" validate:"min=1,max=100"`
+	SortDirection string `json:"sort_direction" validate:"oneof=asc desc"`
+}
+
+func (q PaginatedOrdersQuery) Parse(r *http.Request) (PaginatedOrdersQuery, error) {
+	qs := r.URL.Query()
+
+	offset := qs.Get("offset")
+	if offset != "" {
+		o, err := strconv.Atoi(offset)
+		if err != nil {
+			return q, err
+		}
+		q.Offset = o
+	}
+
+	limit := qs.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return q, err
+		}
+		q.Limit = l
+	}
+
+	sort := qs.Get("sort_direction")
+	if sort != "" {
+		q.SortDirection = sort
+	}
+
+	return q, nil
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOrderFill(t *testing.T) {
+	t.Run("should_snapshot_lines_and_total", func(t *testing.T) {
+		order := NewOrder(CartOwner{CustomerId: 42})
+
+		err := order.Fill([]CartItem{
+			{ProductId: 1, Name: "Mug", Slug: "mug", UnitPrice: NewMoney(1250, "USD"), Quantity: 2, Stock: 2},
+			{ProductId: 2, Name: "Tee", Slug: "tee", UnitPrice: NewMoney(2000, "USD"), Quantity: 1, Stock: 9},
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, order.Items, 2)
+		assert.Equal(t, "Mug", order.Items[0].Name)
+		assert.Equal(t, NewMoney(2500, "USD"), order.Items[0].LineTotal)
+		assert.Equal(t, NewMoney(4500, "USD"), order.Total)
+		assert.Equal(t, OrderStatusPendingPayment, order.Status)
+	})
+
+	t.Run("should_reject_lines_without_enough_stock", func(t *testing.T) {
+		order := NewOrder(CartOwner{Token: "abc"})
+
+		err := order.Fill([]CartItem{{ProductId: 1, Name: "Mug", UnitPrice: NewMoney(1250, "USD"), Quantity: 3, Stock: 2}})
+
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+	})
+
+	t.Run("should_reject_empty_cart", func(t *testing.T) {
+		assert.ErrorIs(t, NewOrder(CartOwner{Token: "abc"}).Fill(nil), ErrEmptyCart)
+	})
+}
+
+func TestOrderIsOwnedBy(t *testing.T) {
+	guest := NewOrder(CartOwner{Token: "abc"})
+	customer := NewOrder(CartOwner{CustomerId: 42})
+
+	assert.True(t, guest.IsOwnedBy(CartOwner{Token: "abc"}))
+	assert.False(t, guest.IsOwnedBy(CartOwner{Token: "xyz"}))
+	assert.False(t, guest.IsOwnedBy(CartOwner{}))
+	assert.True(t, customer.IsOwnedBy(CartOwner{CustomerId: 42}))
+	assert.False(t, customer.IsOwnedBy(CartOwner{CustomerId: 7}))
+}
+
+func TestOrderTransition(t *testing.T) {
+	t.Run("should_follow_allowed_transitions", func(t *testing.T) {
+		order := NewOrder(CartOwner{CustomerId: 42})
+
+		for _, status := range []OrderStatus{OrderStatusPaid, OrderStatusFulfilling, OrderStatusShipped, OrderStatusDelivered, OrderStatusRefunded} {
+			_, err := order.Transition(status, "admin", nil)
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, OrderStatusRefunded, order.Status)
+	})
+
+	t.Run("should_reject_skipping_states", func(t *testing.T) {
+		order := NewOrder(CartOwner{CustomerId: 42})
+
+		_, err := order.Transition(OrderStatusShipped, "admin", nil)
+
+		assert.ErrorIs(t, err, ErrInvalidTransition)
+		assert.Equal(t, OrderStatusPendingPayment, order.Status)
+	})
+
+	t.Run("should_restock_only_unpaid_cancellations", func(t *testing.T) {
+		unpaid := NewOrder(CartOwner{CustomerId: 42})
+		cancellation, err := unpaid.Transition(OrderStatusCancelled, "admin", nil)
+		assert.NoError(t, err)
+		assert.True(t, cancellation.RestoresStock())
+
+		paid := NewOrder(CartOwner{CustomerId: 42})
+		_, _ = paid.Transition(OrderStatusPaid, "admin", nil)
+		refund, err := paid.Transition(OrderStatusRefunded, "admin", nil)
+		assert.NoError(t, err)
+		assert.False(t, refund.RestoresStock())
+
+		_, err = paid.Transition(OrderStatusCancelled, "admin", nil)
+		assert.ErrorIs(t, err, ErrInvalidTransition)
+	})
+}
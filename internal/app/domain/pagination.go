@@ -6,3 +6,14 @@ type Meta struct {
 	PageSize    int `json:"page_size"`
 	TotalPages  int `json:"total_pages"`
 }
+
+// CursorMeta describes a page of a keyset paginated listing. Cursors are
+// passed back as the cursor parameter and are nil at either end. Counting
+// every match defeats the point of keyset pagination, so TotalItems is only
+// set when asked for.
+type CursorMeta struct {
+	PageSize   int     `json:"page_size"`
+	TotalItems *int    `json:"total_items,omitempty"`
+	NextCursor *string `json:"next_cursor"`
+	PrevCursor *string `json:"prev_cursor"`
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPaymentDeclined       = errors.New("payment declined")
+	ErrOrderNotPayable       = errors.New("order is not awaiting payment")
+	ErrNoAuthorization       = errors.New("order has no successful payment authorization")
+	ErrInvalidSignature      = errors.New("invalid webhook signature")
+	ErrPaymentAmountMismatch = errors.New("payment amount does not match order total")
+)
+
+type PaymentOperation string
+
+const (
+	PaymentOperationAuthorize PaymentOperation = "authorize"
+	PaymentOperationCapture   PaymentOperation = "capture"
+	PaymentOperationVoid      PaymentOperation = "void"
+	PaymentOperationRefund    PaymentOperation = "refund"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusSucceeded PaymentStatus = "succeeded"
+	PaymentStatusFailed    PaymentStatus = "failed"
+)
+
+// Payment is one attempt at a gateway operation for an order, kept whether
+// or not it succeeded so payments can be reconciled with the provider.
+type Payment struct {
+	Id        int64            `json:"id"`
+	OrderId   int64            `json:"order_id"`
+	Provider  string           `json:"provider"`
+	Operation PaymentOperation `json:"operation"`
+	Status    PaymentStatus    `json:"status"`
+	Amount    Money            `json:"amount"`
+	Reference string           `json:"reference"`
+	Error     *string          `json:"error"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// PaymentResult is a gateway's answer to an operation. Declines are
+// results, not errors; errors mean the gateway couldn't be reached or
+// didn't understand the request.
+type PaymentResult struct {
+	Reference string
+	Status    PaymentStatus
+	Error     string
+}
+
+type PaymentEventType string
+
+const (
+	PaymentEventSucceeded PaymentEventType = "payment.succeeded"
+	PaymentEventFailed    PaymentEventType = "payment.failed"
+)
+
+// PaymentEvent is a verified callback from a payment provider about an
+// authorization it issued earlier.
+type PaymentEvent struct {
+	Type      PaymentEventType `json:"type"`
+	Reference string           `json:"reference"`
+	Amount    Money            `json:"amount"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// NewPayment records the outcome of an operation on order.
+func NewPayment(order *Order, provider string, operation PaymentOperation, amount Money, result *PaymentResult) *Payment {
+	payment := &Payment{
+		OrderId:   order.Id,
+		Provider:  provider,
+		Operation: operation,
+		Status:    result.Status,
+		Amount:    amount,
+		Reference: result.Reference,
+	}
+	if result.Error != "" {
+		payment.Error = &result.Error
+	}
+	return payment
+}
+
+// LatestSucceeded returns the most recent successful attempt of operation,
+// if any. Payments are expected oldest first.
+func LatestSucceeded(payments []Payment, operation PaymentOperation) *Payment {
+	for i := len(payments) - 1; i >= 0; i-- {
+		if payments[i].Operation == operation && payments[i].Status == PaymentStatusSucceeded {
+			return &payments[i]
+		}
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -8,38 +10,67 @@ import (
 )
 
 type BaseProduct struct {
-	Id         int64    `json:"id"`
-	Name       string   `json:"name"`
-	Slug       string   `json:"slug"`
-	Price      float64  `json:"price"`
-	SalePrice  *float64 `json:"sale_price"`
-	Stock      int64    `json:"stock"`
-	CategoryId int64    `json:"category_id"`
-	BrandId    int64    `json:"brand_id"`
+	Id         int64  `json:"id"`
+	Name       string `json:"name"`
+	Slug       string `json:"slug"`
+	Price      Money  `json:"price"`
+	SalePrice  *Money `json:"sale_price"`
+	Stock      int64  `json:"stock"`
+	CategoryId int64  `json:"category_id"`
+	BrandId    int64  `json:"brand_id"`
+}
+
+// ValidatePricing checks that the sale price is expressed in the same
+// currency as the regular price, since a product carries a single currency.
+func (p BaseProduct) ValidatePricing() error {
+	if p.SalePrice != nil && p.SalePrice.Currency != p.Price.Currency {
+		return fmt.Errorf("%w: price is in %s but sale price is in %s", ErrCurrencyMismatch, p.Price.Currency, p.SalePrice.Currency)
+	}
+	return nil
 }
 
 type Product struct {
 	BaseProduct
-	Description *string   `json:"description"`
-	Category    *Category `json:"category"`
-	Brand       *Brand    `json:"brand"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Description *string            `json:"description"`
+	Category    *Category          `json:"category"`
+	Brand       *Brand             `json:"brand"`
+	Options     []ProductOption    `json:"options"`
+	Variants    []ProductVariant   `json:"variants"`
+	Attributes  []ProductAttribute `json:"attributes"`
+	Images      []ProductImage     `json:"images"`
+	Version     int64              `json:"version"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
 }
 
 type ProductSummary struct {
 	BaseProduct
-	Category *CategorySummary `json:"category"`
-	Brand    *BrandSummary    `json:"brand"`
+	Category  *CategorySummary `json:"category"`
+	Brand     *BrandSummary    `json:"brand"`
+	Thumbnail *string          `json:"thumbnail"`
+	UpdatedAt time.Time        `json:"updated_at"`
 }
 
 type PaginatedProductsQuery struct {
-	Offset        int      `json:"offset"`
-	Limit         int      `json:"limit"`
-	Search        string   `json:"search"`
-	SortDirection string   `json:"sort_direction" validate:"oneof=asc desc"`
-	SortField     string   `json:"sort_field" validate:"oneof=price name stock"`
-	Categories    []string `json:"categories"`
+	Offset        int               `json:"offset" validate:"min=0"`
+	Limit         int               `json:"limit" validate:"min=1,max=100"`
+	Search        string            `json:"search"`
+	SortDirection string            `json:"sort_direction" validate:"oneof=asc desc"`
+	SortField     string            `json:"sort_field" validate:"oneof=price effective_price name stock created_at relevance"`
+	Categories    []string          `json:"categories"`
+	Brands        []string          `json:"brands"`
+	MinPrice      *Money            `json:"min_price" validate:"omitempty,gte=0"`
+	MaxPrice      *Money            `json:"max_price" validate:"omitempty,gte=0"`
+	InStock       bool              `json:"in_stock"`
+	OnSale        bool              `json:"on_sale"`
+	CreatedAfter  *time.Time        `json:"created_after"`
+	Sku           string            `json:"sku"`
+	Options       map[string]string `json:"options"`
+	Attributes    []AttributeFilter `json:"attributes" validate:"max=10"`
+	Facets        []string          `json:"facets" validate:"dive,oneof=brand category price attributes"`
+	Pagination    string            `json:"pagination" validate:"oneof=offset cursor"`
+	Cursor        *ProductCursor    `json:"cursor"`
+	WithTotal     bool              `json:"with_total"`
 }
 
 func (q PaginatedProductsQuery) Parse(r *http.Request) (PaginatedProductsQuery, error) {
@@ -78,6 +109,119 @@ func (q PaginatedProductsQuery) Parse(r *http.Request) (PaginatedProductsQuery,
 		q.SortField = sortField
 	}
 
+	pagination := qs.Get("pagination")
+	if pagination != "" {
+		q.Pagination = pagination
+	}
+
+	// A cursor carries the order it was taken in, so it overrides any sort
+	// parameters and implies cursor pagination.
+	cursor := qs.Get("cursor")
+	if cursor != "" {
+		c, err := DecodeProductCursor(cursor)
+		if err != nil {
+			return q, err
+		}
+		if !c.validValue(q.Search != "") {
+			return q, ErrInvalidCursor
+		}
+		q.Cursor = &c
+		q.Pagination = PaginationCursor
+		q.SortField = c.SortField
+		q.SortDirection = c.SortDirection
+	}
+
+	withTotal := qs.Get("with_total")
+	if withTotal != "" {
+		t, err := strconv.ParseBool(withTotal)
+		if err != nil {
+			return q, err
+		}
+		q.WithTotal = t
+	}
+
+	// Price bounds are compared against the effective price in a single
+	// currency, since amounts in different currencies can't be ranked.
+	currency := qs.Get("currency")
+
+	minPrice := qs.Get("min_price")
+	if minPrice != "" {
+		m, err := ParseMoney(minPrice, currency)
+		if err != nil {
+			return q, err
+		}
+		q.MinPrice = &m
+	}
+
+	maxPrice := qs.Get("max_price")
+	if maxPrice != "" {
+		m, err := ParseMoney(maxPrice, currency)
+		if err != nil {
+			return q, err
+		}
+		q.MaxPrice = &m
+	}
+
+	if q.MinPrice != nil && q.MaxPrice != nil && q.MaxPrice.LessThan(*q.MinPrice) {
+		return q, errors.New("max_price cannot be less than min_price")
+	}
+
+	inStock := qs.Get("in_stock")
+	if inStock != "" {
+		b, err := strconv.ParseBool(inStock)
+		if err != nil {
+			return q, err
+		}
+		q.InStock = b
+	}
+
+	onSale := qs.Get("on_sale")
+	if onSale != "" {
+		b, err := strconv.ParseBool(onSale)
+		if err != nil {
+			return q, err
+		}
+		q.OnSale = b
+	}
+
+	createdAfter := qs.Get("created_after")
+	if createdAfter != "" {
+		t, err := parseTime(createdAfter)
+		if err != nil {
+			return q, err
+		}
+		q.CreatedAfter = &t
+	}
+
+	sku := qs.Get("sku")
+	if sku != "" {
+		q.Sku = sku
+	}
+
+	q.Options = map[string]string{}
+	for key, values := range qs {
+		name, isOption := strings.CutPrefix(key, "option.")
+		if isOption && name != "" && len(values) > 0 {
+			q.Options[name] = values[0]
+		}
+	}
+
+	attributes, err := parseAttributeFilters(r.URL.RawQuery)
+	if err != nil {
+		return q, err
+	}
+	q.Attributes = attributes
+
+	facets := qs.Get("facets")
+	if facets != "" {
+		q.Facets = strings.Split(facets, ",")
+	}
+
+	brands := qs.Get("brands")
+	if brands != "" {
+		q.Brands = strings.Split(brands, ",")
+	}
+
 	categories := qs.Get("categories")
 	if categories != "" {
 		q.Categories = strings.Split(categories, ",")
@@ -87,3 +231,21 @@ func (q PaginatedProductsQuery) Parse(r *http.Request) (PaginatedProductsQuery,
 
 	return q, nil
 }
+
+// parseTime accepts an RFC 3339 timestamp or a plain date, read as
+// midnight UTC.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
+
+// EffectivePrice is what the product sells for: its sale price when it
+// has one, otherwise its regular price.
+func (p BaseProduct) EffectivePrice() Money {
+	if p.SalePrice != nil {
+		return *p.SalePrice
+	}
+	return p.Price
+}
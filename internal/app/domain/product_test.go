@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPaginatedProductsQueryParse(t *testing.T) {
+	t.Run("should_parse_listing_filters", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/v1/products?min_price=10&max_price=99.99&brands=apple,samsung&in_stock=true&on_sale=1&created_after=2024-03-01", nil)
+
+		q, err := PaginatedProductsQuery{}.Parse(r)
+
+		assert.NoError(t, err)
+		assert.Equal(t, NewMoney(1000, DefaultCurrency), *q.MinPrice)
+		assert.Equal(t, NewMoney(9999, DefaultCurrency), *q.MaxPrice)
+		assert.Equal(t, []string{"apple", "samsung"}, q.Brands)
+		assert.True(t, q.InStock)
+		assert.True(t, q.OnSale)
+		assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), *q.CreatedAfter)
+	})
+
+	t.Run("should_read_price_bounds_in_currency", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/v1/products?min_price=1500&currency=JPY", nil)
+
+		q, err := PaginatedProductsQuery{}.Parse(r)
+
+		assert.NoError(t, err)
+		assert.Equal(t, NewMoney(1500, "JPY"), *q.MinPrice)
+	})
+
+	t.Run("should_reject_inverted_price_range", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/v1/products?min_price=50&max_price=10", nil)
+
+		_, err := PaginatedProductsQuery{}.Parse(r)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("should_take_sort_from_cursor", func(t *testing.T) {
+		cursor := ProductCursor{SortField: "created_at", SortDirection: "asc", Value: "2024-03-01 00:00:00+00", Id: 7}
+		r := httptest.NewRequest("GET", "/v1/products?sort_field=name&cursor="+cursor.Encode(), nil)
+
+		q, err := PaginatedProductsQuery{}.Parse(r)
+
+		assert.NoError(t, err)
+		assert.Equal(t, PaginationCursor, q.Pagination)
+		assert.Equal(t, "created_at", q.SortField)
+		assert.Equal(t, "asc", q.SortDirection)
+	})
+}
+
+func TestDeriveFromVariants(t *testing.T) {
+	usd := func(amount int64) *Money {
+		m := NewMoney(amount, "USD")
+		return &m
+	}
+
+	t.Run("should_take_sale_price_from_variants_inheriting_it", func(t *testing.T) {
+		p := &Product{
+			BaseProduct: BaseProduct{Price: *usd(5000), SalePrice: usd(4000)},
+			Variants: []ProductVariant{
+				{Price: usd(3000), Stock: 1},
+				{Stock: 2},
+			},
+		}
+
+		p.DeriveFromVariants()
+
+		assert.Equal(t, *usd(3000), p.Price)
+		assert.Nil(t, p.SalePrice)
+		assert.Equal(t, int64(3), p.Stock)
+	})
+
+	t.Run("should_keep_sale_price_below_cheapest_variant", func(t *testing.T) {
+		p := &Product{
+			BaseProduct: BaseProduct{Price: *usd(5000), SalePrice: usd(4000)},
+			Variants: []ProductVariant{
+				{Price: usd(4500)},
+				{},
+			},
+		}
+
+		p.DeriveFromVariants()
+
+		assert.Equal(t, *usd(4500), p.Price)
+		assert.Equal(t, usd(4000), p.SalePrice)
+	})
+}
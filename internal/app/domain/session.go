@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var ErrRefreshTokenReused = errors.New("refresh token was already used; session revoked")
+
+// SessionClient describes the device a session was started or last
+// refreshed from.
+type SessionClient struct {
+	UserAgent string
+	Ip        string
+}
+
+// Session is one sign-in of a user on one device. Its refresh tokens form
+// a family: each is exchanged exactly once for the next, and presenting a
+// spent one means the family leaked, so the whole session is revoked.
+type Session struct {
+	Id         int64      `json:"id"`
+	UserId     int64      `json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	Ip         string     `json:"ip"`
+	Current    bool       `json:"current"`
+	TwoFactor  bool       `json:"two_factor"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"-"`
+}
+
+// NewSession starts a session; twoFactor records whether the sign-in
+// passed a second factor, which carries over to every refresh.
+func NewSession(userId int64, client SessionClient, twoFactor bool) *Session {
+	return &Session{
+		UserId:    userId,
+		UserAgent: client.UserAgent,
+		Ip:        client.Ip,
+		TwoFactor: twoFactor,
+	}
+}
+
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RefreshToken is stored by hash only; the plain token is handed to the
+// client once.
+type RefreshToken struct {
+	Id        int64
+	SessionId int64
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// NewRefreshToken generates a token for session, returning the plain value
+// alongside what gets stored.
+func NewRefreshToken(sessionId int64, ttl time.Duration) (string, *RefreshToken, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	plain := hex.EncodeToString(b)
+
+	return plain, &RefreshToken{
+		SessionId: sessionId,
+		TokenHash: HashToken(plain),
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+// HashToken digests a random, high-entropy token for storage. Unlike
+// passwords these can't be guessed, so a fast hash is enough.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuthTokens is what a client gets on login and on every refresh.
+type AuthTokens struct {
+	AccessToken
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
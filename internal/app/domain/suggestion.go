@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type ProductSuggestion struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// Suggestions are search-as-you-type completions grouped by kind.
+type Suggestions struct {
+	Products   []ProductSuggestion `json:"products"`
+	Categories []CategorySummary   `json:"categories"`
+	Brands     []BrandSummary      `json:"brands"`
+}
+
+type SuggestQuery struct {
+	Query string `json:"q" validate:"required,min=1,max=100"`
+	Limit int    `json:"limit" validate:"min=1,max=20"`
+}
+
+func (q SuggestQuery) Parse(r *http.Request) (SuggestQuery, error) {
+	qs := r.URL.Query()
+
+	q.Query = strings.TrimSpace(qs.Get("q"))
+
+	limit := qs.Get("limit")
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return q, err
+		}
+		q.Limit = l
+	}
+
+	return q, nil
+}
+
+// CacheKey identifies equivalent queries, which differ only in letter case.
+func (q SuggestQuery) CacheKey() string {
+	return strings.ToLower(q.Query) + "|" + strconv.Itoa(q.Limit)
+}
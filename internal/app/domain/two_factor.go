@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTwoFactorRequired      = errors.New("two-factor authentication is required for this account")
+	ErrInvalidTwoFactorCode   = errors.New("invalid two-factor code")
+	ErrTwoFactorNotEnrolled   = errors.New("two-factor authentication is not set up")
+	ErrTwoFactorAlreadyActive = errors.New("two-factor authentication is already active")
+)
+
+const (
+	totpDigits = 6
+	totpPeriod = 30
+	// totpSkew is how many periods either side of now a code is accepted
+	// in, to allow for clock drift on the user's device.
+	totpSkew = 1
+
+	// RecoveryCodeCount is how many recovery codes a user gets at a time.
+	RecoveryCodeCount = 10
+
+	// TwoFactorChallengeTTL is how long a user has to enter their code
+	// after giving the right password.
+	TwoFactorChallengeTTL = 5 * time.Minute
+)
+
+// TOTPEnrolment is a user's authenticator app secret (RFC 6238). It only
+// counts once confirmed with a first code. The secret has to be readable
+// to check codes, so unlike other credentials it can't be hashed.
+type TOTPEnrolment struct {
+	UserId       int64
+	Secret       string
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+}
+
+func (e *TOTPEnrolment) IsActive() bool {
+	return e.ConfirmedAt != nil
+}
+
+// TOTPProvisioning is what an authenticator app needs to enrol; the URI is
+// usually shown as a QR code.
+type TOTPProvisioning struct {
+	Secret string `json:"secret"`
+	Uri    string `json:"otpauth_uri"`
+}
+
+func NewTOTPEnrolment(userId int64, issuer, account string) (*TOTPEnrolment, *TOTPProvisioning, error) {
+	key := make([]byte, 20)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, err
+	}
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key)
+
+	label := url.PathEscape(issuer + ":" + account)
+	query := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(totpDigits)},
+		"period":    {fmt.Sprint(totpPeriod)},
+	}
+
+	return &TOTPEnrolment{UserId: userId, Secret: secret}, &TOTPProvisioning{
+		Secret: secret,
+		Uri:    "otpauth://totp/" + label + "?" + query.Encode(),
+	}, nil
+}
+
+// Match finds the time step code is valid for at now. Steps at or before
+// the last one used are refused so a code can't be replayed.
+func (e *TOTPEnrolment) Match(code string, now time.Time) (int64, bool) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(e.Secret)
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= e.LastUsedStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1_000_000)
+}
+
+// RecoveryCode is a one-off substitute for a TOTP code, for users who lost
+// their device. Only its hash is kept.
+type RecoveryCode struct {
+	Id       int64
+	UserId   int64
+	CodeHash string
+	UsedAt   *time.Time
+}
+
+// NewRecoveryCodes generates a fresh set, returning the plain codes to
+// show the user once alongside what gets stored.
+func NewRecoveryCodes(userId int64) ([]string, []RecoveryCode, error) {
+	plain := make([]string, RecoveryCodeCount)
+	codes := make([]RecoveryCode, RecoveryCodeCount)
+
+	for i := range plain {
+		code, err := randomHex(8)
+		if err != nil {
+			return nil, nil, err
+		}
+		plain[i] = code[:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:]
+		codes[i] = RecoveryCode{UserId: userId, CodeHash: HashRecoveryCode(plain[i])}
+	}
+
+	return plain, codes, nil
+}
+
+// HashRecoveryCode ignores case and dashes, which people tend to get wrong
+// when typing a code back in.
+func HashRecoveryCode(code string) string {
+	return HashToken(strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", "")))
+}
+
+// TwoFactorPolicy says which roles must use two-factor authentication.
+type TwoFactorPolicy struct {
+	roles []Role
+}
+
+// ParseTwoFactorPolicy reads a comma separated list of roles.
+func ParseTwoFactorPolicy(roles string) (TwoFactorPolicy, error) {
+	var policy TwoFactorPolicy
+	for _, role := range strings.Split(roles, ",") {
+		role := Role(strings.TrimSpace(role))
+		if role == "" {
+			continue
+		}
+		if !role.IsValid() {
+			return TwoFactorPolicy{}, fmt.Errorf("unknown role %q in two-factor policy", role)
+		}
+		policy.roles = append(policy.roles, role)
+	}
+	return policy, nil
+}
+
+func (p TwoFactorPolicy) Requires(role Role) bool {
+	for _, r := range p.roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// TwoFactorChallenge stands in for tokens after a correct password when
+// the account has two-factor authentication; the client answers it with a
+// code to finish logging in.
+type TwoFactorChallenge struct {
+	Token     string    `json:"challenge_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LoginResult carries either tokens, or a challenge when a second factor
+// is still needed.
+type LoginResult struct {
+	Tokens    *AuthTokens
+	Challenge *TwoFactorChallenge
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 test key from RFC 6238, base32 encoded.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPEnrolmentMatch(t *testing.T) {
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+	}
+
+	for _, v := range vectors {
+		t.Run(v.code, func(t *testing.T) {
+			enrolment := &TOTPEnrolment{Secret: rfc6238Secret}
+
+			step, ok := enrolment.Match(v.code, time.Unix(v.unix, 0))
+
+			assert.True(t, ok)
+			assert.Equal(t, v.unix/totpPeriod, step)
+		})
+	}
+
+	t.Run("should_allow_one_step_of_drift", func(t *testing.T) {
+		enrolment := &TOTPEnrolment{Secret: rfc6238Secret}
+
+		_, early := enrolment.Match("287082", time.Unix(59+totpPeriod, 0))
+		_, late := enrolment.Match("287082", time.Unix(59+2*totpPeriod, 0))
+
+		assert.True(t, early)
+		assert.False(t, late)
+	})
+
+	t.Run("should_refuse_spent_step", func(t *testing.T) {
+		enrolment := &TOTPEnrolment{Secret: rfc6238Secret, LastUsedStep: 1}
+
+		_, ok := enrolment.Match("287082", time.Unix(59, 0))
+
+		assert.False(t, ok)
+	})
+}
+
+func TestNewTOTPEnrolment(t *testing.T) {
+	t.Run("should_provision_matching_uri", func(t *testing.T) {
+		enrolment, provisioning, err := NewTOTPEnrolment(42, "Ecom", "jane@example.com")
+		assert.NoError(t, err)
+
+		uri, err := url.Parse(provisioning.Uri)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "otpauth", uri.Scheme)
+		assert.Equal(t, "totp", uri.Host)
+		assert.Equal(t, "/Ecom:jane@example.com", uri.Path)
+		assert.Equal(t, enrolment.Secret, uri.Query().Get("secret"))
+		assert.Equal(t, "Ecom", uri.Query().Get("issuer"))
+		assert.False(t, enrolment.IsActive())
+	})
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	t.Run("should_hash_codes_ignoring_case_and_dashes", func(t *testing.T) {
+		plain, codes, err := NewRecoveryCodes(42)
+		assert.NoError(t, err)
+
+		assert.Len(t, codes, RecoveryCodeCount)
+		assert.Equal(t, codes[0].CodeHash, HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(plain[0], "-", ""))))
+		assert.NotEqual(t, codes[0].CodeHash, codes[1].CodeHash)
+	})
+}
+
+func TestParseTwoFactorPolicy(t *testing.T) {
+	t.Run("should_require_listed_roles", func(t *testing.T) {
+		policy, err := ParseTwoFactorPolicy("catalog_manager, admin")
+
+		assert.NoError(t, err)
+		assert.True(t, policy.Requires(RoleAdmin))
+		assert.False(t, policy.Requires(RoleCustomer))
+	})
+
+	t.Run("should_allow_empty_policy", func(t *testing.T) {
+		policy, err := ParseTwoFactorPolicy("")
+
+		assert.NoError(t, err)
+		assert.False(t, policy.Requires(RoleAdmin))
+	})
+
+	t.Run("should_reject_unknown_role", func(t *testing.T) {
+		_, err := ParseTwoFactorPolicy("owner")
+
+		assert.Error(t, err)
+	})
+}
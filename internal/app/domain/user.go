@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+type User struct {
+	Id              int64      `json:"id"`
+	Email           string     `json:"email"`
+	Name            string     `json:"name"`
+	Role            Role       `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	PasswordHash    string     `json:"-"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// NewUser prepares a registration, hashing the password so it is never
+// kept in plain text.
+func NewUser(email, name, password string) (*User, error) {
+	user := &User{
+		Email: NormalizeEmail(email),
+		Name:  strings.TrimSpace(name),
+		Role:  RoleCustomer,
+	}
+	if err := user.SetPassword(password); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ChangeEmail switches the account to email, which then needs verifying
+// again. It reports whether the address actually changed.
+func (u *User) ChangeEmail(email string) bool {
+	email = NormalizeEmail(email)
+	if email == u.Email {
+		return false
+	}
+	u.Email = email
+	u.EmailVerifiedAt = nil
+	return true
+}
+
+func (u *User) SetPassword(password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = string(hash)
+	return nil
+}
+
+func (u *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+// NormalizeEmail makes addresses that differ only in case or surrounding
+// whitespace the same account.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewUser(t *testing.T) {
+	t.Run("should_normalize_email_and_hash_password", func(t *testing.T) {
+		user, err := NewUser("  Jane@Example.COM ", " Jane ", "correct horse")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "jane@example.com", user.Email)
+		assert.Equal(t, "Jane", user.Name)
+		assert.NotEqual(t, "correct horse", user.PasswordHash)
+		assert.True(t, user.CheckPassword("correct horse"))
+		assert.False(t, user.CheckPassword("battery staple"))
+	})
+}
+
+func TestChangeEmail(t *testing.T) {
+	t.Run("should_require_reverification_of_new_address", func(t *testing.T) {
+		user, err := NewUser("jane@example.com", "Jane", "correct horse")
+		assert.NoError(t, err)
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+
+		assert.False(t, user.ChangeEmail(" JANE@example.com"))
+		assert.NotNil(t, user.EmailVerifiedAt)
+
+		assert.True(t, user.ChangeEmail("jane@example.org"))
+		assert.Nil(t, user.EmailVerifiedAt)
+	})
+}
+
+func TestEmailRetryAt(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, now.Add(30*time.Second), *(&Email{Attempts: 1}).RetryAt(now))
+	assert.Equal(t, now.Add(2*time.Minute), *(&Email{Attempts: 3}).RetryAt(now))
+	assert.Nil(t, (&Email{Attempts: OutboxMaxAttempts}).RetryAt(now))
+}
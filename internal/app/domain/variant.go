@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidVariantOptions = errors.New("invalid variant options")
+
+// ProductOption is an axis along which a product varies, e.g. size with
+// values S, M and L.
+type ProductOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type ProductVariant struct {
+	Id        int64             `json:"id"`
+	ProductId int64             `json:"product_id"`
+	Sku       string            `json:"sku"`
+	Barcode   *string           `json:"barcode"`
+	Price     *Money            `json:"price"`
+	Stock     int64             `json:"stock"`
+	Options   map[string]string `json:"options"`
+}
+
+// EffectivePrice returns the variant's price override, falling back to the
+// product's base price.
+func (v ProductVariant) EffectivePrice(base Money) Money {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return base
+}
+
+// ValidateOptions checks that options are well formed: unique, non-empty
+// axis names, each with at least one unique value.
+func ValidateOptions(options []ProductOption) error {
+	names := map[string]bool{}
+	for _, option := range options {
+		if option.Name == "" {
+			return fmt.Errorf("%w: option name cannot be empty", ErrInvalidVariantOptions)
+		}
+		if names[option.Name] {
+			return fmt.Errorf("%w: duplicate option %q", ErrInvalidVariantOptions, option.Name)
+		}
+		names[option.Name] = true
+
+		if len(option.Values) == 0 {
+			return fmt.Errorf("%w: option %q has no values", ErrInvalidVariantOptions, option.Name)
+		}
+		values := map[string]bool{}
+		for _, value := range option.Values {
+			if values[value] {
+				return fmt.Errorf("%w: duplicate value %q for option %q", ErrInvalidVariantOptions, value, option.Name)
+			}
+			values[value] = true
+		}
+	}
+	return nil
+}
+
+// ValidateVariantOptions checks that a variant picks exactly one allowed
+// value for every option axis of its product.
+func ValidateVariantOptions(options []ProductOption, selected map[string]string) error {
+	if len(selected) != len(options) {
+		return fmt.Errorf("%w: expected a value for each of %d options, got %d", ErrInvalidVariantOptions, len(options), len(selected))
+	}
+
+	for _, option := range options {
+		value, exists := selected[option.Name]
+		if !exists {
+			return fmt.Errorf("%w: missing value for option %q", ErrInvalidVariantOptions, option.Name)
+		}
+
+		allowed := false
+		for _, v := range option.Values {
+			if v == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %q is not a valid value for option %q", ErrInvalidVariantOptions, value, option.Name)
+		}
+	}
+
+	return nil
+}
+
+// sameOptions reports whether two variants represent the same combination
+// of option values.
+func sameOptions(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// FindVariantByOptions returns the variant with the given combination of
+// option values, ignoring the variant with id excludeId.
+func (p *Product) FindVariantByOptions(options map[string]string, excludeId int64) *ProductVariant {
+	for i := range p.Variants {
+		if p.Variants[i].Id != excludeId && sameOptions(p.Variants[i].Options, options) {
+			return &p.Variants[i]
+		}
+	}
+	return nil
+}
+
+// DeriveFromVariants replaces the product-level price and stock with the
+// lowest variant price and the total variant stock. Variants without their
+// own price inherit the product's sale price, so the sale price becomes the
+// lowest of those when it undercuts the price, and is cleared otherwise.
+// Products without variants are left untouched.
+func (p *Product) DeriveFromVariants() {
+	if len(p.Variants) == 0 {
+		return
+	}
+
+	inherited := p.EffectivePrice()
+	price := p.Variants[0].EffectivePrice(p.Price)
+	sale := p.Variants[0].EffectivePrice(inherited)
+	var stock int64
+	for _, variant := range p.Variants {
+		if effective := variant.EffectivePrice(p.Price); effective.LessThan(price) {
+			price = effective
+		}
+		if effective := variant.EffectivePrice(inherited); effective.LessThan(sale) {
+			sale = effective
+		}
+		stock += variant.Stock
+	}
+
+	p.Price = price
+	p.SalePrice = nil
+	if sale.LessThan(price) {
+		p.SalePrice = &sale
+	}
+	p.Stock = stock
+}
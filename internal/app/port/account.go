@@ -0,0 +1,44 @@
+package port
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"time"
+)
+
+type UserTokenRepository interface {
+	// Create stores token and queues email in the outbox in one
+	// transaction, voiding the user's earlier unused tokens of the same
+	// purpose. email is nil for tokens handed to the user directly.
+	Create(ctx context.Context, token *domain.UserToken, email *domain.Email) error
+	// Consume marks the matching unexpired token used and returns it,
+	// failing with domain.ErrNotFound if there is none.
+	Consume(ctx context.Context, purpose domain.UserTokenPurpose, tokenHash string) (*domain.UserToken, error)
+}
+
+type OutboxRepository interface {
+	// Claim takes up to limit due emails, hiding them from other senders
+	// for lease.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.Email, error)
+	MarkSent(ctx context.Context, id int64) error
+	// MarkFailed records a failed attempt, scheduling a retry at retryAt or
+	// giving up when it is nil.
+	MarkFailed(ctx context.Context, id int64, reason string, retryAt *time.Time) error
+}
+
+// EmailTransport delivers an email.
+type EmailTransport interface {
+	Send(ctx context.Context, email domain.Email) error
+}
+
+type AccountService interface {
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
+	RequestEmailVerification(ctx context.Context, userId int64) error
+	VerifyEmail(ctx context.Context, token string) error
+}
+
+type OutboxService interface {
+	// Dispatch sends a batch of due emails, returning how many went out.
+	Dispatch(ctx context.Context, batchSize int) (int, error)
+}
@@ -0,0 +1,25 @@
+package port
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"time"
+)
+
+type ApiKeyRepository interface {
+	GetByPrefix(ctx context.Context, prefix string) (*domain.ApiKey, error)
+	List(ctx context.Context) ([]domain.ApiKey, error)
+	Create(ctx context.Context, key *domain.ApiKey) error
+	Revoke(ctx context.Context, id int64) error
+	// TouchLastUsed records use of the key, at most about once a minute.
+	TouchLastUsed(ctx context.Context, id int64) error
+}
+
+type ApiKeyService interface {
+	Create(ctx context.Context, name string, scopes []domain.Permission, expiresAt *time.Time, createdBy *int64) (string, *domain.ApiKey, error)
+	List(ctx context.Context) ([]domain.ApiKey, error)
+	Revoke(ctx context.Context, id int64) error
+	// Authenticate resolves a plain key to the principal it acts as,
+	// failing with domain.ErrInvalidApiKey.
+	Authenticate(ctx context.Context, key string) (*domain.Principal, error)
+}
@@ -0,0 +1,19 @@
+package port
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+)
+
+type AttributeRepository interface {
+	ListDefinitions(ctx context.Context, categoryId int64) ([]domain.AttributeDefinition, error)
+	CreateDefinition(ctx context.Context, definition *domain.AttributeDefinition) error
+	DeleteDefinition(ctx context.Context, categoryId int64, code string) error
+	SetProductValues(ctx context.Context, productId int64, attributes []domain.ProductAttribute) error
+}
+
+type AttributeService interface {
+	ListDefinitions(ctx context.Context, categorySlug string) ([]domain.AttributeDefinition, error)
+	CreateDefinition(ctx context.Context, categorySlug string, definition *domain.AttributeDefinition) error
+	DeleteDefinition(ctx context.Context, categorySlug string, code string) error
+}
@@ -0,0 +1,24 @@
+package port
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+)
+
+type BrandRepository interface {
+	GetBySlug(ctx context.Context, slug string) (*domain.Brand, error)
+	Create(ctx context.Context, brand *domain.Brand) error
+	Delete(ctx context.Context, id int64) error
+	Update(ctx context.Context, brand *domain.Brand) error
+	SlugExists(ctx context.Context, candidate string) (bool, error)
+	List(ctx context.Context, query domain.PaginatedBrandsQuery) ([]domain.Brand, domain.Meta, error)
+}
+
+type BrandService interface {
+	GetBySlug(ctx context.Context, slug string) (*domain.Brand, error)
+	Create(ctx context.Context, brand *domain.Brand) error
+	Delete(ctx context.Context, slug string) error
+	Update(ctx context.Context, slug string, brand *domain.Brand) error
+	List(ctx context.Context, query domain.PaginatedBrandsQuery) ([]domain.Brand, domain.Meta, error)
+	ListProducts(ctx context.Context, slug string, query domain.PaginatedProductsQuery) ([]domain.ProductSummary, domain.Meta, error)
+}
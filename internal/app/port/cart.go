@@ -0,0 +1,29 @@
+package port
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"time"
+)
+
+type CartRepository interface {
+	GetByToken(ctx context.Context, token string) (*domain.Cart, error)
+	GetByCustomer(ctx context.Context, customerId int64) (*domain.Cart, error)
+	Create(ctx context.Context, cart *domain.Cart) error
+	Touch(ctx context.Context, id int64, expiresAt time.Time) error
+	AddItem(ctx context.Context, cartId int64, item *domain.CartItem) error
+	UpdateItem(ctx context.Context, cartId, itemId, quantity int64) error
+	RemoveItem(ctx context.Context, cartId, itemId int64) error
+	AssignCustomer(ctx context.Context, id, customerId int64) error
+	Merge(ctx context.Context, fromId, intoId int64, currency string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type CartService interface {
+	Get(ctx context.Context, owner domain.CartOwner) (*domain.Cart, error)
+	AddItem(ctx context.Context, owner domain.CartOwner, productId int64, variantId *int64, quantity int64) (*domain.Cart, error)
+	UpdateItem(ctx context.Context, owner domain.CartOwner, itemId, quantity int64) (*domain.Cart, error)
+	RemoveItem(ctx context.Context, owner domain.CartOwner, itemId int64) (*domain.Cart, error)
+	Merge(ctx context.Context, token string, customerId int64) (*domain.Cart, error)
+	PurgeExpired(ctx context.Context) (int64, error)
+}
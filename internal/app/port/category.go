@@ -7,4 +7,22 @@ import (
 
 type CategoryRepository interface {
 	GetById(ctx context.Context, id int64) (*domain.Category, error)
+	GetBySlug(ctx context.Context, slug string) (*domain.Category, error)
+	Create(ctx context.Context, category *domain.Category) error
+	Update(ctx context.Context, category *domain.Category) error
+	UpdateParent(ctx context.Context, id int64, parentId *int64) error
+	LockPath(ctx context.Context, id, parentId int64) error
+	Deactivate(ctx context.Context, id int64) error
+	SlugExists(ctx context.Context, candidate string) (bool, error)
+	ListActive(ctx context.Context) ([]domain.Category, error)
+	ListChildren(ctx context.Context, parentId int64) ([]domain.CategorySummary, error)
+}
+
+type CategoryService interface {
+	GetBySlug(ctx context.Context, slug string) (*domain.CategoryDetails, error)
+	Create(ctx context.Context, category *domain.Category) error
+	Update(ctx context.Context, slug string, category *domain.Category) error
+	Move(ctx context.Context, slug string, parentId *int64) (*domain.Category, error)
+	Deactivate(ctx context.Context, slug string) error
+	Tree(ctx context.Context) ([]*domain.CategoryNode, error)
 }
@@ -0,0 +1,33 @@
+package port
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"io"
+)
+
+// FileStorage stores uploaded files under slash separated keys and serves
+// them from public URLs.
+type FileStorage interface {
+	Save(ctx context.Context, key string, content io.Reader, contentType string) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type ImageProcessor interface {
+	Process(data []byte, sizes []domain.ThumbnailSize) (*domain.ProcessedImage, error)
+}
+
+type ImageRepository interface {
+	ListByProduct(ctx context.Context, productId int64) ([]domain.ProductImage, error)
+	Create(ctx context.Context, image *domain.ProductImage) error
+	Delete(ctx context.Context, productId, id int64) (*domain.ProductImage, error)
+	SetPrimary(ctx context.Context, productId, id int64) error
+	Reorder(ctx context.Context, productId int64, ids []int64) error
+}
+
+type ImageService interface {
+	Upload(ctx context.Context, productId int64, data []byte) (*domain.ProductImage, error)
+	Delete(ctx context.Context, productId, id int64) error
+	SetPrimary(ctx context.Context, productId, id int64) error
+	Reorder(ctx context.Context, productId int64, ids []int64) ([]domain.ProductImage, error)
+}
@@ -0,0 +1,23 @@
+package port
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+)
+
+type OrderRepository interface {
+	GetById(ctx context.Context, id int64) (*domain.Order, error)
+	ListByOwner(ctx context.Context, owner domain.CartOwner, query domain.PaginatedOrdersQuery) ([]domain.Order, domain.Meta, error)
+	// PlaceFromCart fills order from the cart's lines and stores it while
+	// holding locks on the stock it consumes, then empties the cart.
+	PlaceFromCart(ctx context.Context, cartId int64, order *domain.Order) error
+	Transition(ctx context.Context, orderId int64, transition *domain.OrderTransition) error
+}
+
+type OrderService interface {
+	Checkout(ctx context.Context, owner domain.CartOwner) (*domain.Order, error)
+	GetById(ctx context.Context, owner domain.CartOwner, id int64) (*domain.Order, error)
+	List(ctx context.Context, owner domain.CartOwner, query domain.PaginatedOrdersQuery) ([]domain.Order, domain.Meta, error)
+	AdminGetById(ctx context.Context, id int64) (*domain.Order, error)
+	Transition(ctx context.Context, id int64, to domain.OrderStatus, actor string, reason *string) (*domain.Order, error)
+}
@@ -0,0 +1,35 @@
+package port
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+)
+
+// PaymentGateway talks to a payment provider. Authorization holds funds
+// which are later captured, or voided if the order doesn't go ahead;
+// captured funds can be refunded.
+type PaymentGateway interface {
+	Provider() string
+	Authorize(ctx context.Context, orderId int64, amount domain.Money) (*domain.PaymentResult, error)
+	Capture(ctx context.Context, reference string, amount domain.Money) (*domain.PaymentResult, error)
+	Void(ctx context.Context, reference string) (*domain.PaymentResult, error)
+	Refund(ctx context.Context, reference string, amount domain.Money) (*domain.PaymentResult, error)
+	// ParseWebhook verifies a provider callback against its signature and
+	// decodes it, failing with domain.ErrInvalidSignature if it is forged.
+	ParseWebhook(payload []byte, signature string) (*domain.PaymentEvent, error)
+}
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *domain.Payment) error
+	ListByOrder(ctx context.Context, orderId int64) ([]domain.Payment, error)
+	GetAuthorization(ctx context.Context, provider, reference string) (*domain.Payment, error)
+}
+
+type PaymentService interface {
+	Authorize(ctx context.Context, owner domain.CartOwner, orderId int64) (*domain.Payment, error)
+	Capture(ctx context.Context, orderId int64, actor string) (*domain.Payment, error)
+	Void(ctx context.Context, orderId int64, actor string) (*domain.Payment, error)
+	Refund(ctx context.Context, orderId int64, actor string, reason *string) (*domain.Payment, error)
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error
+	ListByOrder(ctx context.Context, orderId int64) ([]domain.Payment, error)
+}
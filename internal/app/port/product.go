@@ -8,16 +8,28 @@ import (
 type ProductRepository interface {
 	GetById(ctx context.Context, id int64) (*domain.Product, error)
 	Create(ctx context.Context, product *domain.Product) error
-	Delete(ctx context.Context, id int64) error
+	// Delete and Update only apply to the given version of the product,
+	// failing with domain.ErrVersionMismatch otherwise. Delete takes 0 to
+	// mean any version; Update bumps product.Version.
+	Delete(ctx context.Context, id, version int64) error
 	Update(ctx context.Context, product *domain.Product) error
 	SlugExists(ctx context.Context, candidate string) (bool, error)
 	List(ctx context.Context, query domain.PaginatedProductsQuery) ([]domain.ProductSummary, domain.Meta, error)
+	ListByCursor(ctx context.Context, query domain.PaginatedProductsQuery) ([]domain.ProductSummary, domain.CursorMeta, error)
+	Facets(ctx context.Context, query domain.PaginatedProductsQuery) (domain.Facets, error)
 }
 
 type ProductService interface {
 	GetById(ctx context.Context, id int64) (*domain.Product, error)
 	Create(ctx context.Context, product *domain.Product) error
-	Delete(ctx context.Context, id int64) error
+	// Delete and Update take the version the caller last saw, 0 for
+	// whatever is current, and fail with domain.ErrVersionMismatch if the
+	// product has changed since.
+	Delete(ctx context.Context, id, version int64) error
 	Update(ctx context.Context, product *domain.Product) error
+	// Patch updates the product with whatever changes apply makes to it.
+	Patch(ctx context.Context, id, version int64, apply func(product *domain.Product) error) (*domain.Product, error)
 	List(ctx context.Context, query domain.PaginatedProductsQuery) ([]domain.ProductSummary, domain.Meta, error)
+	ListByCursor(ctx context.Context, query domain.PaginatedProductsQuery) ([]domain.ProductSummary, domain.CursorMeta, error)
+	Facets(ctx context.Context, query domain.PaginatedProductsQuery) (domain.Facets, error)
 }
@@ -0,0 +1,23 @@
+package port
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+)
+
+type SearchRepository interface {
+	Suggest(ctx context.Context, query domain.SuggestQuery) (*domain.Suggestions, error)
+}
+
+type SearchService interface {
+	Suggest(ctx context.Context, query domain.SuggestQuery) (*domain.Suggestions, error)
+}
+
+// SuggestionCache keeps recent suggestions in memory. Purge is called when
+// products, brands or categories change so completions never outlive what
+// they name.
+type SuggestionCache interface {
+	Get(key string) (*domain.Suggestions, bool)
+	Set(key string, suggestions *domain.Suggestions)
+	Purge()
+}
@@ -0,0 +1,29 @@
+package port
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+)
+
+type SessionRepository interface {
+	GetById(ctx context.Context, id int64) (*domain.Session, error)
+	GetRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	// Create stores a new session along with its first refresh token.
+	Create(ctx context.Context, session *domain.Session, token *domain.RefreshToken) error
+	// Rotate spends used and stores next in its place, failing with
+	// domain.ErrConflict if used was already spent.
+	Rotate(ctx context.Context, used *domain.RefreshToken, next *domain.RefreshToken, client domain.SessionClient) error
+	ListActive(ctx context.Context, userId int64) ([]domain.Session, error)
+	Revoke(ctx context.Context, userId, sessionId int64) error
+	RevokeAll(ctx context.Context, userId int64) (int64, error)
+}
+
+type SessionService interface {
+	// Authenticate verifies an access token and that its session is still
+	// live, failing with domain.ErrInvalidToken.
+	Authenticate(ctx context.Context, accessToken string) (*domain.Principal, error)
+	Refresh(ctx context.Context, refreshToken string, client domain.SessionClient) (*domain.AuthTokens, error)
+	List(ctx context.Context, principal *domain.Principal) ([]domain.Session, error)
+	Revoke(ctx context.Context, userId, sessionId int64) error
+	RevokeAll(ctx context.Context, userId int64) (int64, error)
+}
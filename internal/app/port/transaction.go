@@ -0,0 +1,12 @@
+package port
+
+import "context"
+
+// Transactor runs a unit of work: every repository call fn makes with the
+// ctx it is handed happens in one transaction, committed when fn returns
+// nil and rolled back otherwise. fn may be run more than once if the
+// transaction loses a conflict with a concurrent one, so it should only
+// touch the database.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,34 @@
+package port
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+)
+
+type TwoFactorRepository interface {
+	// GetTOTP fails with domain.ErrNotFound when the user never started
+	// enrolling.
+	GetTOTP(ctx context.Context, userId int64) (*domain.TOTPEnrolment, error)
+	// SaveTOTP starts an enrolment, replacing one that was never confirmed.
+	SaveTOTP(ctx context.Context, enrolment *domain.TOTPEnrolment) error
+	// ConfirmTOTP activates the enrolment at step and stores its recovery
+	// codes in one transaction.
+	ConfirmTOTP(ctx context.Context, userId, step int64, codes []domain.RecoveryCode) error
+	DeleteTOTP(ctx context.Context, userId int64) error
+	// UseTOTPStep records step as spent, failing with domain.ErrConflict
+	// if it or a later one already was.
+	UseTOTPStep(ctx context.Context, userId, step int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userId int64, codes []domain.RecoveryCode) error
+	// UseRecoveryCode spends the matching unused code, failing with
+	// domain.ErrNotFound if there is none.
+	UseRecoveryCode(ctx context.Context, userId int64, codeHash string) error
+}
+
+type TwoFactorService interface {
+	Enrol(ctx context.Context, userId int64) (*domain.TOTPProvisioning, error)
+	// Confirm activates the enrolment and returns the recovery codes, which
+	// are never shown again.
+	Confirm(ctx context.Context, userId int64, code string) ([]string, error)
+	Disable(ctx context.Context, userId int64, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userId int64, code string) ([]string, error)
+}
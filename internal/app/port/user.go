@@ -0,0 +1,32 @@
+package port
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+)
+
+// TokenIssuer hands out access tokens and verifies them on later requests,
+// failing with domain.ErrInvalidToken for anything it didn't issue or that
+// has expired.
+type TokenIssuer interface {
+	Issue(user *domain.User, session *domain.Session) (*domain.AccessToken, error)
+	Verify(token string) (*domain.Principal, error)
+}
+
+type UserRepository interface {
+	GetById(ctx context.Context, id int64) (*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	Create(ctx context.Context, user *domain.User) error
+	Update(ctx context.Context, user *domain.User) error
+}
+
+type UserService interface {
+	Register(ctx context.Context, email, name, password string) (*domain.User, error)
+	// Login checks the password and either starts a session or, when the
+	// account has two-factor authentication, hands back a challenge for
+	// CompleteLogin.
+	Login(ctx context.Context, email, password string, client domain.SessionClient) (*domain.User, *domain.LoginResult, error)
+	CompleteLogin(ctx context.Context, challenge, code string, client domain.SessionClient) (*domain.User, *domain.AuthTokens, error)
+	GetById(ctx context.Context, id int64) (*domain.User, error)
+	UpdateProfile(ctx context.Context, id int64, email, name string) (*domain.User, error)
+}
@@ -0,0 +1,22 @@
+package port
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+)
+
+type VariantRepository interface {
+	GetById(ctx context.Context, productId, id int64) (*domain.ProductVariant, error)
+	ListByProduct(ctx context.Context, productId int64) ([]domain.ProductVariant, error)
+	Create(ctx context.Context, variant *domain.ProductVariant) error
+	Update(ctx context.Context, variant *domain.ProductVariant) error
+	Delete(ctx context.Context, productId, id int64) error
+	SetOptions(ctx context.Context, productId int64, options []domain.ProductOption) error
+}
+
+type VariantService interface {
+	SetOptions(ctx context.Context, productId int64, options []domain.ProductOption) error
+	Create(ctx context.Context, variant *domain.ProductVariant) error
+	Update(ctx context.Context, variant *domain.ProductVariant) error
+	Delete(ctx context.Context, productId, id int64) error
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"time"
+)
+
+type AccountService struct {
+	userRepo      port.UserRepository
+	sessionRepo   port.SessionRepository
+	userTokenRepo port.UserTokenRepository
+	transactor    port.Transactor
+	mail          domain.AccountMail
+}
+
+func NewAccountService(userRepo port.UserRepository, sessionRepo port.SessionRepository, userTokenRepo port.UserTokenRepository, transactor port.Transactor, mail domain.AccountMail) *AccountService {
+	return &AccountService{
+		userRepo:      userRepo,
+		sessionRepo:   sessionRepo,
+		userTokenRepo: userTokenRepo,
+		transactor:    transactor,
+		mail:          mail,
+	}
+}
+
+// RequestPasswordReset mails a reset link if email belongs to an account.
+// Unknown addresses succeed silently so the endpoint can't be used to
+// probe for accounts.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, domain.NormalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, message, err := s.mail.PasswordReset(user)
+	if err != nil {
+		return err
+	}
+
+	return s.userTokenRepo.Create(ctx, token, message)
+}
+
+// ResetPassword sets a new password and signs the user out everywhere, in
+// case the old one was how someone else got in. The token is only spent if
+// both happen.
+func (s *AccountService) ResetPassword(ctx context.Context, token, password string) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, user, err := s.consume(ctx, domain.UserTokenPasswordReset, token)
+		if err != nil {
+			return err
+		}
+
+		if err = user.SetPassword(password); err != nil {
+			return err
+		}
+
+		if err = s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+
+		_, err = s.sessionRepo.RevokeAll(ctx, user.Id)
+		return err
+	})
+}
+
+func (s *AccountService) RequestEmailVerification(ctx context.Context, userId int64) error {
+	user, err := s.userRepo.GetById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return sendEmailVerification(ctx, s.userTokenRepo, s.mail, user)
+}
+
+// VerifyEmail marks the user's address verified, provided it is still the
+// address the token was mailed to.
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	userToken, user, err := s.consume(ctx, domain.UserTokenEmailVerification, token)
+	if err != nil {
+		return err
+	}
+
+	if userToken.Email != user.Email {
+		return domain.ErrInvalidToken
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+
+	return s.userRepo.Update(ctx, user)
+}
+
+func (s *AccountService) consume(ctx context.Context, purpose domain.UserTokenPurpose, token string) (*domain.UserToken, *domain.User, error) {
+	userToken, err := s.userTokenRepo.Consume(ctx, purpose, domain.HashToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil, domain.ErrInvalidToken
+		}
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.GetById(ctx, userToken.UserId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return userToken, user, nil
+}
+
+func sendEmailVerification(ctx context.Context, userTokenRepo port.UserTokenRepository, mail domain.AccountMail, user *domain.User) error {
+	token, message, err := mail.EmailVerification(user)
+	if err != nil {
+		return err
+	}
+	return userTokenRepo.Create(ctx, token, message)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strings"
+	"testing"
+)
+
+func TestRequestPasswordReset(t *testing.T) {
+	t.Run("should_queue_reset_email", func(t *testing.T) {
+		mockUserRepo := new(repository.MockUserRepository)
+		mockUserTokenRepo := new(repository.MockUserTokenRepository)
+		accountServ := NewAccountService(mockUserRepo, nil, mockUserTokenRepo, new(repository.MockTransactor), testAccountMail)
+
+		user := &domain.User{Id: 42, Email: "jane@example.com", Name: "Jane"}
+		mockUserRepo.On("GetByEmail", mock.Anything, "jane@example.com").Return(user, nil)
+		mockUserTokenRepo.On("Create", mock.Anything, mock.MatchedBy(func(token *domain.UserToken) bool {
+			return token.UserId == 42 && token.Purpose == domain.UserTokenPasswordReset
+		}), mock.MatchedBy(func(email *domain.Email) bool {
+			return email.To == "jane@example.com" && strings.Contains(email.Body, "https://shop.example.com/reset-password?token=")
+		})).Return(nil)
+
+		err := accountServ.RequestPasswordReset(context.Background(), "Jane@example.com")
+
+		assert.NoError(t, err)
+		mockUserTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_not_reveal_unknown_email", func(t *testing.T) {
+		mockUserRepo := new(repository.MockUserRepository)
+		mockUserTokenRepo := new(repository.MockUserTokenRepository)
+		accountServ := NewAccountService(mockUserRepo, nil, mockUserTokenRepo, new(repository.MockTransactor), testAccountMail)
+
+		mockUserRepo.On("GetByEmail", mock.Anything, "john@example.com").Return(nil, domain.ErrNotFound)
+
+		err := accountServ.RequestPasswordReset(context.Background(), "john@example.com")
+
+		assert.NoError(t, err)
+		mockUserTokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	t.Run("should_set_password_and_revoke_sessions", func(t *testing.T) {
+		mockUserRepo := new(repository.MockUserRepository)
+		mockSessionRepo := new(repository.MockSessionRepository)
+		mockUserTokenRepo := new(repository.MockUserTokenRepository)
+		accountServ := NewAccountService(mockUserRepo, mockSessionRepo, mockUserTokenRepo, new(repository.MockTransactor), testAccountMail)
+
+		user := &domain.User{Id: 42}
+		mockUserTokenRepo.On("Consume", mock.Anything, domain.UserTokenPasswordReset, domain.HashToken("abc")).Return(&domain.UserToken{UserId: 42}, nil)
+		mockUserRepo.On("GetById", mock.Anything, int64(42)).Return(user, nil)
+		mockUserRepo.On("Update", mock.Anything, user).Return(nil)
+		mockSessionRepo.On("RevokeAll", mock.Anything, int64(42)).Return(int64(2), nil)
+
+		err := accountServ.ResetPassword(context.Background(), "abc", "new password")
+
+		assert.NoError(t, err)
+		assert.True(t, user.CheckPassword("new password"))
+		mockSessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_reject_spent_token", func(t *testing.T) {
+		mockUserTokenRepo := new(repository.MockUserTokenRepository)
+		accountServ := NewAccountService(nil, nil, mockUserTokenRepo, new(repository.MockTransactor), testAccountMail)
+
+		mockUserTokenRepo.On("Consume", mock.Anything, domain.UserTokenPasswordReset, domain.HashToken("abc")).Return(nil, domain.ErrNotFound)
+
+		err := accountServ.ResetPassword(context.Background(), "abc", "new password")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	t.Run("should_mark_email_verified", func(t *testing.T) {
+		mockUserRepo := new(repository.MockUserRepository)
+		mockUserTokenRepo := new(repository.MockUserTokenRepository)
+		accountServ := NewAccountService(mockUserRepo, nil, mockUserTokenRepo, new(repository.MockTransactor), testAccountMail)
+
+		user := &domain.User{Id: 42, Email: "jane@example.com"}
+		mockUserTokenRepo.On("Consume", mock.Anything, domain.UserTokenEmailVerification, domain.HashToken("abc")).Return(&domain.UserToken{UserId: 42, Email: "jane@example.com"}, nil)
+		mockUserRepo.On("GetById", mock.Anything, int64(42)).Return(user, nil)
+		mockUserRepo.On("Update", mock.Anything, user).Return(nil)
+
+		err := accountServ.VerifyEmail(context.Background(), "abc")
+
+		assert.NoError(t, err)
+		assert.NotNil(t, user.EmailVerifiedAt)
+	})
+
+	t.Run("should_reject_token_sent_to_previous_email", func(t *testing.T) {
+		mockUserRepo := new(repository.MockUserRepository)
+		mockUserTokenRepo := new(repository.MockUserTokenRepository)
+		accountServ := NewAccountService(mockUserRepo, nil, mockUserTokenRepo, new(repository.MockTransactor), testAccountMail)
+
+		user := &domain.User{Id: 42, Email: "jane.new@example.com"}
+		mockUserTokenRepo.On("Consume", mock.Anything, domain.UserTokenEmailVerification, domain.HashToken("abc")).Return(&domain.UserToken{UserId: 42, Email: "jane@example.com"}, nil)
+		mockUserRepo.On("GetById", mock.Anything, int64(42)).Return(user, nil)
+
+		err := accountServ.VerifyEmail(context.Background(), "abc")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+		assert.Nil(t, user.EmailVerifiedAt)
+		mockUserRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"time"
+)
+
+type ApiKeyService struct {
+	apiKeyRepo port.ApiKeyRepository
+}
+
+func NewApiKeyService(apiKeyRepo port.ApiKeyRepository) *ApiKeyService {
+	return &ApiKeyService{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// Create generates a key and returns it in plain text; this is the only
+// time it can be seen.
+func (s *ApiKeyService) Create(ctx context.Context, name string, scopes []domain.Permission, expiresAt *time.Time, createdBy *int64) (string, *domain.ApiKey, error) {
+	plain, key, err := domain.NewApiKey(name, scopes, expiresAt, createdBy)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err = s.apiKeyRepo.Create(ctx, key); err != nil {
+		return "", nil, err
+	}
+
+	return plain, key, nil
+}
+
+func (s *ApiKeyService) List(ctx context.Context) ([]domain.ApiKey, error) {
+	return s.apiKeyRepo.List(ctx)
+}
+
+func (s *ApiKeyService) Revoke(ctx context.Context, id int64) error {
+	return s.apiKeyRepo.Revoke(ctx, id)
+}
+
+func (s *ApiKeyService) Authenticate(ctx context.Context, plain string) (*domain.Principal, error) {
+	prefix, secret, err := domain.ParseApiKey(plain)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.apiKeyRepo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrInvalidApiKey
+		}
+		return nil, err
+	}
+
+	if !key.Matches(secret) || !key.IsActive(time.Now()) {
+		return nil, domain.ErrInvalidApiKey
+	}
+
+	if err = s.apiKeyRepo.TouchLastUsed(ctx, key.Id); err != nil {
+		return nil, err
+	}
+
+	return key.Principal(), nil
+}
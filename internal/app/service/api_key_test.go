@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestAuthenticateApiKey(t *testing.T) {
+	plain, key, err := domain.NewApiKey("erp", []domain.Permission{domain.PermissionProductsWrite}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key.Id = 3
+
+	t.Run("should_resolve_key_to_its_scopes", func(t *testing.T) {
+		mockApiKeyRepo := new(repository.MockApiKeyRepository)
+		apiKeyServ := NewApiKeyService(mockApiKeyRepo)
+
+		mockApiKeyRepo.On("GetByPrefix", mock.Anything, key.Prefix).Return(key, nil)
+		mockApiKeyRepo.On("TouchLastUsed", mock.Anything, int64(3)).Return(nil)
+
+		principal, err := apiKeyServ.Authenticate(context.Background(), plain)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), principal.ApiKeyId)
+		assert.True(t, principal.Can(domain.PermissionProductsWrite))
+		mockApiKeyRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_reject_wrong_secret", func(t *testing.T) {
+		mockApiKeyRepo := new(repository.MockApiKeyRepository)
+		apiKeyServ := NewApiKeyService(mockApiKeyRepo)
+
+		mockApiKeyRepo.On("GetByPrefix", mock.Anything, key.Prefix).Return(key, nil)
+
+		_, err := apiKeyServ.Authenticate(context.Background(), "ecom_"+key.Prefix+"_forged")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidApiKey)
+		mockApiKeyRepo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should_reject_revoked_key", func(t *testing.T) {
+		mockApiKeyRepo := new(repository.MockApiKeyRepository)
+		apiKeyServ := NewApiKeyService(mockApiKeyRepo)
+
+		revokedAt := time.Now()
+		revoked := *key
+		revoked.RevokedAt = &revokedAt
+		mockApiKeyRepo.On("GetByPrefix", mock.Anything, key.Prefix).Return(&revoked, nil)
+
+		_, err := apiKeyServ.Authenticate(context.Background(), plain)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidApiKey)
+	})
+
+	t.Run("should_reject_unknown_prefix", func(t *testing.T) {
+		mockApiKeyRepo := new(repository.MockApiKeyRepository)
+		apiKeyServ := NewApiKeyService(mockApiKeyRepo)
+
+		mockApiKeyRepo.On("GetByPrefix", mock.Anything, "deadbeef").Return(nil, domain.ErrNotFound)
+
+		_, err := apiKeyServ.Authenticate(context.Background(), "ecom_deadbeef_secret")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidApiKey)
+	})
+}
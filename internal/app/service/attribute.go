@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+)
+
+type AttributeService struct {
+	attributeRepo port.AttributeRepository
+	categoryRepo  port.CategoryRepository
+}
+
+func NewAttributeService(attributeRepo port.AttributeRepository, categoryRepo port.CategoryRepository) *AttributeService {
+	return &AttributeService{
+		attributeRepo: attributeRepo,
+		categoryRepo:  categoryRepo,
+	}
+}
+
+func (s *AttributeService) ListDefinitions(ctx context.Context, categorySlug string) ([]domain.AttributeDefinition, error) {
+	category, err := s.categoryRepo.GetBySlug(ctx, categorySlug)
+	if err != nil {
+		return nil, err
+	}
+	return s.attributeRepo.ListDefinitions(ctx, category.Id)
+}
+
+func (s *AttributeService) CreateDefinition(ctx context.Context, categorySlug string, definition *domain.AttributeDefinition) error {
+	if err := definition.Validate(); err != nil {
+		return err
+	}
+
+	category, err := s.categoryRepo.GetBySlug(ctx, categorySlug)
+	if err != nil {
+		return err
+	}
+	definition.CategoryId = category.Id
+
+	return s.attributeRepo.CreateDefinition(ctx, definition)
+}
+
+func (s *AttributeService) DeleteDefinition(ctx context.Context, categorySlug string, code string) error {
+	category, err := s.categoryRepo.GetBySlug(ctx, categorySlug)
+	if err != nil {
+		return err
+	}
+	return s.attributeRepo.DeleteDefinition(ctx, category.Id, code)
+}
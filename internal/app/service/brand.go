@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/app/util"
+)
+
+type BrandService struct {
+	brandRepo       port.BrandRepository
+	productRepo     port.ProductRepository
+	suggestionCache port.SuggestionCache
+}
+
+// NewBrandService creates a brand service. suggestionCache may be nil; when
+// set it is purged after every brand write.
+func NewBrandService(brandRepo port.BrandRepository, productRepo port.ProductRepository, suggestionCache port.SuggestionCache) *BrandService {
+	return &BrandService{
+		brandRepo:       brandRepo,
+		productRepo:     productRepo,
+		suggestionCache: suggestionCache,
+	}
+}
+
+func (s *BrandService) GetBySlug(ctx context.Context, slug string) (*domain.Brand, error) {
+	return s.brandRepo.GetBySlug(ctx, slug)
+}
+
+func (s *BrandService) Create(ctx context.Context, brand *domain.Brand) error {
+	slug, err := util.GenerateUniqueSlug(ctx, brand.Name, s.brandRepo.SlugExists)
+	if err != nil {
+		return err
+	}
+	brand.Slug = slug
+
+	if err = s.brandRepo.Create(ctx, brand); err != nil {
+		return err
+	}
+
+	purgeSuggestions(s.suggestionCache)
+	return nil
+}
+
+func (s *BrandService) Delete(ctx context.Context, slug string) error {
+	brand, err := s.brandRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	if err = s.brandRepo.Delete(ctx, brand.Id); err != nil {
+		return err
+	}
+
+	purgeSuggestions(s.suggestionCache)
+	return nil
+}
+
+func (s *BrandService) Update(ctx context.Context, slug string, brand *domain.Brand) error {
+	existingBrand, err := s.brandRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	brand.Id = existingBrand.Id
+
+	if existingBrand.Name != brand.Name {
+		slug, err := util.GenerateUniqueSlug(ctx, brand.Name, s.brandRepo.SlugExists)
+		if err != nil {
+			return err
+		}
+		brand.Slug = slug
+	} else {
+		brand.Slug = existingBrand.Slug
+	}
+
+	if err = s.brandRepo.Update(ctx, brand); err != nil {
+		return err
+	}
+
+	purgeSuggestions(s.suggestionCache)
+	return nil
+}
+
+func (s *BrandService) List(ctx context.Context, query domain.PaginatedBrandsQuery) ([]domain.Brand, domain.Meta, error) {
+	return s.brandRepo.List(ctx, query)
+}
+
+func (s *BrandService) ListProducts(ctx context.Context, slug string, query domain.PaginatedProductsQuery) ([]domain.ProductSummary, domain.Meta, error) {
+	brand, err := s.brandRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, domain.Meta{}, err
+	}
+
+	query.Brands = []string{brand.Slug}
+
+	return s.productRepo.List(ctx, query)
+}
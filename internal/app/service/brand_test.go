@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestCreateBrand(t *testing.T) {
+	t.Run("should_create_brand_with_slug", func(t *testing.T) {
+		mockBrandRepo := new(repository.MockBrandRepository)
+		brandServ := NewBrandService(mockBrandRepo, nil, nil)
+
+		brandId := int64(123)
+		slug := "mock-brand"
+
+		mockBrand := &domain.Brand{
+			Name: "Mock Brand",
+		}
+
+		mockBrandRepo.On("SlugExists", mock.Anything, slug).Return(false, nil)
+		mockBrandRepo.On("Create", mock.Anything, mockBrand).Run(func(args mock.Arguments) {
+			brand := args.Get(1).(*domain.Brand)
+			brand.Id = brandId
+		}).Return(nil)
+
+		ctx := context.Background()
+		err := brandServ.Create(ctx, mockBrand)
+
+		assert.NoError(t, err)
+		assert.Equal(t, brandId, mockBrand.Id)
+		assert.Equal(t, slug, mockBrand.Slug)
+
+		mockBrandRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_return_conflict_when_name_taken", func(t *testing.T) {
+		mockBrandRepo := new(repository.MockBrandRepository)
+		brandServ := NewBrandService(mockBrandRepo, nil, nil)
+
+		mockBrand := &domain.Brand{
+			Name: "Mock Brand",
+		}
+
+		mockBrandRepo.On("SlugExists", mock.Anything, "mock-brand").Return(false, nil)
+		mockBrandRepo.On("Create", mock.Anything, mockBrand).Return(domain.ErrConflict)
+
+		ctx := context.Background()
+		err := brandServ.Create(ctx, mockBrand)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+
+		mockBrandRepo.AssertExpectations(t)
+	})
+}
+
+func TestUpdateBrand(t *testing.T) {
+	t.Run("should_keep_slug_when_name_unchanged", func(t *testing.T) {
+		mockBrandRepo := new(repository.MockBrandRepository)
+		brandServ := NewBrandService(mockBrandRepo, nil, nil)
+
+		existingBrand := &domain.Brand{
+			Id:   123,
+			Name: "Mock Brand",
+			Slug: "mock-brand",
+		}
+
+		description := "Updated mock description"
+		brand := &domain.Brand{
+			Name:        "Mock Brand",
+			Description: &description,
+		}
+
+		mockBrandRepo.On("GetBySlug", mock.Anything, "mock-brand").Return(existingBrand, nil)
+		mockBrandRepo.On("Update", mock.Anything, brand).Return(nil)
+
+		ctx := context.Background()
+		err := brandServ.Update(ctx, "mock-brand", brand)
+
+		assert.NoError(t, err)
+		assert.Equal(t, existingBrand.Id, brand.Id)
+		assert.Equal(t, existingBrand.Slug, brand.Slug)
+
+		mockBrandRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_regenerate_slug_when_name_changed", func(t *testing.T) {
+		mockBrandRepo := new(repository.MockBrandRepository)
+		brandServ := NewBrandService(mockBrandRepo, nil, nil)
+
+		existingBrand := &domain.Brand{
+			Id:   123,
+			Name: "Mock Brand",
+			Slug: "mock-brand",
+		}
+
+		brand := &domain.Brand{
+			Name: "Renamed Brand",
+		}
+
+		mockBrandRepo.On("GetBySlug", mock.Anything, "mock-brand").Return(existingBrand, nil)
+		mockBrandRepo.On("SlugExists", mock.Anything, "renamed-brand").Return(false, nil)
+		mockBrandRepo.On("Update", mock.Anything, brand).Return(nil)
+
+		ctx := context.Background()
+		err := brandServ.Update(ctx, "mock-brand", brand)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "renamed-brand", brand.Slug)
+
+		mockBrandRepo.AssertExpectations(t)
+	})
+}
+
+func TestListBrandProducts(t *testing.T) {
+	t.Run("should_filter_products_by_brand", func(t *testing.T) {
+		mockBrandRepo := new(repository.MockBrandRepository)
+		mockProductRepo := new(repository.MockProductRepository)
+		brandServ := NewBrandService(mockBrandRepo, mockProductRepo, nil)
+
+		brand := &domain.Brand{
+			Id:   123,
+			Name: "Mock Brand",
+			Slug: "mock-brand",
+		}
+
+		query := domain.PaginatedProductsQuery{Limit: 20}
+		expectedQuery := query
+		expectedQuery.Brands = []string{"mock-brand"}
+
+		mockBrandRepo.On("GetBySlug", mock.Anything, "mock-brand").Return(brand, nil)
+		mockProductRepo.On("List", mock.Anything, expectedQuery).Return([]domain.ProductSummary{}, domain.Meta{}, nil)
+
+		ctx := context.Background()
+		_, _, err := brandServ.ListProducts(ctx, "mock-brand", query)
+
+		assert.NoError(t, err)
+
+		mockBrandRepo.AssertExpectations(t)
+		mockProductRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_return_error_when_brand_not_found", func(t *testing.T) {
+		mockBrandRepo := new(repository.MockBrandRepository)
+		mockProductRepo := new(repository.MockProductRepository)
+		brandServ := NewBrandService(mockBrandRepo, mockProductRepo, nil)
+
+		mockBrandRepo.On("GetBySlug", mock.Anything, "missing").Return(nil, domain.ErrNotFound)
+
+		ctx := context.Background()
+		products, _, err := brandServ.ListProducts(ctx, "missing", domain.PaginatedProductsQuery{})
+
+		assert.Nil(t, products)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		mockBrandRepo.AssertExpectations(t)
+		mockProductRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+	})
+}
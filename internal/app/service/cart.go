@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"time"
+)
+
+type CartService struct {
+	cartRepo    port.CartRepository
+	productRepo port.ProductRepository
+	ttl         time.Duration
+}
+
+// NewCartService returns a cart service whose carts expire once they have
+// gone untouched for ttl.
+func NewCartService(cartRepo port.CartRepository, productRepo port.ProductRepository, ttl time.Duration) *CartService {
+	return &CartService{
+		cartRepo:    cartRepo,
+		productRepo: productRepo,
+		ttl:         ttl,
+	}
+}
+
+// Get returns the owner's cart, or an empty one that isn't stored yet.
+func (s *CartService) Get(ctx context.Context, owner domain.CartOwner) (*domain.Cart, error) {
+	cart, err := s.find(ctx, owner)
+	if errors.Is(err, domain.ErrNotFound) {
+		return s.empty(owner), nil
+	}
+	return cart, err
+}
+
+func (s *CartService) AddItem(ctx context.Context, owner domain.CartOwner, productId int64, variantId *int64, quantity int64) (*domain.Cart, error) {
+	product, err := s.productRepo.GetById(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	price, stock, err := domain.ResolveCartLine(product, variantId)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := s.findOrCreate(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if currency := cart.Currency(); currency != "" && currency != price.Currency {
+		return nil, fmt.Errorf("%w: cart is priced in %s but product %d is in %s", domain.ErrCurrencyMismatch, currency, productId, price.Currency)
+	}
+
+	total := quantity
+	if existing := cart.FindItem(productId, variantId); existing != nil {
+		total += existing.Quantity
+	}
+	if total > stock {
+		return nil, fmt.Errorf("%w: only %d left", domain.ErrInsufficientStock, stock)
+	}
+
+	item := &domain.CartItem{ProductId: productId, VariantId: variantId, Quantity: quantity}
+	if err = s.cartRepo.AddItem(ctx, cart.Id, item); err != nil {
+		return nil, err
+	}
+
+	return s.touch(ctx, owner, cart)
+}
+
+func (s *CartService) UpdateItem(ctx context.Context, owner domain.CartOwner, itemId, quantity int64) (*domain.Cart, error) {
+	cart, err := s.find(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	item := cart.ItemById(itemId)
+	if item == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	if quantity > item.Stock {
+		return nil, fmt.Errorf("%w: only %d left", domain.ErrInsufficientStock, item.Stock)
+	}
+
+	if err = s.cartRepo.UpdateItem(ctx, cart.Id, itemId, quantity); err != nil {
+		return nil, err
+	}
+
+	return s.touch(ctx, owner, cart)
+}
+
+func (s *CartService) RemoveItem(ctx context.Context, owner domain.CartOwner, itemId int64) (*domain.Cart, error) {
+	cart, err := s.find(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.cartRepo.RemoveItem(ctx, cart.Id, itemId); err != nil {
+		return nil, err
+	}
+
+	return s.touch(ctx, owner, cart)
+}
+
+// Merge folds the anonymous cart behind token into the customer's cart,
+// as happens when a visitor signs in. Lines in another currency than the
+// customer's cart are dropped and quantities are capped at stock. If the
+// customer has no cart yet the anonymous one simply becomes theirs.
+func (s *CartService) Merge(ctx context.Context, token string, customerId int64) (*domain.Cart, error) {
+	customer := domain.CartOwner{CustomerId: customerId}
+
+	anonymous, err := s.cartRepo.GetByToken(ctx, token)
+	if errors.Is(err, domain.ErrNotFound) {
+		return s.Get(ctx, customer)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := s.cartRepo.GetByCustomer(ctx, customerId)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		err = s.cartRepo.AssignCustomer(ctx, anonymous.Id, customerId)
+		cart = anonymous
+	case err == nil:
+		err = s.cartRepo.Merge(ctx, anonymous.Id, cart.Id, cart.Currency())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.touch(ctx, customer, cart)
+}
+
+func (s *CartService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.cartRepo.DeleteExpired(ctx)
+}
+
+func (s *CartService) find(ctx context.Context, owner domain.CartOwner) (*domain.Cart, error) {
+	if !owner.IsAnonymous() {
+		return s.cartRepo.GetByCustomer(ctx, owner.CustomerId)
+	}
+	if owner.Token == "" {
+		return nil, domain.ErrNotFound
+	}
+	return s.cartRepo.GetByToken(ctx, owner.Token)
+}
+
+// findOrCreate returns the owner's cart, creating it on first use. Visitors
+// without a token, or whose cart expired, get a cart under a fresh token.
+func (s *CartService) findOrCreate(ctx context.Context, owner domain.CartOwner) (*domain.Cart, error) {
+	cart, err := s.find(ctx, owner)
+	if !errors.Is(err, domain.ErrNotFound) {
+		return cart, err
+	}
+
+	cart = s.empty(owner)
+	if owner.IsAnonymous() {
+		token, err := newCartToken()
+		if err != nil {
+			return nil, err
+		}
+		cart.Token = &token
+	}
+
+	if err = s.cartRepo.Create(ctx, cart); err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+// touch pushes back the expiry of a cart that was just used and returns it
+// reloaded, so prices and totals reflect the change.
+func (s *CartService) touch(ctx context.Context, owner domain.CartOwner, cart *domain.Cart) (*domain.Cart, error) {
+	if err := s.cartRepo.Touch(ctx, cart.Id, time.Now().Add(s.ttl)); err != nil {
+		return nil, err
+	}
+
+	if owner.IsAnonymous() && cart.Token != nil {
+		owner.Token = *cart.Token
+	}
+
+	return s.find(ctx, owner)
+}
+
+func (s *CartService) empty(owner domain.CartOwner) *domain.Cart {
+	cart := &domain.Cart{
+		Items:     []domain.CartItem{},
+		Subtotal:  domain.NewMoney(0, domain.DefaultCurrency),
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	if !owner.IsAnonymous() {
+		cart.CustomerId = &owner.CustomerId
+	}
+	return cart
+}
+
+func newCartToken() (string, error) {
+	token := make([]byte, 24)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestAddCartItem(t *testing.T) {
+	t.Run("should_create_anonymous_cart_with_token", func(t *testing.T) {
+		mockCartRepo := new(repository.MockCartRepository)
+		mockProductRepo := new(repository.MockProductRepository)
+		cartServ := NewCartService(mockCartRepo, mockProductRepo, time.Hour)
+
+		variantId := int64Ptr(2)
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(mockProductWithVariants(), nil)
+		mockCartRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Cart")).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.Cart).Id = 7
+		})
+		mockCartRepo.On("AddItem", mock.Anything, int64(7), &domain.CartItem{ProductId: 123, VariantId: variantId, Quantity: 2}).Return(nil)
+		mockCartRepo.On("Touch", mock.Anything, int64(7), mock.Anything).Return(nil)
+		mockCartRepo.On("GetByToken", mock.Anything, mock.AnythingOfType("string")).Return(&domain.Cart{Id: 7}, nil)
+
+		cart, err := cartServ.AddItem(context.Background(), domain.CartOwner{}, 123, variantId, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), cart.Id)
+
+		created := mockCartRepo.Calls[0].Arguments.Get(1).(*domain.Cart)
+		assert.NotNil(t, created.Token)
+		assert.Len(t, *created.Token, 48)
+
+		mockCartRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_require_variant_for_products_with_variants", func(t *testing.T) {
+		mockCartRepo := new(repository.MockCartRepository)
+		mockProductRepo := new(repository.MockProductRepository)
+		cartServ := NewCartService(mockCartRepo, mockProductRepo, time.Hour)
+
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(mockProductWithVariants(), nil)
+
+		_, err := cartServ.AddItem(context.Background(), domain.CartOwner{Token: "abc"}, 123, nil, 1)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidCartItem)
+		mockCartRepo.AssertNotCalled(t, "AddItem", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should_count_quantity_already_in_cart_against_stock", func(t *testing.T) {
+		mockCartRepo := new(repository.MockCartRepository)
+		mockProductRepo := new(repository.MockProductRepository)
+		cartServ := NewCartService(mockCartRepo, mockProductRepo, time.Hour)
+
+		variantId := int64Ptr(1)
+		existing := &domain.Cart{
+			Id: 7,
+			Items: []domain.CartItem{
+				{Id: 1, ProductId: 123, VariantId: variantId, Quantity: 2, Stock: 3, UnitPrice: domain.NewMoney(2000, "USD")},
+			},
+		}
+
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(mockProductWithVariants(), nil)
+		mockCartRepo.On("GetByToken", mock.Anything, "abc").Return(existing, nil)
+
+		_, err := cartServ.AddItem(context.Background(), domain.CartOwner{Token: "abc"}, 123, variantId, 2)
+
+		assert.ErrorIs(t, err, domain.ErrInsufficientStock)
+		mockCartRepo.AssertNotCalled(t, "AddItem", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMergeCart(t *testing.T) {
+	t.Run("should_merge_anonymous_cart_into_customer_cart", func(t *testing.T) {
+		mockCartRepo := new(repository.MockCartRepository)
+		cartServ := NewCartService(mockCartRepo, nil, time.Hour)
+
+		customerCart := &domain.Cart{Id: 2, Items: []domain.CartItem{{UnitPrice: domain.NewMoney(1000, "EUR"), Quantity: 1, Stock: 5}}}
+		mockCartRepo.On("GetByToken", mock.Anything, "abc").Return(&domain.Cart{Id: 1}, nil)
+		mockCartRepo.On("GetByCustomer", mock.Anything, int64(42)).Return(customerCart, nil)
+		mockCartRepo.On("Merge", mock.Anything, int64(1), int64(2), "EUR").Return(nil)
+		mockCartRepo.On("Touch", mock.Anything, int64(2), mock.Anything).Return(nil)
+
+		cart, err := cartServ.Merge(context.Background(), "abc", 42)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), cart.Id)
+		mockCartRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_hand_anonymous_cart_to_customer_without_cart", func(t *testing.T) {
+		mockCartRepo := new(repository.MockCartRepository)
+		cartServ := NewCartService(mockCartRepo, nil, time.Hour)
+
+		mockCartRepo.On("GetByToken", mock.Anything, "abc").Return(&domain.Cart{Id: 1}, nil)
+		mockCartRepo.On("GetByCustomer", mock.Anything, int64(42)).Return(nil, domain.ErrNotFound).Once()
+		mockCartRepo.On("AssignCustomer", mock.Anything, int64(1), int64(42)).Return(nil)
+		mockCartRepo.On("Touch", mock.Anything, int64(1), mock.Anything).Return(nil)
+		mockCartRepo.On("GetByCustomer", mock.Anything, int64(42)).Return(&domain.Cart{Id: 1}, nil).Once()
+
+		cart, err := cartServ.Merge(context.Background(), "abc", 42)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), cart.Id)
+		mockCartRepo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/app/util"
+)
+
+type CategoryService struct {
+	categoryRepo    port.CategoryRepository
+	transactor      port.Transactor
+	suggestionCache port.SuggestionCache
+}
+
+// NewCategoryService creates a category service. suggestionCache may be nil;
+// when set it is purged after every write that changes category names.
+func NewCategoryService(categoryRepo port.CategoryRepository, transactor port.Transactor, suggestionCache port.SuggestionCache) *CategoryService {
+	return &CategoryService{
+		categoryRepo:    categoryRepo,
+		transactor:      transactor,
+		suggestionCache: suggestionCache,
+	}
+}
+
+func (s *CategoryService) GetBySlug(ctx context.Context, slug string) (*domain.CategoryDetails, error) {
+	category, err := s.categoryRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := s.categoryRepo.ListChildren(ctx, category.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var breadcrumbs []domain.CategorySummary
+	for c := category; c != nil; c = c.Parent {
+		breadcrumbs = append([]domain.CategorySummary{{Id: c.Id, Name: c.Name, Slug: c.Slug}}, breadcrumbs...)
+	}
+
+	return &domain.CategoryDetails{
+		Category:    *category,
+		Breadcrumbs: breadcrumbs,
+		Children:    children,
+	}, nil
+}
+
+func (s *CategoryService) Create(ctx context.Context, category *domain.Category) error {
+	if category.ParentId != nil {
+		parent, err := s.getParent(ctx, *category.ParentId)
+		if err != nil {
+			return err
+		}
+		category.Parent = parent
+	}
+
+	slug, err := util.GenerateUniqueSlug(ctx, category.Name, s.categoryRepo.SlugExists)
+	if err != nil {
+		return err
+	}
+	category.Slug = slug
+
+	if err = s.categoryRepo.Create(ctx, category); err != nil {
+		return err
+	}
+
+	purgeSuggestions(s.suggestionCache)
+	return nil
+}
+
+func (s *CategoryService) Update(ctx context.Context, slug string, category *domain.Category) error {
+	existingCategory, err := s.categoryRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	category.Id = existingCategory.Id
+	category.ParentId = existingCategory.ParentId
+	category.Parent = existingCategory.Parent
+
+	if existingCategory.Name != category.Name {
+		slug, err := util.GenerateUniqueSlug(ctx, category.Name, s.categoryRepo.SlugExists)
+		if err != nil {
+			return err
+		}
+		category.Slug = slug
+	} else {
+		category.Slug = existingCategory.Slug
+	}
+
+	if err = s.categoryRepo.Update(ctx, category); err != nil {
+		return err
+	}
+
+	purgeSuggestions(s.suggestionCache)
+	return nil
+}
+
+// Move checks for cycles and rewrites the parent in one transaction, with
+// the category and the new parent's ancestors locked, so two concurrent
+// moves can't each pass the check and together close a loop.
+func (s *CategoryService) Move(ctx context.Context, slug string, parentId *int64) (*domain.Category, error) {
+	var category *domain.Category
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		category, err = s.categoryRepo.GetBySlug(ctx, slug)
+		if err != nil {
+			return err
+		}
+
+		var parent *domain.Category
+		if parentId != nil {
+			if *parentId == category.Id {
+				return domain.ErrCategoryCycle
+			}
+
+			if err = s.categoryRepo.LockPath(ctx, category.Id, *parentId); err != nil {
+				return err
+			}
+
+			parent, err = s.getParent(ctx, *parentId)
+			if err != nil {
+				return err
+			}
+
+			for ancestor := parent; ancestor != nil; ancestor = ancestor.Parent {
+				if ancestor.Id == category.Id {
+					return domain.ErrCategoryCycle
+				}
+			}
+		}
+
+		if err = s.categoryRepo.UpdateParent(ctx, category.Id, parentId); err != nil {
+			return err
+		}
+
+		category.ParentId = parentId
+		category.Parent = parent
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *CategoryService) Deactivate(ctx context.Context, slug string) error {
+	category, err := s.categoryRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	if err = s.categoryRepo.Deactivate(ctx, category.Id); err != nil {
+		return err
+	}
+
+	purgeSuggestions(s.suggestionCache)
+	return nil
+}
+
+func (s *CategoryService) Tree(ctx context.Context) ([]*domain.CategoryNode, error) {
+	categories, err := s.categoryRepo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[int64]*domain.CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.Id] = &domain.CategoryNode{
+			Id:       category.Id,
+			Name:     category.Name,
+			Slug:     category.Slug,
+			ImageUrl: category.ImageUrl,
+			Children: []*domain.CategoryNode{},
+		}
+	}
+
+	roots := []*domain.CategoryNode{}
+	for _, category := range categories {
+		node := nodes[category.Id]
+		if category.ParentId == nil {
+			roots = append(roots, node)
+			continue
+		}
+
+		// Categories whose parent is inactive are hidden along with it.
+		if parent, exists := nodes[*category.ParentId]; exists {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	return roots, nil
+}
+
+func (s *CategoryService) getParent(ctx context.Context, parentId int64) (*domain.Category, error) {
+	parent, err := s.categoryRepo.GetById(ctx, parentId)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			return nil, domain.ErrParentNotFound
+		default:
+			return nil, err
+		}
+	}
+	return parent, nil
+}
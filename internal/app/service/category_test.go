@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestCategoryTree(t *testing.T) {
+	t.Run("should_nest_children_under_parents", func(t *testing.T) {
+		mockCategoryRepo := new(repository.MockCategoryRepository)
+		categoryServ := NewCategoryService(mockCategoryRepo, new(repository.MockTransactor), nil)
+
+		categories := []domain.Category{
+			{Id: 1, Name: "Electronics", Slug: "electronics"},
+			{Id: 2, Name: "Phones", Slug: "phones", ParentId: int64Ptr(1)},
+			{Id: 3, Name: "Smartphones", Slug: "smartphones", ParentId: int64Ptr(2)},
+			{Id: 4, Name: "Clothing", Slug: "clothing"},
+			{Id: 5, Name: "Orphan", Slug: "orphan", ParentId: int64Ptr(99)},
+		}
+
+		mockCategoryRepo.On("ListActive", mock.Anything).Return(categories, nil)
+
+		ctx := context.Background()
+		tree, err := categoryServ.Tree(ctx)
+
+		assert.NoError(t, err)
+		assert.Len(t, tree, 2)
+		assert.Equal(t, "electronics", tree[0].Slug)
+		assert.Len(t, tree[0].Children, 1)
+		assert.Equal(t, "phones", tree[0].Children[0].Slug)
+		assert.Len(t, tree[0].Children[0].Children, 1)
+		assert.Equal(t, "smartphones", tree[0].Children[0].Children[0].Slug)
+		assert.Equal(t, "clothing", tree[1].Slug)
+		assert.Empty(t, tree[1].Children)
+
+		mockCategoryRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetCategoryBySlug(t *testing.T) {
+	t.Run("should_return_breadcrumbs_and_children", func(t *testing.T) {
+		mockCategoryRepo := new(repository.MockCategoryRepository)
+		categoryServ := NewCategoryService(mockCategoryRepo, new(repository.MockTransactor), nil)
+
+		root := &domain.Category{Id: 1, Name: "Electronics", Slug: "electronics"}
+		category := &domain.Category{Id: 2, Name: "Phones", Slug: "phones", ParentId: int64Ptr(1), Parent: root}
+		children := []domain.CategorySummary{{Id: 3, Name: "Smartphones", Slug: "smartphones"}}
+
+		mockCategoryRepo.On("GetBySlug", mock.Anything, "phones").Return(category, nil)
+		mockCategoryRepo.On("ListChildren", mock.Anything, int64(2)).Return(children, nil)
+
+		ctx := context.Background()
+		result, err := categoryServ.GetBySlug(ctx, "phones")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.CategorySummary{
+			{Id: 1, Name: "Electronics", Slug: "electronics"},
+			{Id: 2, Name: "Phones", Slug: "phones"},
+		}, result.Breadcrumbs)
+		assert.Equal(t, children, result.Children)
+
+		mockCategoryRepo.AssertExpectations(t)
+	})
+}
+
+func TestMoveCategory(t *testing.T) {
+	t.Run("should_reject_moving_under_descendant", func(t *testing.T) {
+		mockCategoryRepo := new(repository.MockCategoryRepository)
+		categoryServ := NewCategoryService(mockCategoryRepo, new(repository.MockTransactor), nil)
+
+		category := &domain.Category{Id: 1, Name: "Electronics", Slug: "electronics"}
+		child := &domain.Category{Id: 2, Name: "Phones", Slug: "phones", ParentId: int64Ptr(1), Parent: category}
+		grandchild := &domain.Category{Id: 3, Name: "Smartphones", Slug: "smartphones", ParentId: int64Ptr(2), Parent: child}
+
+		mockCategoryRepo.On("GetBySlug", mock.Anything, "electronics").Return(category, nil)
+		mockCategoryRepo.On("LockPath", mock.Anything, int64(1), int64(3)).Return(nil)
+		mockCategoryRepo.On("GetById", mock.Anything, int64(3)).Return(grandchild, nil)
+
+		ctx := context.Background()
+		result, err := categoryServ.Move(ctx, "electronics", int64Ptr(3))
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, domain.ErrCategoryCycle)
+
+		mockCategoryRepo.AssertExpectations(t)
+		mockCategoryRepo.AssertNotCalled(t, "UpdateParent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should_reject_moving_under_itself", func(t *testing.T) {
+		mockCategoryRepo := new(repository.MockCategoryRepository)
+		categoryServ := NewCategoryService(mockCategoryRepo, new(repository.MockTransactor), nil)
+
+		category := &domain.Category{Id: 1, Name: "Electronics", Slug: "electronics"}
+
+		mockCategoryRepo.On("GetBySlug", mock.Anything, "electronics").Return(category, nil)
+
+		ctx := context.Background()
+		_, err := categoryServ.Move(ctx, "electronics", int64Ptr(1))
+
+		assert.ErrorIs(t, err, domain.ErrCategoryCycle)
+	})
+
+	t.Run("should_move_category_to_new_parent", func(t *testing.T) {
+		mockCategoryRepo := new(repository.MockCategoryRepository)
+		categoryServ := NewCategoryService(mockCategoryRepo, new(repository.MockTransactor), nil)
+
+		category := &domain.Category{Id: 2, Name: "Phones", Slug: "phones", ParentId: int64Ptr(1)}
+		newParent := &domain.Category{Id: 4, Name: "Mobile", Slug: "mobile"}
+		parentId := int64Ptr(4)
+
+		mockCategoryRepo.On("GetBySlug", mock.Anything, "phones").Return(category, nil)
+		mockCategoryRepo.On("LockPath", mock.Anything, int64(2), int64(4)).Return(nil)
+		mockCategoryRepo.On("GetById", mock.Anything, int64(4)).Return(newParent, nil)
+		mockCategoryRepo.On("UpdateParent", mock.Anything, int64(2), parentId).Return(nil)
+
+		ctx := context.Background()
+		result, err := categoryServ.Move(ctx, "phones", parentId)
+
+		assert.NoError(t, err)
+		assert.Equal(t, parentId, result.ParentId)
+		assert.Equal(t, newParent, result.Parent)
+
+		mockCategoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_return_error_when_parent_not_found", func(t *testing.T) {
+		mockCategoryRepo := new(repository.MockCategoryRepository)
+		categoryServ := NewCategoryService(mockCategoryRepo, new(repository.MockTransactor), nil)
+
+		category := &domain.Category{Id: 2, Name: "Phones", Slug: "phones"}
+
+		mockCategoryRepo.On("GetBySlug", mock.Anything, "phones").Return(category, nil)
+		mockCategoryRepo.On("LockPath", mock.Anything, int64(2), int64(99)).Return(nil)
+		mockCategoryRepo.On("GetById", mock.Anything, int64(99)).Return(nil, domain.ErrNotFound)
+
+		ctx := context.Background()
+		_, err := categoryServ.Move(ctx, "phones", int64Ptr(99))
+
+		assert.ErrorIs(t, err, domain.ErrParentNotFound)
+	})
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+)
+
+type ImageService struct {
+	imageRepo   port.ImageRepository
+	productRepo port.ProductRepository
+	storage     port.FileStorage
+	processor   port.ImageProcessor
+}
+
+func NewImageService(imageRepo port.ImageRepository, productRepo port.ProductRepository, storage port.FileStorage, processor port.ImageProcessor) *ImageService {
+	return &ImageService{
+		imageRepo:   imageRepo,
+		productRepo: productRepo,
+		storage:     storage,
+		processor:   processor,
+	}
+}
+
+// Upload stores an image and its thumbnails and appends it to the product's
+// gallery. The first image of a product becomes its primary image.
+func (s *ImageService) Upload(ctx context.Context, productId int64, data []byte) (*domain.ProductImage, error) {
+	if _, err := s.productRepo.GetById(ctx, productId); err != nil {
+		return nil, err
+	}
+
+	processed, err := s.processor.Process(data, domain.ThumbnailSizes)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.imageRepo.ListByProduct(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := imageKeyPrefix(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	image := &domain.ProductImage{
+		ProductId:  productId,
+		MimeType:   processed.MimeType,
+		Width:      processed.Width,
+		Height:     processed.Height,
+		Position:   len(existing),
+		IsPrimary:  len(existing) == 0,
+		Thumbnails: map[string]string{},
+	}
+
+	image.Url, err = s.save(ctx, image, prefix+"/original"+processed.Extension, data, processed.MimeType)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, thumbnail := range processed.Thumbnails {
+		url, err := s.save(ctx, image, prefix+"/"+thumbnail.Size+thumbnail.Extension, thumbnail.Data, thumbnail.MimeType)
+		if err != nil {
+			return nil, err
+		}
+		image.Thumbnails[thumbnail.Size] = url
+	}
+
+	if err = s.imageRepo.Create(ctx, image); err != nil {
+		s.removeFiles(ctx, image)
+		return nil, err
+	}
+
+	return image, nil
+}
+
+func (s *ImageService) Delete(ctx context.Context, productId, id int64) error {
+	image, err := s.imageRepo.Delete(ctx, productId, id)
+	if err != nil {
+		return err
+	}
+
+	s.removeFiles(ctx, image)
+	return nil
+}
+
+func (s *ImageService) SetPrimary(ctx context.Context, productId, id int64) error {
+	return s.imageRepo.SetPrimary(ctx, productId, id)
+}
+
+func (s *ImageService) Reorder(ctx context.Context, productId int64, ids []int64) ([]domain.ProductImage, error) {
+	images, err := s.imageRepo.ListByProduct(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = domain.ValidateImageOrder(images, ids); err != nil {
+		return nil, err
+	}
+
+	if err = s.imageRepo.Reorder(ctx, productId, ids); err != nil {
+		return nil, err
+	}
+
+	return s.imageRepo.ListByProduct(ctx, productId)
+}
+
+// save stores one file of image, recording its key so it can be removed
+// along with the image. Files already saved are removed if it fails.
+func (s *ImageService) save(ctx context.Context, image *domain.ProductImage, key string, data []byte, contentType string) (string, error) {
+	url, err := s.storage.Save(ctx, key, bytes.NewReader(data), contentType)
+	if err != nil {
+		s.removeFiles(ctx, image)
+		return "", err
+	}
+
+	image.StorageKeys = append(image.StorageKeys, key)
+	return url, nil
+}
+
+// removeFiles deletes an image's files on a best effort basis; a leftover
+// file is harmless once nothing references it.
+func (s *ImageService) removeFiles(ctx context.Context, image *domain.ProductImage) {
+	for _, key := range image.StorageKeys {
+		_ = s.storage.Delete(ctx, key)
+	}
+}
+
+// imageKeyPrefix returns a fresh storage location for an upload, random so
+// URLs can't be guessed and re-uploads never collide with cached files.
+func imageKeyPrefix(productId int64) (string, error) {
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("products/%d/%s", productId, hex.EncodeToString(token)), nil
+}
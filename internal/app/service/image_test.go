@@ -0,0 +1,105 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/imaging"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+)
+
+type memoryStorage struct {
+	files map[string][]byte
+}
+
+func (s *memoryStorage) Save(ctx context.Context, key string, content io.Reader, contentType string) (string, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return "", err
+	}
+	s.files[key] = data
+	return "/media/" + key, nil
+}
+
+func (s *memoryStorage) Delete(ctx context.Context, key string) error {
+	delete(s.files, key)
+	return nil
+}
+
+func TestUploadImage(t *testing.T) {
+	var upload bytes.Buffer
+	if err := png.Encode(&upload, image.NewRGBA(image.Rect(0, 0, 64, 64))); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should_store_original_and_thumbnails", func(t *testing.T) {
+		mockImageRepo := new(repository.MockImageRepository)
+		mockProductRepo := new(repository.MockProductRepository)
+		storage := &memoryStorage{files: map[string][]byte{}}
+		imageServ := NewImageService(mockImageRepo, mockProductRepo, storage, imaging.NewProcessor())
+
+		mockProductRepo.On("GetById", mock.Anything, int64(1)).Return(&domain.Product{}, nil)
+		mockImageRepo.On("ListByProduct", mock.Anything, int64(1)).Return([]domain.ProductImage{}, nil)
+		mockImageRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.ProductImage")).Return(nil)
+
+		img, err := imageServ.Upload(context.Background(), 1, upload.Bytes())
+
+		assert.NoError(t, err)
+		assert.True(t, img.IsPrimary)
+		assert.Equal(t, "image/png", img.MimeType)
+		assert.Len(t, img.Thumbnails, len(domain.ThumbnailSizes))
+		assert.Len(t, storage.files, len(domain.ThumbnailSizes)+1)
+		assert.Contains(t, img.Thumbnails, domain.SummaryThumbnail)
+
+		mockImageRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_remove_files_when_saving_fails", func(t *testing.T) {
+		mockImageRepo := new(repository.MockImageRepository)
+		mockProductRepo := new(repository.MockProductRepository)
+		storage := &memoryStorage{files: map[string][]byte{}}
+		imageServ := NewImageService(mockImageRepo, mockProductRepo, storage, imaging.NewProcessor())
+
+		mockProductRepo.On("GetById", mock.Anything, int64(1)).Return(&domain.Product{}, nil)
+		mockImageRepo.On("ListByProduct", mock.Anything, int64(1)).Return([]domain.ProductImage{{Id: 3, IsPrimary: true}}, nil)
+		mockImageRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.ProductImage")).Return(assert.AnError)
+
+		_, err := imageServ.Upload(context.Background(), 1, upload.Bytes())
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Empty(t, storage.files)
+	})
+
+	t.Run("should_reject_unsupported_files", func(t *testing.T) {
+		mockImageRepo := new(repository.MockImageRepository)
+		mockProductRepo := new(repository.MockProductRepository)
+		imageServ := NewImageService(mockImageRepo, mockProductRepo, &memoryStorage{files: map[string][]byte{}}, imaging.NewProcessor())
+
+		mockProductRepo.On("GetById", mock.Anything, int64(1)).Return(&domain.Product{}, nil)
+
+		_, err := imageServ.Upload(context.Background(), 1, []byte("plain text"))
+
+		assert.ErrorIs(t, err, domain.ErrUnsupportedImage)
+		mockImageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestReorderImages(t *testing.T) {
+	t.Run("should_reject_incomplete_order", func(t *testing.T) {
+		mockImageRepo := new(repository.MockImageRepository)
+		imageServ := NewImageService(mockImageRepo, nil, nil, nil)
+
+		mockImageRepo.On("ListByProduct", mock.Anything, int64(1)).Return([]domain.ProductImage{{Id: 1}, {Id: 2}}, nil)
+
+		_, err := imageServ.Reorder(context.Background(), 1, []int64{2, 2})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidImageOrder)
+		mockImageRepo.AssertNotCalled(t, "Reorder", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
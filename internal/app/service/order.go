@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+)
+
+type OrderService struct {
+	orderRepo port.OrderRepository
+	cartRepo  port.CartRepository
+}
+
+func NewOrderService(orderRepo port.OrderRepository, cartRepo port.CartRepository) *OrderService {
+	return &OrderService{
+		orderRepo: orderRepo,
+		cartRepo:  cartRepo,
+	}
+}
+
+// Checkout places an order for everything in the owner's cart.
+func (s *OrderService) Checkout(ctx context.Context, owner domain.CartOwner) (*domain.Order, error) {
+	cart, err := s.ownerCart(ctx, owner)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrEmptyCart
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cart.Items) == 0 {
+		return nil, domain.ErrEmptyCart
+	}
+
+	order := domain.NewOrder(owner)
+	if err = s.orderRepo.PlaceFromCart(ctx, cart.Id, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// GetById returns one of the owner's orders. Other people's orders are
+// reported as missing rather than forbidden, so ids can't be probed. The
+// status history names staff and their reasons, so only admins see it.
+func (s *OrderService) GetById(ctx context.Context, owner domain.CartOwner, id int64) (*domain.Order, error) {
+	order, err := s.orderRepo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !order.IsOwnedBy(owner) {
+		return nil, domain.ErrNotFound
+	}
+
+	order.History = nil
+	return order, nil
+}
+
+func (s *OrderService) List(ctx context.Context, owner domain.CartOwner, query domain.PaginatedOrdersQuery) ([]domain.Order, domain.Meta, error) {
+	if owner.IsAnonymous() && owner.Token == "" {
+		return []domain.Order{}, domain.Meta{PageSize: query.Limit, CurrentPage: 1}, nil
+	}
+	return s.orderRepo.ListByOwner(ctx, owner, query)
+}
+
+func (s *OrderService) AdminGetById(ctx context.Context, id int64) (*domain.Order, error) {
+	return s.orderRepo.GetById(ctx, id)
+}
+
+// Transition moves an order to another status if its current status
+// allows it, recording who did it and why.
+func (s *OrderService) Transition(ctx context.Context, id int64, to domain.OrderStatus, actor string, reason *string) (*domain.Order, error) {
+	order, err := s.orderRepo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	transition, err := order.Transition(to, actor, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.orderRepo.Transition(ctx, id, transition); err != nil {
+		return nil, err
+	}
+
+	order.History = append(order.History, *transition)
+	return order, nil
+}
+
+func (s *OrderService) ownerCart(ctx context.Context, owner domain.CartOwner) (*domain.Cart, error) {
+	if !owner.IsAnonymous() {
+		return s.cartRepo.GetByCustomer(ctx, owner.CustomerId)
+	}
+	if owner.Token == "" {
+		return nil, domain.ErrNotFound
+	}
+	return s.cartRepo.GetByToken(ctx, owner.Token)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestCheckout(t *testing.T) {
+	t.Run("should_place_order_from_cart", func(t *testing.T) {
+		mockOrderRepo := new(repository.MockOrderRepository)
+		mockCartRepo := new(repository.MockCartRepository)
+		orderServ := NewOrderService(mockOrderRepo, mockCartRepo)
+
+		cart := &domain.Cart{Id: 7, Items: []domain.CartItem{{Id: 1, ProductId: 123, Quantity: 1}}}
+		mockCartRepo.On("GetByToken", mock.Anything, "abc").Return(cart, nil)
+		mockOrderRepo.On("PlaceFromCart", mock.Anything, int64(7), mock.AnythingOfType("*domain.Order")).Return(nil)
+
+		order, err := orderServ.Checkout(context.Background(), domain.CartOwner{Token: "abc"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", *order.GuestToken)
+		assert.Equal(t, domain.OrderStatusPendingPayment, order.Status)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_reject_checkout_without_cart", func(t *testing.T) {
+		mockOrderRepo := new(repository.MockOrderRepository)
+		mockCartRepo := new(repository.MockCartRepository)
+		orderServ := NewOrderService(mockOrderRepo, mockCartRepo)
+
+		mockCartRepo.On("GetByCustomer", mock.Anything, int64(42)).Return(nil, domain.ErrNotFound)
+
+		_, err := orderServ.Checkout(context.Background(), domain.CartOwner{CustomerId: 42})
+
+		assert.ErrorIs(t, err, domain.ErrEmptyCart)
+		mockOrderRepo.AssertNotCalled(t, "PlaceFromCart", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetOrder(t *testing.T) {
+	t.Run("should_hide_other_customers_orders", func(t *testing.T) {
+		mockOrderRepo := new(repository.MockOrderRepository)
+		orderServ := NewOrderService(mockOrderRepo, nil)
+
+		mockOrderRepo.On("GetById", mock.Anything, int64(1)).Return(domain.NewOrder(domain.CartOwner{CustomerId: 42}), nil)
+
+		_, err := orderServ.GetById(context.Background(), domain.CartOwner{CustomerId: 7}, 1)
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("should_hide_history_from_customers", func(t *testing.T) {
+		mockOrderRepo := new(repository.MockOrderRepository)
+		orderServ := NewOrderService(mockOrderRepo, nil)
+
+		reason := "suspected fraud"
+		order := domain.NewOrder(domain.CartOwner{CustomerId: 42})
+		order.History = []domain.OrderTransition{{From: domain.OrderStatusPendingPayment, To: domain.OrderStatusCancelled, Actor: "user:1", Reason: &reason}}
+		mockOrderRepo.On("GetById", mock.Anything, int64(1)).Return(order, nil)
+
+		result, err := orderServ.GetById(context.Background(), domain.CartOwner{CustomerId: 42}, 1)
+
+		assert.NoError(t, err)
+		assert.Nil(t, result.History)
+	})
+}
+
+func TestTransitionOrder(t *testing.T) {
+	t.Run("should_record_transition", func(t *testing.T) {
+		mockOrderRepo := new(repository.MockOrderRepository)
+		orderServ := NewOrderService(mockOrderRepo, nil)
+
+		reason := "paid by bank transfer"
+		mockOrderRepo.On("GetById", mock.Anything, int64(1)).Return(domain.NewOrder(domain.CartOwner{CustomerId: 42}), nil)
+		mockOrderRepo.On("Transition", mock.Anything, int64(1), &domain.OrderTransition{
+			From:   domain.OrderStatusPendingPayment,
+			To:     domain.OrderStatusPaid,
+			Actor:  "admin",
+			Reason: &reason,
+		}).Return(nil)
+
+		order, err := orderServ.Transition(context.Background(), 1, domain.OrderStatusPaid, "admin", &reason)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.OrderStatusPaid, order.Status)
+		assert.Len(t, order.History, 1)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_reject_disallowed_transition", func(t *testing.T) {
+		mockOrderRepo := new(repository.MockOrderRepository)
+		orderServ := NewOrderService(mockOrderRepo, nil)
+
+		mockOrderRepo.On("GetById", mock.Anything, int64(1)).Return(domain.NewOrder(domain.CartOwner{CustomerId: 42}), nil)
+
+		_, err := orderServ.Transition(context.Background(), 1, domain.OrderStatusDelivered, "admin", nil)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+		mockOrderRepo.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
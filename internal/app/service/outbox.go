@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"time"
+)
+
+const (
+	// outboxLease is how long a claimed email stays hidden from other
+	// senders; it outlasts a whole batch of sends timing out.
+	outboxLease       = 2 * time.Minute
+	outboxSendTimeout = 5 * time.Second
+)
+
+type OutboxService struct {
+	outboxRepo port.OutboxRepository
+	transport  port.EmailTransport
+}
+
+func NewOutboxService(outboxRepo port.OutboxRepository, transport port.EmailTransport) *OutboxService {
+	return &OutboxService{
+		outboxRepo: outboxRepo,
+		transport:  transport,
+	}
+}
+
+// Dispatch sends due emails. A failed send is rescheduled and doesn't stop
+// the rest of the batch.
+func (s *OutboxService) Dispatch(ctx context.Context, batchSize int) (int, error) {
+	emails, err := s.outboxRepo.Claim(ctx, batchSize, outboxLease)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, email := range emails {
+		sendCtx, cancel := context.WithTimeout(ctx, outboxSendTimeout)
+		err = s.transport.Send(sendCtx, email)
+		cancel()
+
+		if err != nil {
+			if err = s.outboxRepo.MarkFailed(ctx, email.Id, err.Error(), email.RetryAt(time.Now())); err != nil {
+				return sent, err
+			}
+			continue
+		}
+
+		if err = s.outboxRepo.MarkSent(ctx, email.Id); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type stubTransport struct {
+	failFor string
+	sent    []domain.Email
+}
+
+func (t *stubTransport) Send(ctx context.Context, email domain.Email) error {
+	if email.To == t.failFor {
+		return errors.New("connection refused")
+	}
+	t.sent = append(t.sent, email)
+	return nil
+}
+
+func TestDispatchOutbox(t *testing.T) {
+	t.Run("should_reschedule_failed_sends_and_continue", func(t *testing.T) {
+		mockOutboxRepo := new(repository.MockOutboxRepository)
+		transport := &stubTransport{failFor: "bounce@example.com"}
+		outboxServ := NewOutboxService(mockOutboxRepo, transport)
+
+		emails := []domain.Email{
+			{Id: 1, To: "bounce@example.com", Attempts: 1},
+			{Id: 2, To: "jane@example.com", Attempts: 1},
+		}
+		mockOutboxRepo.On("Claim", mock.Anything, 10, outboxLease).Return(emails, nil)
+		mockOutboxRepo.On("MarkFailed", mock.Anything, int64(1), "connection refused", mock.MatchedBy(func(at any) bool {
+			return at != nil
+		})).Return(nil)
+		mockOutboxRepo.On("MarkSent", mock.Anything, int64(2)).Return(nil)
+
+		sent, err := outboxServ.Dispatch(context.Background(), 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		assert.Len(t, transport.sent, 1)
+		mockOutboxRepo.AssertExpectations(t)
+	})
+}
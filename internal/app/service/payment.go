@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+)
+
+type PaymentService struct {
+	paymentRepo port.PaymentRepository
+	orderRepo   port.OrderRepository
+	gateway     port.PaymentGateway
+	transactor  port.Transactor
+}
+
+func NewPaymentService(paymentRepo port.PaymentRepository, orderRepo port.OrderRepository, gateway port.PaymentGateway, transactor port.Transactor) *PaymentService {
+	return &PaymentService{
+		paymentRepo: paymentRepo,
+		orderRepo:   orderRepo,
+		gateway:     gateway,
+		transactor:  transactor,
+	}
+}
+
+// Authorize asks the provider to hold the order total. The order is marked
+// paid once the provider confirms, by webhook or capture.
+func (s *PaymentService) Authorize(ctx context.Context, owner domain.CartOwner, orderId int64) (*domain.Payment, error) {
+	order, err := s.orderRepo.GetById(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !order.IsOwnedBy(owner) {
+		return nil, domain.ErrNotFound
+	}
+
+	if order.Status != domain.OrderStatusPendingPayment {
+		return nil, domain.ErrOrderNotPayable
+	}
+
+	result, err := s.gateway.Authorize(ctx, order.Id, order.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.settle(ctx, order, domain.PaymentOperationAuthorize, order.Total, result, "", "", nil)
+}
+
+// Capture takes the held funds and marks the order paid. If a provider
+// callback recorded the same capture first, that capture is returned.
+func (s *PaymentService) Capture(ctx context.Context, orderId int64, actor string) (*domain.Payment, error) {
+	order, authorization, err := s.authorizedOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != domain.OrderStatusPendingPayment {
+		return nil, domain.ErrOrderNotPayable
+	}
+
+	result, err := s.gateway.Capture(ctx, authorization.Reference, order.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err := s.settle(ctx, order, domain.PaymentOperationCapture, order.Total, result, domain.OrderStatusPaid, actor, nil)
+	if errors.Is(err, domain.ErrConflict) {
+		payments, listErr := s.paymentRepo.ListByOrder(ctx, orderId)
+		if listErr != nil {
+			return nil, listErr
+		}
+		if capture := domain.LatestSucceeded(payments, domain.PaymentOperationCapture); capture != nil && capture.Reference == result.Reference {
+			return capture, nil
+		}
+	}
+	return payment, err
+}
+
+// Void releases held funds of an unpaid order and cancels it, which puts
+// its items back in stock.
+func (s *PaymentService) Void(ctx context.Context, orderId int64, actor string) (*domain.Payment, error) {
+	order, authorization, err := s.authorizedOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !order.Status.CanTransitionTo(domain.OrderStatusCancelled) {
+		return nil, fmt.Errorf("%w: %s order cannot be voided", domain.ErrInvalidTransition, order.Status)
+	}
+
+	result, err := s.gateway.Void(ctx, authorization.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.settle(ctx, order, domain.PaymentOperationVoid, authorization.Amount, result, domain.OrderStatusCancelled, actor, nil)
+}
+
+// Refund returns the order total to the customer and marks the order
+// refunded.
+func (s *PaymentService) Refund(ctx context.Context, orderId int64, actor string, reason *string) (*domain.Payment, error) {
+	order, authorization, err := s.authorizedOrder(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !order.Status.CanTransitionTo(domain.OrderStatusRefunded) {
+		return nil, fmt.Errorf("%w: %s order cannot be refunded", domain.ErrInvalidTransition, order.Status)
+	}
+
+	result, err := s.gateway.Refund(ctx, authorization.Reference, order.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.settle(ctx, order, domain.PaymentOperationRefund, order.Total, result, domain.OrderStatusRefunded, actor, reason)
+}
+
+// HandleWebhook applies a provider callback. Providers retry callbacks, so
+// an event for an order that is already paid, or a capture already
+// recorded, is accepted and ignored. A success for any amount but the order
+// total is refused.
+func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := s.gateway.ParseWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	authorization, err := s.paymentRepo.GetAuthorization(ctx, s.gateway.Provider(), event.Reference)
+	if err != nil {
+		return err
+	}
+
+	order, err := s.orderRepo.GetById(ctx, authorization.OrderId)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != domain.OrderStatusPendingPayment {
+		return nil
+	}
+
+	result := &domain.PaymentResult{Reference: event.Reference, Status: domain.PaymentStatusSucceeded}
+	if event.Type == domain.PaymentEventFailed {
+		result.Status, result.Error = domain.PaymentStatusFailed, event.Error
+	} else if event.Amount != order.Total {
+		return fmt.Errorf("%w: captured %s of %s", domain.ErrPaymentAmountMismatch, event.Amount, order.Total)
+	}
+
+	_, err = s.settle(ctx, order, domain.PaymentOperationCapture, event.Amount, result, domain.OrderStatusPaid, "payment:"+s.gateway.Provider(), nil)
+	if errors.Is(err, domain.ErrPaymentDeclined) || errors.Is(err, domain.ErrConflict) {
+		// Declines are only recorded; a conflict means a concurrent callback
+		// or capture got there first.
+		return nil
+	}
+	return err
+}
+
+func (s *PaymentService) ListByOrder(ctx context.Context, orderId int64) ([]domain.Payment, error) {
+	if _, err := s.orderRepo.GetById(ctx, orderId); err != nil {
+		return nil, err
+	}
+	return s.paymentRepo.ListByOrder(ctx, orderId)
+}
+
+// authorizedOrder loads an order along with its latest successful
+// authorization, which later operations refer to.
+func (s *PaymentService) authorizedOrder(ctx context.Context, orderId int64) (*domain.Order, *domain.Payment, error) {
+	order, err := s.orderRepo.GetById(ctx, orderId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payments, err := s.paymentRepo.ListByOrder(ctx, orderId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	authorization := domain.LatestSucceeded(payments, domain.PaymentOperationAuthorize)
+	if authorization == nil {
+		return nil, nil, domain.ErrNoAuthorization
+	}
+
+	return order, authorization, nil
+}
+
+// settle persists the attempt whatever its outcome. A successful one also
+// moves the order to status to, unless to is empty, in the same
+// transaction, so a capture is never stored without the order being paid
+// or the other way round. A failed attempt is reported as
+// domain.ErrPaymentDeclined.
+func (s *PaymentService) settle(ctx context.Context, order *domain.Order, operation domain.PaymentOperation, amount domain.Money, result *domain.PaymentResult, to domain.OrderStatus, actor string, reason *string) (*domain.Payment, error) {
+	payment := domain.NewPayment(order, s.gateway.Provider(), operation, amount, result)
+
+	var transition *domain.OrderTransition
+	if payment.Status == domain.PaymentStatusSucceeded && to != "" {
+		var err error
+		if transition, err = order.Transition(to, actor, reason); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.paymentRepo.Create(ctx, payment); err != nil {
+			return err
+		}
+		if transition == nil {
+			return nil
+		}
+		return s.orderRepo.Transition(ctx, order.Id, transition)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Status == domain.PaymentStatusFailed {
+		return payment, fmt.Errorf("%w: %s", domain.ErrPaymentDeclined, result.Error)
+	}
+
+	return payment, nil
+}
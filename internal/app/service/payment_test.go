@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/payment"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func mockPayableOrder(total int64) *domain.Order {
+	order := domain.NewOrder(domain.CartOwner{Token: "abc"})
+	order.Id = 1
+	order.Total = domain.NewMoney(total, "USD")
+	return order
+}
+
+func TestAuthorizePayment(t *testing.T) {
+	t.Run("should_record_authorization", func(t *testing.T) {
+		mockPaymentRepo := new(repository.MockPaymentRepository)
+		mockOrderRepo := new(repository.MockOrderRepository)
+		paymentServ := NewPaymentService(mockPaymentRepo, mockOrderRepo, payment.NewFakeGateway("secret"), new(repository.MockTransactor))
+
+		mockOrderRepo.On("GetById", mock.Anything, int64(1)).Return(mockPayableOrder(2000), nil)
+		mockPaymentRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Payment")).Return(nil)
+
+		p, err := paymentServ.Authorize(context.Background(), domain.CartOwner{Token: "abc"}, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.PaymentOperationAuthorize, p.Operation)
+		assert.Equal(t, domain.PaymentStatusSucceeded, p.Status)
+		assert.Equal(t, int64(2000), p.Amount.Amount)
+		assert.NotEmpty(t, p.Reference)
+		mockPaymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_record_declined_attempt", func(t *testing.T) {
+		mockPaymentRepo := new(repository.MockPaymentRepository)
+		mockOrderRepo := new(repository.MockOrderRepository)
+		paymentServ := NewPaymentService(mockPaymentRepo, mockOrderRepo, payment.NewFakeGateway("secret"), new(repository.MockTransactor))
+
+		mockOrderRepo.On("GetById", mock.Anything, int64(1)).Return(mockPayableOrder(2013), nil)
+		mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *domain.Payment) bool {
+			return p.Status == domain.PaymentStatusFailed && p.Error != nil
+		})).Return(nil)
+
+		_, err := paymentServ.Authorize(context.Background(), domain.CartOwner{Token: "abc"}, 1)
+
+		assert.ErrorIs(t, err, domain.ErrPaymentDeclined)
+		mockPaymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_reject_paid_order", func(t *testing.T) {
+		mockPaymentRepo := new(repository.MockPaymentRepository)
+		mockOrderRepo := new(repository.MockOrderRepository)
+		paymentServ := NewPaymentService(mockPaymentRepo, mockOrderRepo, payment.NewFakeGateway("secret"), new(repository.MockTransactor))
+
+		order := mockPayableOrder(2000)
+		order.Status = domain.OrderStatusPaid
+		mockOrderRepo.On("GetById", mock.Anything, int64(1)).Return(order, nil)
+
+		_, err := paymentServ.Authorize(context.Background(), domain.CartOwner{Token: "abc"}, 1)
+
+		assert.ErrorIs(t, err, domain.ErrOrderNotPayable)
+		mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPaymentWebhook(t *testing.T) {
+	gateway := payment.NewFakeGateway("secret")
+	authorization := &domain.Payment{
+		OrderId:   1,
+		Provider:  payment.FakeProvider,
+		Operation: domain.PaymentOperationAuthorize,
+		Status:    domain.PaymentStatusSucceeded,
+		Amount:    domain.NewMoney(2000, "USD"),
+		Reference: "fake_abc",
+	}
+	payload, err := json.Marshal(domain.PaymentEvent{
+		Type:      domain.PaymentEventSucceeded,
+		Reference: "fake_abc",
+		Amount:    domain.NewMoney(2000, "USD"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should_mark_order_paid", func(t *testing.T) {
+		mockPaymentRepo := new(repository.MockPaymentRepository)
+		mockOrderRepo := new(repository.MockOrderRepository)
+		paymentServ := NewPaymentService(mockPaymentRepo, mockOrderRepo, gateway, new(repository.MockTransactor))
+
+		mockPaymentRepo.On("GetAuthorization", mock.Anything, payment.FakeProvider, "fake_abc").Return(authorization, nil)
+		mockOrderRepo.On("GetById", mock.Anything, int64(1)).Return(mockPayableOrder(2000), nil)
+		mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *domain.Payment) bool {
+			return p.Operation == domain.PaymentOperationCapture && p.Status == domain.PaymentStatusSucceeded
+		})).Return(nil)
+		mockOrderRepo.On("Transition", mock.Anything, int64(1), mock.MatchedBy(func(tr *domain.OrderTransition) bool {
+			return tr.To == domain.OrderStatusPaid && tr.Actor == "payment:fake"
+		})).Return(nil)
+
+		err := paymentServ.HandleWebhook(context.Background(), payload, gateway.Sign(payload))
+
+		assert.NoError(t, err)
+		mockPaymentRepo.AssertExpectations(t)
+		mockOrderRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_ignore_repeated_callback", func(t *testing.T) {
+		mockPaymentRepo := new(repository.MockPaymentRepository)
+		mockOrderRepo := new(repository.MockOrderRepository)
+		paymentServ := NewPaymentService(mockPaymentRepo, mockOrderRepo, gateway, new(repository.MockTransactor))
+
+		order := mockPayableOrder(2000)
+		order.Status = domain.OrderStatusPaid
+		mockPaymentRepo.On("GetAuthorization", mock.Anything, payment.FakeProvider, "fake_abc").Return(authorization, nil)
+		mockOrderRepo.On("GetById", mock.Anything, int64(1)).Return(order, nil)
+
+		err := paymentServ.HandleWebhook(context.Background(), payload, gateway.Sign(payload))
+
+		assert.NoError(t, err)
+		mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockOrderRepo.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should_ignore_capture_already_recorded", func(t *testing.T) {
+		mockPaymentRepo := new(repository.MockPaymentRepository)
+		mockOrderRepo := new(repository.MockOrderRepository)
+		paymentServ := NewPaymentService(mockPaymentRepo, mockOrderRepo, gateway, new(repository.MockTransactor))
+
+		mockPaymentRepo.On("GetAuthorization", mock.Anything, payment.FakeProvider, "fake_abc").Return(authorization, nil)
+		mockOrderRepo.On("GetById", mock.Anything, int64(1)).Return(mockPayableOrder(2000), nil)
+		mockPaymentRepo.On("Create", mock.Anything, mock.Anything).Return(domain.ErrConflict)
+
+		err := paymentServ.HandleWebhook(context.Background(), payload, gateway.Sign(payload))
+
+		assert.NoError(t, err)
+		mockOrderRepo.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should_reject_amount_other_than_total", func(t *testing.T) {
+		mockPaymentRepo := new(repository.MockPaymentRepository)
+		mockOrderRepo := new(repository.MockOrderRepository)
+		paymentServ := NewPaymentService(mockPaymentRepo, mockOrderRepo, gateway, new(repository.MockTransactor))
+
+		mockPaymentRepo.On("GetAuthorization", mock.Anything, payment.FakeProvider, "fake_abc").Return(authorization, nil)
+		mockOrderRepo.On("GetById", mock.Anything, int64(1)).Return(mockPayableOrder(5000), nil)
+
+		err := paymentServ.HandleWebhook(context.Background(), payload, gateway.Sign(payload))
+
+		assert.ErrorIs(t, err, domain.ErrPaymentAmountMismatch)
+		mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockOrderRepo.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should_reject_forged_signature", func(t *testing.T) {
+		mockPaymentRepo := new(repository.MockPaymentRepository)
+		paymentServ := NewPaymentService(mockPaymentRepo, nil, gateway, new(repository.MockTransactor))
+
+		err := paymentServ.HandleWebhook(context.Background(), payload, payment.NewFakeGateway("other").Sign(payload))
+
+		assert.ErrorIs(t, err, domain.ErrInvalidSignature)
+		mockPaymentRepo.AssertNotCalled(t, "GetAuthorization", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCapturePayment(t *testing.T) {
+	t.Run("should_return_capture_recorded_by_callback", func(t *testing.T) {
+		mockPaymentRepo := new(repository.MockPaymentRepository)
+		mockOrderRepo := new(repository.MockOrderRepository)
+		gateway := payment.NewFakeGateway("secret")
+		paymentServ := NewPaymentService(mockPaymentRepo, mockOrderRepo, gateway, new(repository.MockTransactor))
+
+		order := mockPayableOrder(2000)
+		authorized, err := gateway.Authorize(context.Background(), order.Id, order.Total)
+		assert.NoError(t, err)
+
+		authorization := domain.NewPayment(order, payment.FakeProvider, domain.PaymentOperationAuthorize, order.Total, authorized)
+		capture := domain.NewPayment(order, payment.FakeProvider, domain.PaymentOperationCapture, order.Total, authorized)
+
+		mockOrderRepo.On("GetById", mock.Anything, int64(1)).Return(order, nil)
+		mockPaymentRepo.On("ListByOrder", mock.Anything, int64(1)).Return([]domain.Payment{*authorization}, nil).Once()
+		mockPaymentRepo.On("Create", mock.Anything, mock.Anything).Return(domain.ErrConflict)
+		mockPaymentRepo.On("ListByOrder", mock.Anything, int64(1)).Return([]domain.Payment{*authorization, *capture}, nil).Once()
+
+		p, err := paymentServ.Capture(context.Background(), 1, "admin")
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.PaymentOperationCapture, p.Operation)
+		mockOrderRepo.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRefundPayment(t *testing.T) {
+	t.Run("should_require_authorization", func(t *testing.T) {
+		mockPaymentRepo := new(repository.MockPaymentRepository)
+		mockOrderRepo := new(repository.MockOrderRepository)
+		paymentServ := NewPaymentService(mockPaymentRepo, mockOrderRepo, payment.NewFakeGateway("secret"), new(repository.MockTransactor))
+
+		order := mockPayableOrder(2000)
+		order.Status = domain.OrderStatusPaid
+		mockOrderRepo.On("GetById", mock.Anything, int64(1)).Return(order, nil)
+		mockPaymentRepo.On("ListByOrder", mock.Anything, int64(1)).Return([]domain.Payment{}, nil)
+
+		_, err := paymentServ.Refund(context.Background(), 1, "admin", nil)
+
+		assert.ErrorIs(t, err, domain.ErrNoAuthorization)
+	})
+}
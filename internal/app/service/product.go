@@ -9,14 +9,22 @@ import (
 )
 
 type ProductService struct {
-	productRepo  port.ProductRepository
-	categoryRepo port.CategoryRepository
+	productRepo     port.ProductRepository
+	categoryRepo    port.CategoryRepository
+	attributeRepo   port.AttributeRepository
+	transactor      port.Transactor
+	suggestionCache port.SuggestionCache
 }
 
-func NewProductService(productRepo port.ProductRepository, categoryRepo port.CategoryRepository) *ProductService {
+// NewProductService creates a product service. suggestionCache may be nil;
+// when set it is purged after every product write.
+func NewProductService(productRepo port.ProductRepository, categoryRepo port.CategoryRepository, attributeRepo port.AttributeRepository, transactor port.Transactor, suggestionCache port.SuggestionCache) *ProductService {
 	return &ProductService{
-		productRepo:  productRepo,
-		categoryRepo: categoryRepo,
+		productRepo:     productRepo,
+		categoryRepo:    categoryRepo,
+		attributeRepo:   attributeRepo,
+		transactor:      transactor,
+		suggestionCache: suggestionCache,
 	}
 }
 
@@ -32,25 +40,120 @@ func (s *ProductService) GetById(ctx context.Context, id int64) (*domain.Product
 	}
 
 	product.Category = category
+	product.DeriveFromVariants()
 
 	return product, nil
 }
 
+// Create stores the product and its attribute values together, so a
+// product never exists without the attributes it was created with.
 func (s *ProductService) Create(ctx context.Context, product *domain.Product) error {
-	slug, err := util.GenerateUniqueSlug(ctx, product.Name, s.productRepo.SlugExists)
+	if err := product.ValidatePricing(); err != nil {
+		return err
+	}
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		attributes, err := s.parseAttributes(ctx, product)
+		if err != nil {
+			return err
+		}
+
+		slug, err := util.GenerateUniqueSlug(ctx, product.Name, s.productRepo.SlugExists)
+		if err != nil {
+			return err
+		}
+		product.Slug = slug
+
+		if err = s.productRepo.Create(ctx, product); err != nil {
+			return err
+		}
+
+		product.Attributes = attributes
+		return s.attributeRepo.SetProductValues(ctx, product.Id, attributes)
+	})
 	if err != nil {
 		return err
 	}
-	product.Slug = slug
-	return s.productRepo.Create(ctx, product)
+
+	purgeSuggestions(s.suggestionCache)
+	return nil
 }
 
-func (s *ProductService) Delete(ctx context.Context, id int64) error {
-	return s.productRepo.Delete(ctx, id)
+func (s *ProductService) Delete(ctx context.Context, id, version int64) error {
+	if err := s.productRepo.Delete(ctx, id, version); err != nil {
+		return err
+	}
+	purgeSuggestions(s.suggestionCache)
+	return nil
 }
 
+// Update reads the current product, picks the slug and writes the
+// changes in one transaction, so two concurrent renames can't both claim
+// the same slug or interleave their attribute values. product.Version is
+// the version the caller edited, or 0 to overwrite whatever is current.
 func (s *ProductService) Update(ctx context.Context, product *domain.Product) error {
-	existingProduct, err := s.productRepo.GetById(ctx, product.Id)
+	if err := product.ValidatePricing(); err != nil {
+		return err
+	}
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		existingProduct, err := s.productRepo.GetById(ctx, product.Id)
+		if err != nil {
+			return err
+		}
+
+		if product.Version != 0 && product.Version != existingProduct.Version {
+			return domain.ErrVersionMismatch
+		}
+
+		return s.save(ctx, product, existingProduct)
+	})
+	if err != nil {
+		return err
+	}
+
+	purgeSuggestions(s.suggestionCache)
+	return nil
+}
+
+// Patch loads the product as stored, lets apply change it and saves the
+// result like Update, all in one transaction. version works as for
+// Update. apply may run more than once, each time on a fresh copy. The
+// product is then read back as GetById shows it.
+func (s *ProductService) Patch(ctx context.Context, id, version int64, apply func(product *domain.Product) error) (*domain.Product, error) {
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		existingProduct, err := s.productRepo.GetById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if version != 0 && version != existingProduct.Version {
+			return domain.ErrVersionMismatch
+		}
+
+		patched := *existingProduct
+		if err = apply(&patched); err != nil {
+			return err
+		}
+
+		if err = patched.ValidatePricing(); err != nil {
+			return err
+		}
+
+		return s.save(ctx, &patched, existingProduct)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	purgeSuggestions(s.suggestionCache)
+	return s.GetById(ctx, id)
+}
+
+// save writes product over existingProduct, keeping the slug unless the
+// name changed.
+func (s *ProductService) save(ctx context.Context, product, existingProduct *domain.Product) error {
+	attributes, err := s.parseAttributes(ctx, product)
 	if err != nil {
 		return err
 	}
@@ -65,11 +168,41 @@ func (s *ProductService) Update(ctx context.Context, product *domain.Product) er
 		product.Slug = existingProduct.Slug
 	}
 
+	product.Version = existingProduct.Version
 	product.UpdatedAt = time.Now()
 
-	return s.productRepo.Update(ctx, product)
+	if err = s.productRepo.Update(ctx, product); err != nil {
+		return err
+	}
+
+	product.Attributes = attributes
+	return s.attributeRepo.SetProductValues(ctx, product.Id, attributes)
 }
 
 func (s *ProductService) List(ctx context.Context, query domain.PaginatedProductsQuery) ([]domain.ProductSummary, domain.Meta, error) {
 	return s.productRepo.List(ctx, query)
 }
+
+func (s *ProductService) ListByCursor(ctx context.Context, query domain.PaginatedProductsQuery) ([]domain.ProductSummary, domain.CursorMeta, error) {
+	return s.productRepo.ListByCursor(ctx, query)
+}
+
+func (s *ProductService) Facets(ctx context.Context, query domain.PaginatedProductsQuery) (domain.Facets, error) {
+	return s.productRepo.Facets(ctx, query)
+}
+
+// parseAttributes validates the raw attribute values on product against the
+// definitions of its category and returns them typed.
+func (s *ProductService) parseAttributes(ctx context.Context, product *domain.Product) ([]domain.ProductAttribute, error) {
+	definitions, err := s.attributeRepo.ListDefinitions(ctx, product.CategoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(map[string]any, len(product.Attributes))
+	for _, attribute := range product.Attributes {
+		raw[attribute.Code] = attribute.Value
+	}
+
+	return domain.ParseAttributes(definitions, raw)
+}
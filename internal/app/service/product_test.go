@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
 	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
 	"github.com/stretchr/testify/assert"
@@ -13,7 +14,7 @@ func TestGetProduct(t *testing.T) {
 	t.Run("should_return_product_with_category", func(t *testing.T) {
 		mockProductRepo := new(repository.MockProductRepository)
 		mockCategoryRepo := new(repository.MockCategoryRepository)
-		productServ := NewProductService(mockProductRepo, mockCategoryRepo)
+		productServ := NewProductService(mockProductRepo, mockCategoryRepo, nil, new(repository.MockTransactor), nil)
 
 		productId := int64(123)
 		categoryId := int64(456)
@@ -54,13 +55,13 @@ func TestGetProduct(t *testing.T) {
 	t.Run("should_return_product_with_optional_fields", func(t *testing.T) {
 		mockProductRepo := new(repository.MockProductRepository)
 		mockCategoryRepo := new(repository.MockCategoryRepository)
-		productServ := NewProductService(mockProductRepo, mockCategoryRepo)
+		productServ := NewProductService(mockProductRepo, mockCategoryRepo, nil, new(repository.MockTransactor), nil)
 
 		productId := int64(123)
 		categoryId := int64(456)
 
 		description := "Mock description"
-		salePrice := 99.99
+		salePrice := domain.NewMoney(9999, "USD")
 
 		mockProduct := &domain.Product{
 			BaseProduct: domain.BaseProduct{
@@ -101,7 +102,7 @@ func TestGetProduct(t *testing.T) {
 	t.Run("should_return_error_when_product_not_found", func(t *testing.T) {
 		mockProductRepo := new(repository.MockProductRepository)
 		mockCategoryRepo := new(repository.MockCategoryRepository)
-		productServ := NewProductService(mockProductRepo, mockCategoryRepo)
+		productServ := NewProductService(mockProductRepo, mockCategoryRepo, nil, new(repository.MockTransactor), nil)
 
 		productId := int64(123)
 
@@ -120,7 +121,7 @@ func TestGetProduct(t *testing.T) {
 	t.Run("should_return_error_when_category_not_found", func(t *testing.T) {
 		mockProductRepo := new(repository.MockProductRepository)
 		mockCategoryRepo := new(repository.MockCategoryRepository)
-		productServ := NewProductService(mockProductRepo, mockCategoryRepo)
+		productServ := NewProductService(mockProductRepo, mockCategoryRepo, nil, new(repository.MockTransactor), nil)
 
 		productId := int64(123)
 		categoryId := int64(999)
@@ -151,7 +152,8 @@ func TestGetProduct(t *testing.T) {
 func TestCreateProduct(t *testing.T) {
 	t.Run("should_create_product", func(t *testing.T) {
 		mockProductRepo := new(repository.MockProductRepository)
-		productServ := NewProductService(mockProductRepo, nil)
+		mockAttributeRepo := new(repository.MockAttributeRepository)
+		productServ := NewProductService(mockProductRepo, nil, mockAttributeRepo, new(repository.MockTransactor), nil)
 
 		productId := int64(123)
 		categoryId := int64(456)
@@ -163,7 +165,7 @@ func TestCreateProduct(t *testing.T) {
 		mockProduct := &domain.Product{
 			BaseProduct: domain.BaseProduct{
 				Name:       "Mock Product",
-				Price:      120,
+				Price:      domain.NewMoney(12000, "USD"),
 				SalePrice:  nil,
 				CategoryId: categoryId,
 				Stock:      10,
@@ -172,6 +174,8 @@ func TestCreateProduct(t *testing.T) {
 			Description: &description,
 		}
 
+		mockAttributeRepo.On("ListDefinitions", mock.Anything, categoryId).Return([]domain.AttributeDefinition{}, nil)
+		mockAttributeRepo.On("SetProductValues", mock.Anything, productId, []domain.ProductAttribute{}).Return(nil)
 		mockProductRepo.On("SlugExists", mock.Anything, slug).Return(false, nil)
 		mockProductRepo.On("Create", mock.Anything, mockProduct).Run(func(args mock.Arguments) {
 			product := args.Get(1).(*domain.Product)
@@ -193,5 +197,247 @@ func TestCreateProduct(t *testing.T) {
 		assert.Equal(t, mockProduct.Description, mockProduct.Description)
 
 		mockProductRepo.AssertExpectations(t)
+		mockAttributeRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_validate_attributes_against_category", func(t *testing.T) {
+		mockProductRepo := new(repository.MockProductRepository)
+		mockAttributeRepo := new(repository.MockAttributeRepository)
+		productServ := NewProductService(mockProductRepo, nil, mockAttributeRepo, new(repository.MockTransactor), nil)
+
+		productId := int64(123)
+		categoryId := int64(456)
+		unit := "GB"
+
+		definitions := []domain.AttributeDefinition{
+			{Id: 1, CategoryId: categoryId, Code: "ram_gb", Name: "RAM", Type: domain.AttributeTypeNumber, Unit: &unit, Required: true},
+			{Id: 2, CategoryId: categoryId, Code: "color", Name: "Color", Type: domain.AttributeTypeEnum, Values: []string{"black", "silver"}},
+		}
+
+		mockProduct := &domain.Product{
+			BaseProduct: domain.BaseProduct{
+				Name:       "Mock Laptop",
+				Price:      domain.NewMoney(99900, "USD"),
+				CategoryId: categoryId,
+			},
+			Attributes: []domain.ProductAttribute{
+				{Code: "ram_gb", Value: float64(16)},
+				{Code: "color", Value: "silver"},
+			},
+		}
+
+		expectedAttributes := []domain.ProductAttribute{
+			{AttributeId: 1, Code: "ram_gb", Name: "RAM", Type: domain.AttributeTypeNumber, Unit: &unit, Value: float64(16)},
+			{AttributeId: 2, Code: "color", Name: "Color", Type: domain.AttributeTypeEnum, Value: "silver"},
+		}
+
+		mockAttributeRepo.On("ListDefinitions", mock.Anything, categoryId).Return(definitions, nil)
+		mockAttributeRepo.On("SetProductValues", mock.Anything, productId, expectedAttributes).Return(nil)
+		mockProductRepo.On("SlugExists", mock.Anything, "mock-laptop").Return(false, nil)
+		mockProductRepo.On("Create", mock.Anything, mockProduct).Run(func(args mock.Arguments) {
+			product := args.Get(1).(*domain.Product)
+			product.Id = productId
+		}).Return(nil)
+
+		ctx := context.Background()
+		err := productServ.Create(ctx, mockProduct)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedAttributes, mockProduct.Attributes)
+
+		mockProductRepo.AssertExpectations(t)
+		mockAttributeRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_reject_invalid_attributes", func(t *testing.T) {
+		mockProductRepo := new(repository.MockProductRepository)
+		mockAttributeRepo := new(repository.MockAttributeRepository)
+		productServ := NewProductService(mockProductRepo, nil, mockAttributeRepo, new(repository.MockTransactor), nil)
+
+		categoryId := int64(456)
+
+		definitions := []domain.AttributeDefinition{
+			{Id: 1, CategoryId: categoryId, Code: "ram_gb", Name: "RAM", Type: domain.AttributeTypeNumber, Required: true},
+		}
+
+		mockProduct := &domain.Product{
+			BaseProduct: domain.BaseProduct{
+				Name:       "Mock Laptop",
+				Price:      domain.NewMoney(99900, "USD"),
+				CategoryId: categoryId,
+			},
+			Attributes: []domain.ProductAttribute{
+				{Code: "ram_gb", Value: "sixteen"},
+			},
+		}
+
+		mockAttributeRepo.On("ListDefinitions", mock.Anything, categoryId).Return(definitions, nil)
+
+		ctx := context.Background()
+		err := productServ.Create(ctx, mockProduct)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidAttribute)
+		mockProductRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUpdateProduct(t *testing.T) {
+	categoryId := int64(456)
+	existing := &domain.Product{
+		BaseProduct: domain.BaseProduct{
+			Id:         123,
+			Name:       "Mock Product",
+			Slug:       "mock-product",
+			Price:      domain.NewMoney(12000, "USD"),
+			CategoryId: categoryId,
+		},
+		Version: 3,
+	}
+
+	t.Run("should_update_current_version", func(t *testing.T) {
+		mockProductRepo := new(repository.MockProductRepository)
+		mockAttributeRepo := new(repository.MockAttributeRepository)
+		productServ := NewProductService(mockProductRepo, nil, mockAttributeRepo, new(repository.MockTransactor), nil)
+
+		product := &domain.Product{
+			BaseProduct: domain.BaseProduct{Id: 123, Name: "Mock Product", Price: domain.NewMoney(9900, "USD"), CategoryId: categoryId},
+			Version:     3,
+		}
+
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(existing, nil)
+		mockAttributeRepo.On("ListDefinitions", mock.Anything, categoryId).Return([]domain.AttributeDefinition{}, nil)
+		mockProductRepo.On("Update", mock.Anything, product).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.Product).Version++
+		}).Return(nil)
+		mockAttributeRepo.On("SetProductValues", mock.Anything, int64(123), []domain.ProductAttribute{}).Return(nil)
+
+		err := productServ.Update(context.Background(), product)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "mock-product", product.Slug)
+		assert.Equal(t, int64(4), product.Version)
+		mockProductRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_reject_stale_version", func(t *testing.T) {
+		mockProductRepo := new(repository.MockProductRepository)
+		productServ := NewProductService(mockProductRepo, nil, nil, new(repository.MockTransactor), nil)
+
+		product := &domain.Product{
+			BaseProduct: domain.BaseProduct{Id: 123, Name: "Mock Product", Price: domain.NewMoney(9900, "USD"), CategoryId: categoryId},
+			Version:     2,
+		}
+
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(existing, nil)
+
+		err := productServ.Update(context.Background(), product)
+
+		assert.ErrorIs(t, err, domain.ErrVersionMismatch)
+		mockProductRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPatchProduct(t *testing.T) {
+	categoryId := int64(456)
+	newExisting := func() *domain.Product {
+		return &domain.Product{
+			BaseProduct: domain.BaseProduct{
+				Id:         123,
+				Name:       "Mock Product",
+				Slug:       "mock-product",
+				Price:      domain.NewMoney(12000, "USD"),
+				Stock:      10,
+				CategoryId: categoryId,
+			},
+			Version: 3,
+		}
+	}
+
+	t.Run("should_keep_slug_when_name_is_unchanged", func(t *testing.T) {
+		mockProductRepo := new(repository.MockProductRepository)
+		mockCategoryRepo := new(repository.MockCategoryRepository)
+		mockAttributeRepo := new(repository.MockAttributeRepository)
+		productServ := NewProductService(mockProductRepo, mockCategoryRepo, mockAttributeRepo, new(repository.MockTransactor), nil)
+
+		stored := newExisting()
+		stored.Stock = 0
+		stored.Version = 4
+		category := &domain.Category{Id: categoryId, Name: "Shoes"}
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(newExisting(), nil).Once()
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(stored, nil).Once()
+		mockCategoryRepo.On("GetById", mock.Anything, categoryId).Return(category, nil)
+		mockAttributeRepo.On("ListDefinitions", mock.Anything, categoryId).Return([]domain.AttributeDefinition{}, nil)
+		mockProductRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *domain.Product) bool {
+			return p.Stock == 0 && p.Slug == "mock-product" && p.Version == 3
+		})).Return(nil)
+		mockAttributeRepo.On("SetProductValues", mock.Anything, int64(123), []domain.ProductAttribute{}).Return(nil)
+
+		product, err := productServ.Patch(context.Background(), 123, 0, func(p *domain.Product) error {
+			p.Stock = 0
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), product.Stock)
+		assert.Equal(t, int64(4), product.Version)
+		assert.Same(t, category, product.Category)
+		mockProductRepo.AssertNotCalled(t, "SlugExists", mock.Anything, mock.Anything)
+		mockProductRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_regenerate_slug_when_name_changes", func(t *testing.T) {
+		mockProductRepo := new(repository.MockProductRepository)
+		mockCategoryRepo := new(repository.MockCategoryRepository)
+		mockAttributeRepo := new(repository.MockAttributeRepository)
+		productServ := NewProductService(mockProductRepo, mockCategoryRepo, mockAttributeRepo, new(repository.MockTransactor), nil)
+
+		stored := newExisting()
+		stored.Name = "Renamed Product"
+		stored.Slug = "renamed-product"
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(newExisting(), nil).Once()
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(stored, nil).Once()
+		mockCategoryRepo.On("GetById", mock.Anything, categoryId).Return(&domain.Category{Id: categoryId}, nil)
+		mockAttributeRepo.On("ListDefinitions", mock.Anything, categoryId).Return([]domain.AttributeDefinition{}, nil)
+		mockProductRepo.On("SlugExists", mock.Anything, "renamed-product").Return(false, nil)
+		mockProductRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *domain.Product) bool {
+			return p.Slug == "renamed-product"
+		})).Return(nil)
+		mockAttributeRepo.On("SetProductValues", mock.Anything, int64(123), []domain.ProductAttribute{}).Return(nil)
+
+		product, err := productServ.Patch(context.Background(), 123, 3, func(p *domain.Product) error {
+			p.Name = "Renamed Product"
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "renamed-product", product.Slug)
+	})
+
+	t.Run("should_not_save_when_patch_fails", func(t *testing.T) {
+		mockProductRepo := new(repository.MockProductRepository)
+		productServ := NewProductService(mockProductRepo, nil, nil, new(repository.MockTransactor), nil)
+		patchErr := errors.New("invalid patch")
+
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(newExisting(), nil)
+
+		_, err := productServ.Patch(context.Background(), 123, 0, func(p *domain.Product) error {
+			return patchErr
+		})
+
+		assert.ErrorIs(t, err, patchErr)
+		mockProductRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should_reject_stale_version", func(t *testing.T) {
+		mockProductRepo := new(repository.MockProductRepository)
+		productServ := NewProductService(mockProductRepo, nil, nil, new(repository.MockTransactor), nil)
+
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(newExisting(), nil)
+
+		_, err := productServ.Patch(context.Background(), 123, 2, func(p *domain.Product) error {
+			return nil
+		})
+
+		assert.ErrorIs(t, err, domain.ErrVersionMismatch)
 	})
 }
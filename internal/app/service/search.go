@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+)
+
+type SearchService struct {
+	searchRepo port.SearchRepository
+	cache      port.SuggestionCache
+}
+
+// NewSearchService creates a search service. cache may be nil, in which
+// case every suggestion is read from the database.
+func NewSearchService(searchRepo port.SearchRepository, cache port.SuggestionCache) *SearchService {
+	return &SearchService{
+		searchRepo: searchRepo,
+		cache:      cache,
+	}
+}
+
+func (s *SearchService) Suggest(ctx context.Context, query domain.SuggestQuery) (*domain.Suggestions, error) {
+	if s.cache == nil {
+		return s.searchRepo.Suggest(ctx, query)
+	}
+
+	key := query.CacheKey()
+	if suggestions, ok := s.cache.Get(key); ok {
+		return suggestions, nil
+	}
+
+	suggestions, err := s.searchRepo.Suggest(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set(key, suggestions)
+
+	return suggestions, nil
+}
+
+// purgeSuggestions drops cached suggestions after a write to anything they
+// name: products, brands or categories. cache may be nil.
+func purgeSuggestions(cache port.SuggestionCache) {
+	if cache != nil {
+		cache.Purge()
+	}
+}
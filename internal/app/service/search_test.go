@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/cache"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestSuggest(t *testing.T) {
+	t.Run("should_serve_repeated_queries_from_cache", func(t *testing.T) {
+		mockSearchRepo := new(repository.MockSearchRepository)
+		suggestionCache := cache.NewSuggestionCache(time.Minute, 100)
+		searchServ := NewSearchService(mockSearchRepo, suggestionCache)
+
+		suggestions := &domain.Suggestions{
+			Products: []domain.ProductSuggestion{{Id: 1, Name: "iPhone 15", Slug: "iphone-15"}},
+		}
+
+		mockSearchRepo.On("Suggest", mock.Anything, domain.SuggestQuery{Query: "iph", Limit: 5}).Return(suggestions, nil).Once()
+
+		ctx := context.Background()
+		first, err := searchServ.Suggest(ctx, domain.SuggestQuery{Query: "iph", Limit: 5})
+		assert.NoError(t, err)
+		second, err := searchServ.Suggest(ctx, domain.SuggestQuery{Query: "IPH", Limit: 5})
+		assert.NoError(t, err)
+
+		assert.Equal(t, suggestions, first)
+		assert.Equal(t, suggestions, second)
+
+		mockSearchRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_refresh_after_product_write", func(t *testing.T) {
+		mockSearchRepo := new(repository.MockSearchRepository)
+		mockProductRepo := new(repository.MockProductRepository)
+		suggestionCache := cache.NewSuggestionCache(time.Minute, 100)
+		searchServ := NewSearchService(mockSearchRepo, suggestionCache)
+		productServ := NewProductService(mockProductRepo, nil, nil, new(repository.MockTransactor), suggestionCache)
+
+		query := domain.SuggestQuery{Query: "iph", Limit: 5}
+
+		mockSearchRepo.On("Suggest", mock.Anything, query).Return(&domain.Suggestions{}, nil).Twice()
+		mockProductRepo.On("Delete", mock.Anything, int64(1), int64(0)).Return(nil)
+
+		ctx := context.Background()
+		_, err := searchServ.Suggest(ctx, query)
+		assert.NoError(t, err)
+
+		assert.NoError(t, productServ.Delete(ctx, 1, 0))
+
+		_, err = searchServ.Suggest(ctx, query)
+		assert.NoError(t, err)
+
+		mockSearchRepo.AssertExpectations(t)
+	})
+	t.Run("should_refresh_after_brand_rename", func(t *testing.T) {
+		mockSearchRepo := new(repository.MockSearchRepository)
+		mockBrandRepo := new(repository.MockBrandRepository)
+		suggestionCache := cache.NewSuggestionCache(time.Minute, 100)
+		searchServ := NewSearchService(mockSearchRepo, suggestionCache)
+		brandServ := NewBrandService(mockBrandRepo, nil, suggestionCache)
+
+		query := domain.SuggestQuery{Query: "app", Limit: 5}
+		existing := &domain.Brand{Id: 1, Name: "Apple", Slug: "apple"}
+
+		mockSearchRepo.On("Suggest", mock.Anything, query).Return(&domain.Suggestions{}, nil).Twice()
+		mockBrandRepo.On("GetBySlug", mock.Anything, "apple").Return(existing, nil)
+		mockBrandRepo.On("SlugExists", mock.Anything, "apple-inc").Return(false, nil)
+		mockBrandRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+		ctx := context.Background()
+		_, err := searchServ.Suggest(ctx, query)
+		assert.NoError(t, err)
+
+		assert.NoError(t, brandServ.Update(ctx, "apple", &domain.Brand{Name: "Apple Inc"}))
+
+		_, err = searchServ.Suggest(ctx, query)
+		assert.NoError(t, err)
+
+		mockSearchRepo.AssertExpectations(t)
+	})
+}
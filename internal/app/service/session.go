@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"time"
+)
+
+type SessionService struct {
+	sessionRepo     port.SessionRepository
+	userRepo        port.UserRepository
+	tokens          port.TokenIssuer
+	refreshTokenTTL time.Duration
+}
+
+func NewSessionService(sessionRepo port.SessionRepository, userRepo port.UserRepository, tokens port.TokenIssuer, refreshTokenTTL time.Duration) *SessionService {
+	return &SessionService{
+		sessionRepo:     sessionRepo,
+		userRepo:        userRepo,
+		tokens:          tokens,
+		refreshTokenTTL: refreshTokenTTL,
+	}
+}
+
+// Refresh exchanges a refresh token for a new pair. Each refresh token
+// works once; replaying one revokes its session, since either the client
+// or whoever stole the token is now holding a dead one.
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string, client domain.SessionClient) (*domain.AuthTokens, error) {
+	used, err := s.sessionRepo.GetRefreshToken(ctx, domain.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.GetById(ctx, used.SessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !session.IsActive(now) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if used.UsedAt != nil {
+		return nil, s.revokeReused(ctx, session)
+	}
+
+	if !now.Before(used.ExpiresAt) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	plain, next, err := domain.NewRefreshToken(session.Id, s.refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.sessionRepo.Rotate(ctx, used, next, client); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			// Another request spent the token between our read and write.
+			return nil, s.revokeReused(ctx, session)
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetById(ctx, session.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return issueAuthTokens(s.tokens, user, session, plain, next)
+}
+
+// Authenticate resolves an access token to its principal. Tokens are
+// signed, but they outlive a sign-out, a revoked session or a password
+// reset unless their session is checked too, so it is.
+func (s *SessionService) Authenticate(ctx context.Context, accessToken string) (*domain.Principal, error) {
+	principal, err := s.tokens.Verify(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.GetById(ctx, principal.SessionId)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if session.UserId != principal.UserId || !session.IsActive(time.Now()) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return principal, nil
+}
+
+// List returns the user's live sessions, flagging the one the request
+// itself belongs to.
+func (s *SessionService) List(ctx context.Context, principal *domain.Principal) ([]domain.Session, error) {
+	sessions, err := s.sessionRepo.ListActive(ctx, principal.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].Id == principal.SessionId
+	}
+
+	return sessions, nil
+}
+
+func (s *SessionService) Revoke(ctx context.Context, userId, sessionId int64) error {
+	return s.sessionRepo.Revoke(ctx, userId, sessionId)
+}
+
+func (s *SessionService) RevokeAll(ctx context.Context, userId int64) (int64, error) {
+	return s.sessionRepo.RevokeAll(ctx, userId)
+}
+
+func (s *SessionService) revokeReused(ctx context.Context, session *domain.Session) error {
+	if err := s.sessionRepo.Revoke(ctx, session.UserId, session.Id); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	return domain.ErrRefreshTokenReused
+}
+
+// startSession signs user in on a new device.
+func startSession(ctx context.Context, sessionRepo port.SessionRepository, tokens port.TokenIssuer, user *domain.User, client domain.SessionClient, twoFactor bool, ttl time.Duration) (*domain.AuthTokens, error) {
+	session := domain.NewSession(user.Id, client, twoFactor)
+
+	plain, refreshToken, err := domain.NewRefreshToken(0, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	session.ExpiresAt = refreshToken.ExpiresAt
+
+	if err = sessionRepo.Create(ctx, session, refreshToken); err != nil {
+		return nil, err
+	}
+
+	return issueAuthTokens(tokens, user, session, plain, refreshToken)
+}
+
+func issueAuthTokens(tokens port.TokenIssuer, user *domain.User, session *domain.Session, plain string, refreshToken *domain.RefreshToken) (*domain.AuthTokens, error) {
+	accessToken, err := tokens.Issue(user, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AuthTokens{
+		AccessToken:           *accessToken,
+		RefreshToken:          plain,
+		RefreshTokenExpiresAt: refreshToken.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestRefreshSession(t *testing.T) {
+	session := &domain.Session{Id: 7, UserId: 42, ExpiresAt: time.Now().Add(time.Hour)}
+	user := &domain.User{Id: 42, Role: domain.RoleCustomer}
+	client := domain.SessionClient{UserAgent: "curl/8.0", Ip: "203.0.113.7"}
+
+	t.Run("should_rotate_refresh_token", func(t *testing.T) {
+		mockSessionRepo := new(repository.MockSessionRepository)
+		mockUserRepo := new(repository.MockUserRepository)
+		sessionServ := NewSessionService(mockSessionRepo, mockUserRepo, newTestTokenIssuer(), time.Hour)
+
+		used := &domain.RefreshToken{Id: 1, SessionId: 7, ExpiresAt: time.Now().Add(time.Hour)}
+		mockSessionRepo.On("GetRefreshToken", mock.Anything, domain.HashToken("abc")).Return(used, nil)
+		mockSessionRepo.On("GetById", mock.Anything, int64(7)).Return(session, nil)
+		mockSessionRepo.On("Rotate", mock.Anything, used, mock.MatchedBy(func(next *domain.RefreshToken) bool {
+			return next.SessionId == 7 && next.TokenHash != used.TokenHash
+		}), client).Return(nil)
+		mockUserRepo.On("GetById", mock.Anything, int64(42)).Return(user, nil)
+
+		tokens, err := sessionServ.Refresh(context.Background(), "abc", client)
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, "abc", tokens.RefreshToken)
+		assert.NotEmpty(t, tokens.Token)
+		mockSessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_revoke_session_on_reuse", func(t *testing.T) {
+		mockSessionRepo := new(repository.MockSessionRepository)
+		sessionServ := NewSessionService(mockSessionRepo, nil, newTestTokenIssuer(), time.Hour)
+
+		usedAt := time.Now().Add(-time.Minute)
+		used := &domain.RefreshToken{Id: 1, SessionId: 7, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}
+		mockSessionRepo.On("GetRefreshToken", mock.Anything, domain.HashToken("abc")).Return(used, nil)
+		mockSessionRepo.On("GetById", mock.Anything, int64(7)).Return(session, nil)
+		mockSessionRepo.On("Revoke", mock.Anything, int64(42), int64(7)).Return(nil)
+
+		_, err := sessionServ.Refresh(context.Background(), "abc", client)
+
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+		mockSessionRepo.AssertExpectations(t)
+		mockSessionRepo.AssertNotCalled(t, "Rotate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should_revoke_session_when_rotation_races", func(t *testing.T) {
+		mockSessionRepo := new(repository.MockSessionRepository)
+		sessionServ := NewSessionService(mockSessionRepo, nil, newTestTokenIssuer(), time.Hour)
+
+		used := &domain.RefreshToken{Id: 1, SessionId: 7, ExpiresAt: time.Now().Add(time.Hour)}
+		mockSessionRepo.On("GetRefreshToken", mock.Anything, domain.HashToken("abc")).Return(used, nil)
+		mockSessionRepo.On("GetById", mock.Anything, int64(7)).Return(session, nil)
+		mockSessionRepo.On("Rotate", mock.Anything, used, mock.Anything, client).Return(domain.ErrConflict)
+		mockSessionRepo.On("Revoke", mock.Anything, int64(42), int64(7)).Return(nil)
+
+		_, err := sessionServ.Refresh(context.Background(), "abc", client)
+
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+		mockSessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_reject_token_of_revoked_session", func(t *testing.T) {
+		mockSessionRepo := new(repository.MockSessionRepository)
+		sessionServ := NewSessionService(mockSessionRepo, nil, newTestTokenIssuer(), time.Hour)
+
+		revokedAt := time.Now()
+		revoked := *session
+		revoked.RevokedAt = &revokedAt
+		used := &domain.RefreshToken{Id: 1, SessionId: 7, ExpiresAt: time.Now().Add(time.Hour)}
+		mockSessionRepo.On("GetRefreshToken", mock.Anything, domain.HashToken("abc")).Return(used, nil)
+		mockSessionRepo.On("GetById", mock.Anything, int64(7)).Return(&revoked, nil)
+
+		_, err := sessionServ.Refresh(context.Background(), "abc", client)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+}
+
+func TestAuthenticateSession(t *testing.T) {
+	user := &domain.User{Id: 42, Role: domain.RoleCustomer}
+	issuer := newTestTokenIssuer()
+
+	t.Run("should_accept_token_of_live_session", func(t *testing.T) {
+		mockSessionRepo := new(repository.MockSessionRepository)
+		sessionServ := NewSessionService(mockSessionRepo, nil, issuer, time.Hour)
+
+		session := &domain.Session{Id: 7, UserId: 42, ExpiresAt: time.Now().Add(time.Hour)}
+		token, err := issuer.Issue(user, session)
+		assert.NoError(t, err)
+		mockSessionRepo.On("GetById", mock.Anything, int64(7)).Return(session, nil)
+
+		principal, err := sessionServ.Authenticate(context.Background(), token.Token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), principal.UserId)
+	})
+
+	t.Run("should_reject_token_of_revoked_session", func(t *testing.T) {
+		mockSessionRepo := new(repository.MockSessionRepository)
+		sessionServ := NewSessionService(mockSessionRepo, nil, issuer, time.Hour)
+
+		revokedAt := time.Now()
+		session := &domain.Session{Id: 7, UserId: 42, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+		token, err := issuer.Issue(user, session)
+		assert.NoError(t, err)
+		mockSessionRepo.On("GetById", mock.Anything, int64(7)).Return(session, nil)
+
+		_, err = sessionServ.Authenticate(context.Background(), token.Token)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+}
+
+func TestListSessions(t *testing.T) {
+	t.Run("should_flag_current_session", func(t *testing.T) {
+		mockSessionRepo := new(repository.MockSessionRepository)
+		sessionServ := NewSessionService(mockSessionRepo, nil, nil, time.Hour)
+
+		mockSessionRepo.On("ListActive", mock.Anything, int64(42)).Return([]domain.Session{{Id: 7}, {Id: 8}}, nil)
+
+		sessions, err := sessionServ.List(context.Background(), &domain.Principal{UserId: 42, SessionId: 8})
+
+		assert.NoError(t, err)
+		assert.False(t, sessions[0].Current)
+		assert.True(t, sessions[1].Current)
+	})
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"strings"
+	"time"
+)
+
+type TwoFactorService struct {
+	twoFactorRepo port.TwoFactorRepository
+	userRepo      port.UserRepository
+	issuer        string
+	policy        domain.TwoFactorPolicy
+}
+
+func NewTwoFactorService(twoFactorRepo port.TwoFactorRepository, userRepo port.UserRepository, issuer string, policy domain.TwoFactorPolicy) *TwoFactorService {
+	return &TwoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		issuer:        issuer,
+		policy:        policy,
+	}
+}
+
+// Enrol generates a new secret for the user's authenticator app. It has no
+// effect on logging in until confirmed.
+func (s *TwoFactorService) Enrol(ctx context.Context, userId int64) (*domain.TOTPProvisioning, error) {
+	user, err := s.userRepo.GetById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	enrolment, provisioning, err := domain.NewTOTPEnrolment(user.Id, s.issuer, user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.twoFactorRepo.SaveTOTP(ctx, enrolment); err != nil {
+		return nil, err
+	}
+
+	return provisioning, nil
+}
+
+// Confirm checks the first code from the app, proving it was set up
+// correctly, and turns two-factor authentication on.
+func (s *TwoFactorService) Confirm(ctx context.Context, userId int64, code string) ([]string, error) {
+	enrolment, err := s.twoFactorRepo.GetTOTP(ctx, userId)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrTwoFactorNotEnrolled
+		}
+		return nil, err
+	}
+
+	if enrolment.IsActive() {
+		return nil, domain.ErrTwoFactorAlreadyActive
+	}
+
+	step, ok := enrolment.Match(strings.TrimSpace(code), time.Now())
+	if !ok {
+		return nil, domain.ErrInvalidTwoFactorCode
+	}
+
+	plain, codes, err := domain.NewRecoveryCodes(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.twoFactorRepo.ConfirmTOTP(ctx, userId, step, codes); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, domain.ErrTwoFactorAlreadyActive
+		}
+		return nil, err
+	}
+
+	return plain, nil
+}
+
+// Disable turns two-factor authentication off, unless the user's role is
+// required to have it.
+func (s *TwoFactorService) Disable(ctx context.Context, userId int64, code string) error {
+	user, err := s.userRepo.GetById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if s.policy.Requires(user.Role) {
+		return domain.ErrTwoFactorRequired
+	}
+
+	if err = verifySecondFactor(ctx, s.twoFactorRepo, userId, code); err != nil {
+		return err
+	}
+
+	return s.twoFactorRepo.DeleteTOTP(ctx, userId)
+}
+
+// RegenerateRecoveryCodes replaces all the user's recovery codes, used or
+// not, with a fresh set.
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userId int64, code string) ([]string, error) {
+	if err := verifySecondFactor(ctx, s.twoFactorRepo, userId, code); err != nil {
+		return nil, err
+	}
+
+	plain, codes, err := domain.NewRecoveryCodes(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userId, codes); err != nil {
+		return nil, err
+	}
+
+	return plain, nil
+}
+
+// verifySecondFactor accepts either a current code from the user's app or
+// one of their unused recovery codes, spending whichever it was.
+func verifySecondFactor(ctx context.Context, twoFactorRepo port.TwoFactorRepository, userId int64, code string) error {
+	enrolment, err := twoFactorRepo.GetTOTP(ctx, userId)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrTwoFactorNotEnrolled
+		}
+		return err
+	}
+
+	if !enrolment.IsActive() {
+		return domain.ErrTwoFactorNotEnrolled
+	}
+
+	if step, ok := enrolment.Match(strings.TrimSpace(code), time.Now()); ok {
+		if err = twoFactorRepo.UseTOTPStep(ctx, userId, step); err != nil {
+			if errors.Is(err, domain.ErrConflict) {
+				return domain.ErrInvalidTwoFactorCode
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err = twoFactorRepo.UseRecoveryCode(ctx, userId, domain.HashRecoveryCode(code)); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrInvalidTwoFactorCode
+		}
+		return err
+	}
+
+	return nil
+}
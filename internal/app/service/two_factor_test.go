@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestTwoFactorEnrol(t *testing.T) {
+	t.Run("should_save_unconfirmed_enrolment", func(t *testing.T) {
+		mockTwoFactorRepo := new(repository.MockTwoFactorRepository)
+		mockUserRepo := new(repository.MockUserRepository)
+		twoFactorServ := NewTwoFactorService(mockTwoFactorRepo, mockUserRepo, "Ecom", domain.TwoFactorPolicy{})
+
+		mockUserRepo.On("GetById", mock.Anything, int64(42)).Return(&domain.User{Id: 42, Email: "jane@example.com"}, nil)
+		mockTwoFactorRepo.On("SaveTOTP", mock.Anything, mock.MatchedBy(func(e *domain.TOTPEnrolment) bool {
+			return e.UserId == 42 && e.Secret != "" && !e.IsActive()
+		})).Return(nil)
+
+		provisioning, err := twoFactorServ.Enrol(context.Background(), 42)
+
+		assert.NoError(t, err)
+		assert.Contains(t, provisioning.Uri, "otpauth://totp/Ecom:jane@example.com")
+		mockTwoFactorRepo.AssertExpectations(t)
+	})
+}
+
+func TestTwoFactorConfirm(t *testing.T) {
+	t.Run("should_reject_wrong_code", func(t *testing.T) {
+		mockTwoFactorRepo := new(repository.MockTwoFactorRepository)
+		twoFactorServ := NewTwoFactorService(mockTwoFactorRepo, nil, "Ecom", domain.TwoFactorPolicy{})
+
+		mockTwoFactorRepo.On("GetTOTP", mock.Anything, int64(42)).Return(&domain.TOTPEnrolment{UserId: 42, Secret: "JBSWY3DPEHPK3PXP"}, nil)
+
+		_, err := twoFactorServ.Confirm(context.Background(), 42, "12345")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidTwoFactorCode)
+		mockTwoFactorRepo.AssertNotCalled(t, "ConfirmTOTP", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should_reject_when_not_enrolling", func(t *testing.T) {
+		mockTwoFactorRepo := new(repository.MockTwoFactorRepository)
+		twoFactorServ := NewTwoFactorService(mockTwoFactorRepo, nil, "Ecom", domain.TwoFactorPolicy{})
+
+		mockTwoFactorRepo.On("GetTOTP", mock.Anything, int64(42)).Return(nil, domain.ErrNotFound)
+
+		_, err := twoFactorServ.Confirm(context.Background(), 42, "123456")
+
+		assert.ErrorIs(t, err, domain.ErrTwoFactorNotEnrolled)
+	})
+}
+
+func TestTwoFactorDisable(t *testing.T) {
+	policy, err := domain.ParseTwoFactorPolicy("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmedAt := time.Now()
+
+	t.Run("should_refuse_when_role_requires_two_factor", func(t *testing.T) {
+		mockUserRepo := new(repository.MockUserRepository)
+		twoFactorServ := NewTwoFactorService(nil, mockUserRepo, "Ecom", policy)
+
+		mockUserRepo.On("GetById", mock.Anything, int64(42)).Return(&domain.User{Id: 42, Role: domain.RoleAdmin}, nil)
+
+		err := twoFactorServ.Disable(context.Background(), 42, "123456")
+
+		assert.ErrorIs(t, err, domain.ErrTwoFactorRequired)
+	})
+
+	t.Run("should_delete_enrolment_after_recovery_code", func(t *testing.T) {
+		mockTwoFactorRepo := new(repository.MockTwoFactorRepository)
+		mockUserRepo := new(repository.MockUserRepository)
+		twoFactorServ := NewTwoFactorService(mockTwoFactorRepo, mockUserRepo, "Ecom", policy)
+
+		mockUserRepo.On("GetById", mock.Anything, int64(42)).Return(&domain.User{Id: 42, Role: domain.RoleCustomer}, nil)
+		mockTwoFactorRepo.On("GetTOTP", mock.Anything, int64(42)).Return(&domain.TOTPEnrolment{UserId: 42, Secret: "JBSWY3DPEHPK3PXP", ConfirmedAt: &confirmedAt}, nil)
+		mockTwoFactorRepo.On("UseRecoveryCode", mock.Anything, int64(42), domain.HashRecoveryCode("abcd-ef01-2345-6789")).Return(nil)
+		mockTwoFactorRepo.On("DeleteTOTP", mock.Anything, int64(42)).Return(nil)
+
+		err := twoFactorServ.Disable(context.Background(), 42, "abcd-ef01-2345-6789")
+
+		assert.NoError(t, err)
+		mockTwoFactorRepo.AssertExpectations(t)
+	})
+}
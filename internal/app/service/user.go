@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"strings"
+	"time"
+)
+
+// dummyUser is checked against when no account matches a login, so
+// unknown emails take as long to reject as wrong passwords.
+var dummyUser, _ = domain.NewUser("", "", "dummy password")
+
+type UserService struct {
+	userRepo        port.UserRepository
+	sessionRepo     port.SessionRepository
+	userTokenRepo   port.UserTokenRepository
+	twoFactorRepo   port.TwoFactorRepository
+	transactor      port.Transactor
+	tokens          port.TokenIssuer
+	refreshTokenTTL time.Duration
+	mail            domain.AccountMail
+}
+
+func NewUserService(userRepo port.UserRepository, sessionRepo port.SessionRepository, userTokenRepo port.UserTokenRepository, twoFactorRepo port.TwoFactorRepository, transactor port.Transactor, tokens port.TokenIssuer, refreshTokenTTL time.Duration, mail domain.AccountMail) *UserService {
+	return &UserService{
+		userRepo:        userRepo,
+		sessionRepo:     sessionRepo,
+		userTokenRepo:   userTokenRepo,
+		twoFactorRepo:   twoFactorRepo,
+		transactor:      transactor,
+		tokens:          tokens,
+		refreshTokenTTL: refreshTokenTTL,
+		mail:            mail,
+	}
+}
+
+// Register creates the account and mails a link to verify its address.
+// Both happen or neither does, so no account is left without a link.
+func (s *UserService) Register(ctx context.Context, email, name, password string) (*domain.User, error) {
+	user, err := domain.NewUser(email, name, password)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		return sendEmailVerification(ctx, s.userTokenRepo, s.mail, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Login checks credentials and starts a session for client, or, if the
+// user has two-factor authentication, issues the challenge that
+// CompleteLogin takes along with their code.
+func (s *UserService) Login(ctx context.Context, email, password string, client domain.SessionClient) (*domain.User, *domain.LoginResult, error) {
+	user, err := s.userRepo.GetByEmail(ctx, domain.NormalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			dummyUser.CheckPassword(password)
+			return nil, nil, domain.ErrInvalidCredentials
+		}
+		return nil, nil, err
+	}
+
+	if !user.CheckPassword(password) {
+		return nil, nil, domain.ErrInvalidCredentials
+	}
+
+	enrolment, err := s.twoFactorRepo.GetTOTP(ctx, user.Id)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, nil, err
+	}
+
+	if enrolment != nil && enrolment.IsActive() {
+		challenge, token, err := domain.NewTwoFactorChallenge(user.Id)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err = s.userTokenRepo.Create(ctx, token, nil); err != nil {
+			return nil, nil, err
+		}
+
+		return user, &domain.LoginResult{Challenge: challenge}, nil
+	}
+
+	tokens, err := startSession(ctx, s.sessionRepo, s.tokens, user, client, false, s.refreshTokenTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, &domain.LoginResult{Tokens: tokens}, nil
+}
+
+// CompleteLogin finishes a login held up by a two-factor challenge. Each
+// challenge allows a single attempt; after a wrong code the user starts
+// again from their password, which keeps codes as slow to guess as it.
+func (s *UserService) CompleteLogin(ctx context.Context, challenge, code string, client domain.SessionClient) (*domain.User, *domain.AuthTokens, error) {
+	token, err := s.userTokenRepo.Consume(ctx, domain.UserTokenTwoFactor, domain.HashToken(challenge))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil, domain.ErrInvalidToken
+		}
+		return nil, nil, err
+	}
+
+	if err = verifySecondFactor(ctx, s.twoFactorRepo, token.UserId, code); err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.GetById(ctx, token.UserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := startSession(ctx, s.sessionRepo, s.tokens, user, client, true, s.refreshTokenTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, tokens, nil
+}
+
+func (s *UserService) GetById(ctx context.Context, id int64) (*domain.User, error) {
+	return s.userRepo.GetById(ctx, id)
+}
+
+// UpdateProfile changes the user's details. A new email address has to be
+// verified again, and is only saved once its link is queued.
+func (s *UserService) UpdateProfile(ctx context.Context, id int64, email, name string) (*domain.User, error) {
+	var user *domain.User
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.userRepo.GetById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		emailChanged := user.ChangeEmail(email)
+		user.Name = strings.TrimSpace(name)
+
+		if err = s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+
+		if emailChanged {
+			return sendEmailVerification(ctx, s.userTokenRepo, s.mail, user)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/auth"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+var testAccountMail = domain.AccountMail{
+	AppUrl:               "https://shop.example.com",
+	PasswordResetTTL:     time.Hour,
+	EmailVerificationTTL: time.Hour,
+}
+
+func newTestTokenIssuer() *auth.JWTIssuer {
+	return auth.NewHS256Issuer([]byte("secret"), "ecom-api", time.Minute)
+}
+
+func TestRegisterUser(t *testing.T) {
+	t.Run("should_create_user", func(t *testing.T) {
+		mockUserRepo := new(repository.MockUserRepository)
+		mockUserTokenRepo := new(repository.MockUserTokenRepository)
+		userServ := NewUserService(mockUserRepo, nil, mockUserTokenRepo, nil, new(repository.MockTransactor), newTestTokenIssuer(), time.Hour, testAccountMail)
+
+		mockUserRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+			return u.Email == "jane@example.com" && u.CheckPassword("correct horse")
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.User).Id = 42
+		}).Return(nil)
+		mockUserTokenRepo.On("Create", mock.Anything, mock.MatchedBy(func(token *domain.UserToken) bool {
+			return token.UserId == 42 && token.Purpose == domain.UserTokenEmailVerification
+		}), mock.MatchedBy(func(email *domain.Email) bool {
+			return email.To == "jane@example.com"
+		})).Return(nil)
+
+		user, err := userServ.Register(context.Background(), "Jane@example.com", "Jane", "correct horse")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "jane@example.com", user.Email)
+		mockUserRepo.AssertExpectations(t)
+		mockUserTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_reject_taken_email", func(t *testing.T) {
+		mockUserRepo := new(repository.MockUserRepository)
+		mockUserTokenRepo := new(repository.MockUserTokenRepository)
+		userServ := NewUserService(mockUserRepo, nil, mockUserTokenRepo, nil, new(repository.MockTransactor), newTestTokenIssuer(), time.Hour, testAccountMail)
+
+		mockUserRepo.On("Create", mock.Anything, mock.Anything).Return(fmt.Errorf("%w: email taken", domain.ErrConflict))
+
+		_, err := userServ.Register(context.Background(), "jane@example.com", "Jane", "correct horse")
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+}
+
+func TestLogin(t *testing.T) {
+	registered, err := domain.NewUser("jane@example.com", "Jane", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered.Id = 42
+
+	t.Run("should_issue_token_for_valid_credentials", func(t *testing.T) {
+		mockUserRepo := new(repository.MockUserRepository)
+		mockSessionRepo := new(repository.MockSessionRepository)
+		mockTwoFactorRepo := new(repository.MockTwoFactorRepository)
+		tokens := newTestTokenIssuer()
+		userServ := NewUserService(mockUserRepo, mockSessionRepo, nil, mockTwoFactorRepo, new(repository.MockTransactor), tokens, time.Hour, testAccountMail)
+
+		client := domain.SessionClient{UserAgent: "curl/8.0", Ip: "203.0.113.7"}
+		mockUserRepo.On("GetByEmail", mock.Anything, "jane@example.com").Return(registered, nil)
+		mockTwoFactorRepo.On("GetTOTP", mock.Anything, int64(42)).Return(nil, domain.ErrNotFound)
+		mockSessionRepo.On("Create", mock.Anything, mock.MatchedBy(func(s *domain.Session) bool {
+			return s.UserId == 42 && s.Ip == "203.0.113.7"
+		}), mock.AnythingOfType("*domain.RefreshToken")).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.Session).Id = 7
+		}).Return(nil)
+
+		user, result, err := userServ.Login(context.Background(), " JANE@example.com", "correct horse", client)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), user.Id)
+		assert.Nil(t, result.Challenge)
+		assert.NotEmpty(t, result.Tokens.RefreshToken)
+		principal, err := tokens.Verify(result.Tokens.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), principal.UserId)
+		assert.Equal(t, int64(7), principal.SessionId)
+		assert.False(t, principal.TwoFactor)
+	})
+
+	t.Run("should_issue_challenge_when_two_factor_is_active", func(t *testing.T) {
+		mockUserRepo := new(repository.MockUserRepository)
+		mockUserTokenRepo := new(repository.MockUserTokenRepository)
+		mockTwoFactorRepo := new(repository.MockTwoFactorRepository)
+		userServ := NewUserService(mockUserRepo, nil, mockUserTokenRepo, mockTwoFactorRepo, new(repository.MockTransactor), newTestTokenIssuer(), time.Hour, testAccountMail)
+
+		confirmedAt := time.Now()
+		mockUserRepo.On("GetByEmail", mock.Anything, "jane@example.com").Return(registered, nil)
+		mockTwoFactorRepo.On("GetTOTP", mock.Anything, int64(42)).Return(&domain.TOTPEnrolment{UserId: 42, ConfirmedAt: &confirmedAt}, nil)
+		mockUserTokenRepo.On("Create", mock.Anything, mock.MatchedBy(func(token *domain.UserToken) bool {
+			return token.UserId == 42 && token.Purpose == domain.UserTokenTwoFactor
+		}), (*domain.Email)(nil)).Return(nil)
+
+		_, result, err := userServ.Login(context.Background(), "jane@example.com", "correct horse", domain.SessionClient{})
+
+		assert.NoError(t, err)
+		assert.Nil(t, result.Tokens)
+		assert.NotEmpty(t, result.Challenge.Token)
+		mockUserTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_reject_wrong_password", func(t *testing.T) {
+		mockUserRepo := new(repository.MockUserRepository)
+		userServ := NewUserService(mockUserRepo, nil, nil, nil, new(repository.MockTransactor), newTestTokenIssuer(), time.Hour, testAccountMail)
+
+		mockUserRepo.On("GetByEmail", mock.Anything, "jane@example.com").Return(registered, nil)
+
+		_, _, err := userServ.Login(context.Background(), "jane@example.com", "battery staple", domain.SessionClient{})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	})
+
+	t.Run("should_reject_unknown_email", func(t *testing.T) {
+		mockUserRepo := new(repository.MockUserRepository)
+		userServ := NewUserService(mockUserRepo, nil, nil, nil, new(repository.MockTransactor), newTestTokenIssuer(), time.Hour, testAccountMail)
+
+		mockUserRepo.On("GetByEmail", mock.Anything, "john@example.com").Return(nil, domain.ErrNotFound)
+
+		_, _, err := userServ.Login(context.Background(), "john@example.com", "correct horse", domain.SessionClient{})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	})
+}
+
+func TestCompleteLogin(t *testing.T) {
+	registered := &domain.User{Id: 42, Email: "jane@example.com", Role: domain.RoleCatalogManager}
+	confirmedAt := time.Now()
+	enrolment := &domain.TOTPEnrolment{UserId: 42, Secret: "JBSWY3DPEHPK3PXP", ConfirmedAt: &confirmedAt}
+
+	t.Run("should_start_two_factor_session_for_recovery_code", func(t *testing.T) {
+		mockUserRepo := new(repository.MockUserRepository)
+		mockSessionRepo := new(repository.MockSessionRepository)
+		mockUserTokenRepo := new(repository.MockUserTokenRepository)
+		mockTwoFactorRepo := new(repository.MockTwoFactorRepository)
+		tokens := newTestTokenIssuer()
+		userServ := NewUserService(mockUserRepo, mockSessionRepo, mockUserTokenRepo, mockTwoFactorRepo, new(repository.MockTransactor), tokens, time.Hour, testAccountMail)
+
+		mockUserTokenRepo.On("Consume", mock.Anything, domain.UserTokenTwoFactor, domain.HashToken("challenge")).Return(&domain.UserToken{UserId: 42}, nil)
+		mockTwoFactorRepo.On("GetTOTP", mock.Anything, int64(42)).Return(enrolment, nil)
+		mockTwoFactorRepo.On("UseRecoveryCode", mock.Anything, int64(42), domain.HashRecoveryCode("abcd-ef01-2345-6789")).Return(nil)
+		mockUserRepo.On("GetById", mock.Anything, int64(42)).Return(registered, nil)
+		mockSessionRepo.On("Create", mock.Anything, mock.MatchedBy(func(s *domain.Session) bool {
+			return s.UserId == 42 && s.TwoFactor
+		}), mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+		_, authTokens, err := userServ.CompleteLogin(context.Background(), "challenge", "ABCD-EF01-2345-6789", domain.SessionClient{})
+
+		assert.NoError(t, err)
+		principal, err := tokens.Verify(authTokens.Token)
+		assert.NoError(t, err)
+		assert.True(t, principal.TwoFactor)
+		mockSessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_reject_wrong_code", func(t *testing.T) {
+		mockUserTokenRepo := new(repository.MockUserTokenRepository)
+		mockTwoFactorRepo := new(repository.MockTwoFactorRepository)
+		userServ := NewUserService(nil, nil, mockUserTokenRepo, mockTwoFactorRepo, new(repository.MockTransactor), newTestTokenIssuer(), time.Hour, testAccountMail)
+
+		mockUserTokenRepo.On("Consume", mock.Anything, domain.UserTokenTwoFactor, domain.HashToken("challenge")).Return(&domain.UserToken{UserId: 42}, nil)
+		mockTwoFactorRepo.On("GetTOTP", mock.Anything, int64(42)).Return(enrolment, nil)
+		mockTwoFactorRepo.On("UseRecoveryCode", mock.Anything, int64(42), mock.Anything).Return(domain.ErrNotFound)
+
+		_, _, err := userServ.CompleteLogin(context.Background(), "challenge", "12345", domain.SessionClient{})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidTwoFactorCode)
+	})
+
+	t.Run("should_reject_spent_challenge", func(t *testing.T) {
+		mockUserTokenRepo := new(repository.MockUserTokenRepository)
+		userServ := NewUserService(nil, nil, mockUserTokenRepo, nil, new(repository.MockTransactor), newTestTokenIssuer(), time.Hour, testAccountMail)
+
+		mockUserTokenRepo.On("Consume", mock.Anything, domain.UserTokenTwoFactor, mock.Anything).Return(nil, domain.ErrNotFound)
+
+		_, _, err := userServ.CompleteLogin(context.Background(), "challenge", "123456", domain.SessionClient{})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+}
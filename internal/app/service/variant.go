@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+)
+
+type VariantService struct {
+	variantRepo port.VariantRepository
+	productRepo port.ProductRepository
+}
+
+func NewVariantService(variantRepo port.VariantRepository, productRepo port.ProductRepository) *VariantService {
+	return &VariantService{
+		variantRepo: variantRepo,
+		productRepo: productRepo,
+	}
+}
+
+func (s *VariantService) SetOptions(ctx context.Context, productId int64, options []domain.ProductOption) error {
+	if err := domain.ValidateOptions(options); err != nil {
+		return err
+	}
+
+	product, err := s.productRepo.GetById(ctx, productId)
+	if err != nil {
+		return err
+	}
+
+	for _, variant := range product.Variants {
+		if err = domain.ValidateVariantOptions(options, variant.Options); err != nil {
+			return fmt.Errorf("variant %s would become invalid: %w", variant.Sku, err)
+		}
+	}
+
+	return s.variantRepo.SetOptions(ctx, productId, options)
+}
+
+func (s *VariantService) Create(ctx context.Context, variant *domain.ProductVariant) error {
+	product, err := s.productRepo.GetById(ctx, variant.ProductId)
+	if err != nil {
+		return err
+	}
+
+	if err = s.validate(product, variant); err != nil {
+		return err
+	}
+
+	return s.variantRepo.Create(ctx, variant)
+}
+
+func (s *VariantService) Update(ctx context.Context, variant *domain.ProductVariant) error {
+	if _, err := s.variantRepo.GetById(ctx, variant.ProductId, variant.Id); err != nil {
+		return err
+	}
+
+	product, err := s.productRepo.GetById(ctx, variant.ProductId)
+	if err != nil {
+		return err
+	}
+
+	if err = s.validate(product, variant); err != nil {
+		return err
+	}
+
+	return s.variantRepo.Update(ctx, variant)
+}
+
+func (s *VariantService) Delete(ctx context.Context, productId, id int64) error {
+	return s.variantRepo.Delete(ctx, productId, id)
+}
+
+func (s *VariantService) validate(product *domain.Product, variant *domain.ProductVariant) error {
+	if err := domain.ValidateVariantOptions(product.Options, variant.Options); err != nil {
+		return err
+	}
+
+	if existing := product.FindVariantByOptions(variant.Options, variant.Id); existing != nil {
+		return fmt.Errorf("%w: variant %s already has these options", domain.ErrConflict, existing.Sku)
+	}
+
+	if variant.Price != nil && variant.Price.Currency != product.Price.Currency {
+		return fmt.Errorf("%w: product is priced in %s but variant in %s", domain.ErrCurrencyMismatch, product.Price.Currency, variant.Price.Currency)
+	}
+
+	return nil
+}
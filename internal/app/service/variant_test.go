@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func mockProductWithVariants() *domain.Product {
+	variantPrice := domain.NewMoney(2500, "USD")
+
+	return &domain.Product{
+		BaseProduct: domain.BaseProduct{
+			Id:    123,
+			Name:  "Mock T-Shirt",
+			Price: domain.NewMoney(2000, "USD"),
+		},
+		Options: []domain.ProductOption{
+			{Name: "size", Values: []string{"S", "M", "L"}},
+		},
+		Variants: []domain.ProductVariant{
+			{Id: 1, ProductId: 123, Sku: "TS-S", Stock: 3, Options: map[string]string{"size": "S"}},
+			{Id: 2, ProductId: 123, Sku: "TS-L", Stock: 4, Price: &variantPrice, Options: map[string]string{"size": "L"}},
+		},
+	}
+}
+
+func TestCreateVariant(t *testing.T) {
+	t.Run("should_create_variant", func(t *testing.T) {
+		mockVariantRepo := new(repository.MockVariantRepository)
+		mockProductRepo := new(repository.MockProductRepository)
+		variantServ := NewVariantService(mockVariantRepo, mockProductRepo)
+
+		variant := &domain.ProductVariant{
+			ProductId: 123,
+			Sku:       "TS-M",
+			Stock:     5,
+			Options:   map[string]string{"size": "M"},
+		}
+
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(mockProductWithVariants(), nil)
+		mockVariantRepo.On("Create", mock.Anything, variant).Return(nil)
+
+		ctx := context.Background()
+		err := variantServ.Create(ctx, variant)
+
+		assert.NoError(t, err)
+
+		mockProductRepo.AssertExpectations(t)
+		mockVariantRepo.AssertExpectations(t)
+	})
+
+	t.Run("should_reject_duplicate_option_combination", func(t *testing.T) {
+		mockVariantRepo := new(repository.MockVariantRepository)
+		mockProductRepo := new(repository.MockProductRepository)
+		variantServ := NewVariantService(mockVariantRepo, mockProductRepo)
+
+		variant := &domain.ProductVariant{
+			ProductId: 123,
+			Sku:       "TS-S-2",
+			Options:   map[string]string{"size": "S"},
+		}
+
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(mockProductWithVariants(), nil)
+
+		ctx := context.Background()
+		err := variantServ.Create(ctx, variant)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		mockVariantRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should_reject_unknown_option_value", func(t *testing.T) {
+		mockVariantRepo := new(repository.MockVariantRepository)
+		mockProductRepo := new(repository.MockProductRepository)
+		variantServ := NewVariantService(mockVariantRepo, mockProductRepo)
+
+		variant := &domain.ProductVariant{
+			ProductId: 123,
+			Sku:       "TS-XL",
+			Options:   map[string]string{"size": "XL"},
+		}
+
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(mockProductWithVariants(), nil)
+
+		ctx := context.Background()
+		err := variantServ.Create(ctx, variant)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidVariantOptions)
+		mockVariantRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSetOptions(t *testing.T) {
+	t.Run("should_reject_options_invalidating_existing_variants", func(t *testing.T) {
+		mockVariantRepo := new(repository.MockVariantRepository)
+		mockProductRepo := new(repository.MockProductRepository)
+		variantServ := NewVariantService(mockVariantRepo, mockProductRepo)
+
+		options := []domain.ProductOption{
+			{Name: "size", Values: []string{"M", "L"}},
+		}
+
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(mockProductWithVariants(), nil)
+
+		ctx := context.Background()
+		err := variantServ.SetOptions(ctx, 123, options)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidVariantOptions)
+		mockVariantRepo.AssertNotCalled(t, "SetOptions", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetProductWithVariants(t *testing.T) {
+	t.Run("should_derive_price_and_stock_from_variants", func(t *testing.T) {
+		mockProductRepo := new(repository.MockProductRepository)
+		mockCategoryRepo := new(repository.MockCategoryRepository)
+		productServ := NewProductService(mockProductRepo, mockCategoryRepo, nil, new(repository.MockTransactor), nil)
+
+		product := mockProductWithVariants()
+
+		mockProductRepo.On("GetById", mock.Anything, int64(123)).Return(product, nil)
+		mockCategoryRepo.On("GetById", mock.Anything, int64(0)).Return(&domain.Category{}, nil)
+
+		ctx := context.Background()
+		result, err := productServ.GetById(ctx, 123)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.NewMoney(2000, "USD"), result.Price)
+		assert.Equal(t, int64(7), result.Stock)
+	})
+}
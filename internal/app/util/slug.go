@@ -16,7 +16,7 @@ func GenerateUniqueSlug(ctx context.Context, name string, exists SlugExistsFunc)
 	for attempt := 0; attempt < 5; attempt++ {
 		exists, err := exists(ctx, candidate)
 		if err != nil {
-			return "", fmt.Errorf("failed to check if slug exists: %v", err)
+			return "", fmt.Errorf("failed to check if slug exists: %w", err)
 		}
 		if !exists {
 			return candidate, nil
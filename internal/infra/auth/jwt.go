@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"strconv"
+	"time"
+)
+
+type accessClaims struct {
+	Role      domain.Role `json:"role"`
+	SessionId int64       `json:"sid"`
+	TwoFactor bool        `json:"mfa,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// JWTIssuer issues access tokens as signed JWTs carrying the user id as
+// subject, the user's role, the session they belong to and whether that
+// session passed a second factor. Tokens are only accepted when signed with
+// the issuer's own algorithm, so an HS256 token can't pass for RS256.
+type JWTIssuer struct {
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+	issuer    string
+	ttl       time.Duration
+}
+
+// NewHS256Issuer signs and verifies with a shared secret.
+func NewHS256Issuer(secret []byte, issuer string, ttl time.Duration) *JWTIssuer {
+	return &JWTIssuer{
+		method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+		issuer:    issuer,
+		ttl:       ttl,
+	}
+}
+
+// NewRS256Issuer signs with the private key and verifies with the public
+// one, which other services can be given without being able to mint tokens.
+func NewRS256Issuer(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey, issuer string, ttl time.Duration) *JWTIssuer {
+	return &JWTIssuer{
+		method:    jwt.SigningMethodRS256,
+		signKey:   privateKey,
+		verifyKey: publicKey,
+		issuer:    issuer,
+		ttl:       ttl,
+	}
+}
+
+func (i *JWTIssuer) Issue(user *domain.User, session *domain.Session) (*domain.AccessToken, error) {
+	now := time.Now()
+	expiresAt := now.Add(i.ttl)
+
+	claims := accessClaims{
+		Role:      user.Role,
+		SessionId: session.Id,
+		TwoFactor: session.TwoFactor,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    i.issuer,
+			Subject:   strconv.FormatInt(user.Id, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(i.method, claims).SignedString(i.signKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AccessToken{
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (i *JWTIssuer) Verify(token string) (*domain.Principal, error) {
+	var claims accessClaims
+
+	_, err := jwt.ParseWithClaims(
+		token,
+		&claims,
+		func(*jwt.Token) (any, error) { return i.verifyKey, nil },
+		jwt.WithValidMethods([]string{i.method.Alg()}),
+		jwt.WithIssuer(i.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	userId, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil || userId <= 0 || !claims.Role.IsValid() {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return &domain.Principal{
+		UserId:    userId,
+		SessionId: claims.SessionId,
+		Role:      claims.Role,
+		TwoFactor: claims.TwoFactor,
+	}, nil
+}
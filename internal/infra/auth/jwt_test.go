@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestJWTIssuer(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &domain.User{Id: 42, Role: domain.RoleCatalogManager}
+	session := &domain.Session{Id: 7}
+
+	t.Run("should_verify_issued_hs256_token", func(t *testing.T) {
+		issuer := NewHS256Issuer([]byte("secret"), "ecom-api", time.Minute)
+
+		token, err := issuer.Issue(user, session)
+		assert.NoError(t, err)
+
+		principal, err := issuer.Verify(token.Token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.Principal{UserId: 42, SessionId: 7, Role: domain.RoleCatalogManager}, principal)
+	})
+
+	t.Run("should_verify_issued_rs256_token", func(t *testing.T) {
+		issuer := NewRS256Issuer(privateKey, &privateKey.PublicKey, "ecom-api", time.Minute)
+
+		token, err := issuer.Issue(user, session)
+		assert.NoError(t, err)
+
+		principal, err := issuer.Verify(token.Token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), principal.UserId)
+	})
+
+	t.Run("should_carry_two_factor_from_session", func(t *testing.T) {
+		issuer := NewHS256Issuer([]byte("secret"), "ecom-api", time.Minute)
+
+		token, err := issuer.Issue(user, &domain.Session{Id: 7, TwoFactor: true})
+		assert.NoError(t, err)
+
+		principal, err := issuer.Verify(token.Token)
+
+		assert.NoError(t, err)
+		assert.True(t, principal.TwoFactor)
+	})
+
+	t.Run("should_reject_token_signed_with_other_secret", func(t *testing.T) {
+		token, err := NewHS256Issuer([]byte("other"), "ecom-api", time.Minute).Issue(user, session)
+		assert.NoError(t, err)
+
+		_, err = NewHS256Issuer([]byte("secret"), "ecom-api", time.Minute).Verify(token.Token)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+
+	t.Run("should_reject_token_signed_with_other_algorithm", func(t *testing.T) {
+		token, err := NewHS256Issuer([]byte("secret"), "ecom-api", time.Minute).Issue(user, session)
+		assert.NoError(t, err)
+
+		_, err = NewRS256Issuer(privateKey, &privateKey.PublicKey, "ecom-api", time.Minute).Verify(token.Token)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+
+	t.Run("should_reject_expired_token", func(t *testing.T) {
+		issuer := NewHS256Issuer([]byte("secret"), "ecom-api", -time.Minute)
+
+		token, err := issuer.Issue(user, session)
+		assert.NoError(t, err)
+
+		_, err = issuer.Verify(token.Token)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+}
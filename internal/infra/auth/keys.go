@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+)
+
+// LoadRSAKeys reads a PEM private key and, if publicKeyFile is set, a PEM
+// public key. Without one the private key's public half is used.
+func LoadRSAKeys(privateKeyFile, publicKeyFile string) (*rsa.PrivateKey, *rsa.PublicKey, error) {
+	data, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %w", privateKeyFile, err)
+	}
+
+	if publicKeyFile == "" {
+		return privateKey, &privateKey.PublicKey, nil
+	}
+
+	data, err = os.ReadFile(publicKeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %w", publicKeyFile, err)
+	}
+
+	return privateKey, publicKey, nil
+}
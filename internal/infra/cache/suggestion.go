@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"sync"
+	"time"
+)
+
+type suggestionEntry struct {
+	suggestions *domain.Suggestions
+	expiresAt   time.Time
+}
+
+// SuggestionCache is an in-process, size-bounded cache of autocomplete
+// results whose entries expire after a fixed TTL.
+type SuggestionCache struct {
+	mu         sync.RWMutex
+	entries    map[string]suggestionEntry
+	ttl        time.Duration
+	maxEntries int
+}
+
+func NewSuggestionCache(ttl time.Duration, maxEntries int) *SuggestionCache {
+	return &SuggestionCache{
+		entries:    make(map[string]suggestionEntry),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+	}
+}
+
+func (c *SuggestionCache) Get(key string) (*domain.Suggestions, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+
+	return entry.suggestions, true
+}
+
+func (c *SuggestionCache) Set(key string, suggestions *domain.Suggestions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= c.maxEntries {
+		c.evictExpired()
+	}
+	// Still full of live entries: start over rather than track recency.
+	if len(c.entries) >= c.maxEntries {
+		c.entries = make(map[string]suggestionEntry)
+	}
+
+	c.entries[key] = suggestionEntry{
+		suggestions: suggestions,
+		expiresAt:   time.Now().Add(c.ttl),
+	}
+}
+
+func (c *SuggestionCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]suggestionEntry)
+}
+
+func (c *SuggestionCache) evictExpired() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -8,11 +8,22 @@ import (
 type Config struct {
 	Http     *Http
 	Database *Database
+	Money    *Money
+	Search   *Search
+	Storage  *Storage
+	Cart     *Cart
+	Payment  *Payment
+	Auth     *Auth
+	Mail     *Mail
+	Cache    *Cache
 	Env      string
 }
 
+// Http configures the server. With RequireIfMatch set, writes to
+// versioned resources must say which version they are based on.
 type Http struct {
-	Addr string
+	Addr           string
+	RequireIfMatch bool
 }
 
 type Database struct {
@@ -22,9 +33,89 @@ type Database struct {
 	MaxIdleTime  string
 }
 
+type Money struct {
+	Format string
+}
+
+type Search struct {
+	SuggestCacheEnabled bool
+	SuggestCacheTTL     string
+	SuggestCacheSize    int
+}
+
+type Storage struct {
+	LocalDir      string
+	PublicUrl     string
+	MaxUploadSize int
+}
+
+type Cart struct {
+	TTL           string
+	PurgeInterval string
+}
+
+// DefaultPaymentWebhookSecret is the public stand-in secret the fake
+// gateway signs webhooks with during development.
+const DefaultPaymentWebhookSecret = "fake-webhook-secret"
+
+// Payment configures the payment gateway. The fake provider, which approves
+// everything, and the default webhook secret are refused when Env is
+// "production".
+type Payment struct {
+	Provider      string
+	WebhookSecret string
+}
+
+// Auth configures access tokens. HS256 signs with TokenSecret; RS256 signs
+// with the PEM private key and verifies with the PEM public key, which
+// defaults to the private key's own. TwoFactorRoles lists the roles, comma
+// separated, that may only use their permissions after signing in with a
+// second factor; TOTPIssuer is the name authenticator apps show.
+type Auth struct {
+	TokenAlgorithm      string
+	TokenSecret         string
+	TokenPrivateKeyFile string
+	TokenPublicKeyFile  string
+	TokenIssuer         string
+	AccessTokenTTL      string
+	RefreshTokenTTL     string
+	PasswordResetTTL    string
+	VerificationTTL     string
+	TwoFactorRoles      string
+	TOTPIssuer          string
+}
+
+// Mail configures outgoing email. Transport is "smtp", or "file" which
+// writes messages to FileDir, or just logs them when FileDir is empty; the
+// file transport is refused when Env is "production".
+// AppUrl is the storefront that links in emails point at.
+type Mail struct {
+	Transport        string
+	From             string
+	AppUrl           string
+	FileDir          string
+	SMTPHost         string
+	SMTPPort         int
+	SMTPUsername     string
+	SMTPPassword     string
+	DispatchInterval string
+	DispatchBatch    int
+}
+
+// Cache sets the Cache-Control header sent with successful catalog reads,
+// per route: Product for a single product, Products for product lists, and
+// Brands and Categories for theirs. An empty value sends none.
+type Cache struct {
+	Product    string
+	Products   string
+	Brands     string
+	Categories string
+}
+
 func Load() *Config {
 	http := &Http{
-		Addr: getString("HTTP_ADDR", ":8080"),
+		Addr:           getString("HTTP_ADDR", ":8080"),
+		RequireIfMatch: getBool("HTTP_REQUIRE_IF_MATCH", false),
 	}
 
 	database := &Database{
@@ -34,9 +125,77 @@ func Load() *Config {
 		MaxIdleTime:  getString("DATABASE_MAX_IDLE_TIME", "15m"),
 	}
 
+	money := &Money{
+		Format: getString("MONEY_FORMAT", "minor_units"),
+	}
+
+	search := &Search{
+		SuggestCacheEnabled: getBool("SEARCH_SUGGEST_CACHE_ENABLED", true),
+		SuggestCacheTTL:     getString("SEARCH_SUGGEST_CACHE_TTL", "1m"),
+		SuggestCacheSize:    getInt("SEARCH_SUGGEST_CACHE_SIZE", 10_000),
+	}
+
+	storage := &Storage{
+		LocalDir:      getString("STORAGE_LOCAL_DIR", "./uploads"),
+		PublicUrl:     getString("STORAGE_PUBLIC_URL", "http://localhost:8080/media"),
+		MaxUploadSize: getInt("STORAGE_MAX_UPLOAD_SIZE", 10<<20),
+	}
+
+	cart := &Cart{
+		TTL:           getString("CART_TTL", "720h"),
+		PurgeInterval: getString("CART_PURGE_INTERVAL", "1h"),
+	}
+
+	payment := &Payment{
+		Provider:      getString("PAYMENT_PROVIDER", "fake"),
+		WebhookSecret: getString("PAYMENT_WEBHOOK_SECRET", DefaultPaymentWebhookSecret),
+	}
+
+	auth := &Auth{
+		TokenAlgorithm:      getString("AUTH_TOKEN_ALGORITHM", "HS256"),
+		TokenSecret:         getString("AUTH_TOKEN_SECRET", ""),
+		TokenPrivateKeyFile: getString("AUTH_TOKEN_PRIVATE_KEY_FILE", ""),
+		TokenPublicKeyFile:  getString("AUTH_TOKEN_PUBLIC_KEY_FILE", ""),
+		TokenIssuer:         getString("AUTH_TOKEN_ISSUER", "ecom-api"),
+		AccessTokenTTL:      getString("AUTH_ACCESS_TOKEN_TTL", "15m"),
+		RefreshTokenTTL:     getString("AUTH_REFRESH_TOKEN_TTL", "720h"),
+		PasswordResetTTL:    getString("AUTH_PASSWORD_RESET_TTL", "1h"),
+		VerificationTTL:     getString("AUTH_VERIFICATION_TTL", "48h"),
+		TwoFactorRoles:      getString("AUTH_TWO_FACTOR_ROLES", "catalog_manager,admin"),
+		TOTPIssuer:          getString("AUTH_TOTP_ISSUER", "Ecom"),
+	}
+
+	mail := &Mail{
+		Transport:        getString("MAIL_TRANSPORT", "file"),
+		From:             getString("MAIL_FROM", "no-reply@ecom.local"),
+		AppUrl:           getString("MAIL_APP_URL", "http://localhost:3000"),
+		FileDir:          getString("MAIL_FILE_DIR", "./mail"),
+		SMTPHost:         getString("MAIL_SMTP_HOST", "localhost"),
+		SMTPPort:         getInt("MAIL_SMTP_PORT", 587),
+		SMTPUsername:     getString("MAIL_SMTP_USERNAME", ""),
+		SMTPPassword:     getString("MAIL_SMTP_PASSWORD", ""),
+		DispatchInterval: getString("MAIL_DISPATCH_INTERVAL", "5s"),
+		DispatchBatch:    getInt("MAIL_DISPATCH_BATCH", 20),
+	}
+
+	cache := &Cache{
+		Product:    getString("CACHE_CONTROL_PRODUCT", "public, max-age=60"),
+		Products:   getString("CACHE_CONTROL_PRODUCTS", "public, max-age=30"),
+		Brands:     getString("CACHE_CONTROL_BRANDS", "public, max-age=300"),
+		Categories: getString("CACHE_CONTROL_CATEGORIES", "public, max-age=300"),
+	}
+
 	return &Config{
 		Http:     http,
 		Database: database,
+		Money:    money,
+		Search:   search,
+		Storage:  storage,
+		Cart:     cart,
+		Payment:  payment,
+		Auth:     auth,
+		Mail:     mail,
+		Cache:    cache,
 		Env:      getString("ENV", "development"),
 	}
 }
@@ -58,3 +217,14 @@ func getInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+func getBool(key string, fallback bool) bool {
+	if value, ok := os.LookupEnv(key); ok {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fallback
+		}
+		return b
+	}
+	return fallback
+}
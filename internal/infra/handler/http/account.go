@@ -0,0 +1,132 @@
+package http
+
+import (
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type AccountHandler struct {
+	config         *config.Config
+	logger         *zap.SugaredLogger
+	accountService port.AccountService
+}
+
+func NewAccountHandler(config *config.Config, logger *zap.SugaredLogger, accountService port.AccountService) *AccountHandler {
+	return &AccountHandler{
+		config:         config,
+		logger:         logger,
+		accountService: accountService,
+	}
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+// ForgotPassword always answers 202 so it doesn't reveal which addresses
+// have accounts.
+func (h *AccountHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req forgotPasswordRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		internalServerError(w, r, err, h.logger)
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusAccepted, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" validate:"required,max=128"`
+	Password string `json:"password" validate:"required,min=8,maxbytes=72"`
+}
+
+func (h *AccountHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req resetPasswordRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := h.accountService.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		h.tokenError(w, r, err)
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token" validate:"required,max=128"`
+}
+
+func (h *AccountHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req verifyEmailRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(r.Context(), req.Token); err != nil {
+		h.tokenError(w, r, err)
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *AccountHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	principal := getPrincipalFromCtx(r.Context())
+
+	if err := h.accountService.RequestEmailVerification(r.Context(), principal.UserId); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusAccepted, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *AccountHandler) tokenError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidToken):
+		badRequestResponse(w, r, err, h.logger)
+	default:
+		internalServerError(w, r, err, h.logger)
+	}
+}
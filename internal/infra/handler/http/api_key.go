@@ -0,0 +1,137 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type apiKeyIdKey string
+
+const apiKeyIdCtx apiKeyIdKey = "apiKeyId"
+
+type ApiKeyHandler struct {
+	config        *config.Config
+	logger        *zap.SugaredLogger
+	apiKeyService port.ApiKeyService
+}
+
+func NewApiKeyHandler(config *config.Config, logger *zap.SugaredLogger, apiKeyService port.ApiKeyService) *ApiKeyHandler {
+	return &ApiKeyHandler{
+		config:        config,
+		logger:        logger,
+		apiKeyService: apiKeyService,
+	}
+}
+
+func (h *ApiKeyHandler) ListApiKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.List(r.Context())
+	if err != nil {
+		internalServerError(w, r, err, h.logger)
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, keys); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type createApiKeyRequest struct {
+	Name      string     `json:"name" validate:"required,min=2,max=255"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,unique,dive,oneof=products:read products:write"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreateApiKey responds with the full key. It is stored hashed, so this is
+// the only chance to copy it.
+func (h *ApiKeyHandler) CreateApiKey(w http.ResponseWriter, r *http.Request) {
+	var req createApiKeyRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		badRequestResponse(w, r, errors.New("expires_at must be in the future"), h.logger)
+		return
+	}
+
+	scopes := make([]domain.Permission, len(req.Scopes))
+	for i, scope := range req.Scopes {
+		scopes[i] = domain.Permission(scope)
+	}
+
+	principal := getPrincipalFromCtx(r.Context())
+
+	plain, key, err := h.apiKeyService.Create(r.Context(), req.Name, scopes, req.ExpiresAt, &principal.UserId)
+	if err != nil {
+		internalServerError(w, r, err, h.logger)
+		return
+	}
+
+	keyWithSecret := struct {
+		Key    string         `json:"key"`
+		ApiKey *domain.ApiKey `json:"api_key"`
+	}{
+		Key:    plain,
+		ApiKey: key,
+	}
+
+	if err = jsonResponse(w, http.StatusCreated, keyWithSecret); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *ApiKeyHandler) RevokeApiKey(w http.ResponseWriter, r *http.Request) {
+	id := getApiKeyIdFromCtx(r.Context())
+
+	if err := h.apiKeyService.Revoke(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *ApiKeyHandler) ApiKeyIdMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idStr := chi.URLParam(r, "apiKeyId")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			badRequestResponse(w, r, err, h.logger)
+			return
+		}
+
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, apiKeyIdCtx, id)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getApiKeyIdFromCtx(ctx context.Context) int64 {
+	val := ctx.Value(apiKeyIdCtx)
+	if val == nil {
+		return 0
+	}
+	return val.(int64)
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type AttributeHandler struct {
+	config           *config.Config
+	logger           *zap.SugaredLogger
+	attributeService port.AttributeService
+}
+
+func NewAttributeHandler(config *config.Config, logger *zap.SugaredLogger, attributeService port.AttributeService) *AttributeHandler {
+	return &AttributeHandler{
+		config:           config,
+		logger:           logger,
+		attributeService: attributeService,
+	}
+}
+
+func (h *AttributeHandler) ListAttributes(w http.ResponseWriter, r *http.Request) {
+	slug := getCategorySlugFromCtx(r.Context())
+
+	definitions, err := h.attributeService.ListDefinitions(r.Context(), slug)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, definitions); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type createAttributeRequest struct {
+	Code     string   `json:"code" validate:"required,min=1,max=64,lowercase,excludesall=.-<>=!"`
+	Name     string   `json:"name" validate:"required,min=1,max=255"`
+	Type     string   `json:"type" validate:"required,oneof=number enum boolean text"`
+	Unit     *string  `json:"unit" validate:"omitempty,max=32"`
+	Values   []string `json:"values" validate:"omitempty,dive,required,max=255"`
+	Required bool     `json:"required"`
+}
+
+func (h *AttributeHandler) CreateAttribute(w http.ResponseWriter, r *http.Request) {
+	slug := getCategorySlugFromCtx(r.Context())
+
+	var req createAttributeRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	definition := &domain.AttributeDefinition{
+		Code:     req.Code,
+		Name:     req.Name,
+		Type:     domain.AttributeType(req.Type),
+		Unit:     req.Unit,
+		Values:   req.Values,
+		Required: req.Required,
+	}
+
+	if err := h.attributeService.CreateDefinition(r.Context(), slug, definition); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrInvalidAttribute):
+			badRequestResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrConflict):
+			conflictResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusCreated, definition); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *AttributeHandler) DeleteAttribute(w http.ResponseWriter, r *http.Request) {
+	slug := getCategorySlugFromCtx(r.Context())
+	code := chi.URLParam(r, "code")
+
+	if err := h.attributeService.DeleteDefinition(r.Context(), slug, code); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
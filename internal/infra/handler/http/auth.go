@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+)
+
+type principalKey string
+
+const principalCtx principalKey = "principal"
+
+var errAuthenticationRequired = errors.New("authentication required")
+
+type AuthHandler struct {
+	config          *config.Config
+	logger          *zap.SugaredLogger
+	sessionService  port.SessionService
+	apiKeyService   port.ApiKeyService
+	twoFactorPolicy domain.TwoFactorPolicy
+}
+
+func NewAuthHandler(config *config.Config, logger *zap.SugaredLogger, sessionService port.SessionService, apiKeyService port.ApiKeyService, twoFactorPolicy domain.TwoFactorPolicy) *AuthHandler {
+	return &AuthHandler{
+		config:          config,
+		logger:          logger,
+		sessionService:  sessionService,
+		apiKeyService:   apiKeyService,
+		twoFactorPolicy: twoFactorPolicy,
+	}
+}
+
+// Authenticate resolves the bearer credential, if any, to a principal:
+// an API key when it carries the key prefix, an access token of a live
+// session otherwise. Requests without one carry on anonymously; a bad
+// credential is rejected outright rather than silently downgraded.
+func (h *AuthHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			unauthorizedResponse(w, r, domain.ErrInvalidToken, h.logger)
+			return
+		}
+
+		var principal *domain.Principal
+		var err error
+		if strings.HasPrefix(token, domain.ApiKeyPrefix) {
+			principal, err = h.apiKeyService.Authenticate(r.Context(), token)
+		} else {
+			principal, err = h.sessionService.Authenticate(r.Context(), token)
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrInvalidToken), errors.Is(err, domain.ErrInvalidApiKey):
+				unauthorizedResponse(w, r, err, h.logger)
+			default:
+				internalServerError(w, r, err, h.logger)
+			}
+			return
+		}
+
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, principalCtx, principal)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireUser turns away requests not made by a signed-in user, API keys
+// included.
+func (h *AuthHandler) RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if principal := getPrincipalFromCtx(r.Context()); principal == nil || !principal.IsUser() {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			unauthorizedResponse(w, r, errAuthenticationRequired, h.logger)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequirePermission lets through only principals granted permission.
+// Users whose role the two-factor policy covers must also have signed in
+// with a second factor; until then they can still reach /me to enrol.
+func (h *AuthHandler) RequirePermission(permission domain.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := getPrincipalFromCtx(r.Context())
+			if principal == nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				unauthorizedResponse(w, r, errAuthenticationRequired, h.logger)
+				return
+			}
+
+			if !principal.Can(permission) {
+				forbiddenResponse(w, r, domain.ErrForbidden, h.logger)
+				return
+			}
+
+			if principal.IsUser() && !principal.TwoFactor && h.twoFactorPolicy.Requires(principal.Role) {
+				forbiddenResponse(w, r, domain.ErrTwoFactorRequired, h.logger)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func getPrincipalFromCtx(ctx context.Context) *domain.Principal {
+	val := ctx.Value(principalCtx)
+	if val == nil {
+		return nil
+	}
+	return val.(*domain.Principal)
+}
@@ -0,0 +1,268 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type brandSlugKey string
+
+const brandSlugCtx brandSlugKey = "brandSlug"
+
+type BrandHandler struct {
+	config       *config.Config
+	logger       *zap.SugaredLogger
+	brandService port.BrandService
+}
+
+func NewBrandHandler(config *config.Config, logger *zap.SugaredLogger, brandService port.BrandService) *BrandHandler {
+	return &BrandHandler{
+		config:       config,
+		logger:       logger,
+		brandService: brandService,
+	}
+}
+
+func (h *BrandHandler) GetBrand(w http.ResponseWriter, r *http.Request) {
+	slug := getBrandSlugFromCtx(r.Context())
+
+	brand, err := h.brandService.GetBySlug(r.Context(), slug)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, brand); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type createBrandRequest struct {
+	Name        string  `json:"name" validate:"required,min=2,max=255"`
+	Description *string `json:"description" validate:"omitempty,min=16,max=1000"`
+	LogoUrl     *string `json:"logo_url" validate:"omitempty,url,max=255"`
+}
+
+func (h *BrandHandler) CreateBrand(w http.ResponseWriter, r *http.Request) {
+	var req createBrandRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	brand := &domain.Brand{
+		Name:        req.Name,
+		Description: req.Description,
+		LogoUrl:     req.LogoUrl,
+	}
+
+	if err := h.brandService.Create(r.Context(), brand); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrConflict):
+			conflictResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusCreated, brand); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *BrandHandler) DeleteBrand(w http.ResponseWriter, r *http.Request) {
+	slug := getBrandSlugFromCtx(r.Context())
+
+	if err := h.brandService.Delete(r.Context(), slug); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type updateBrandRequest struct {
+	Name        string  `json:"name" validate:"required,min=2,max=255"`
+	Description *string `json:"description" validate:"omitempty,min=16,max=1000"`
+	LogoUrl     *string `json:"logo_url" validate:"omitempty,url,max=255"`
+}
+
+func (h *BrandHandler) UpdateBrand(w http.ResponseWriter, r *http.Request) {
+	slug := getBrandSlugFromCtx(r.Context())
+
+	var req updateBrandRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	brand := &domain.Brand{
+		Name:        req.Name,
+		Description: req.Description,
+		LogoUrl:     req.LogoUrl,
+	}
+
+	if err := h.brandService.Update(r.Context(), slug, brand); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrConflict):
+			conflictResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusOK, brand); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *BrandHandler) ListBrands(w http.ResponseWriter, r *http.Request) {
+	query := domain.PaginatedBrandsQuery{
+		Offset:        0,
+		Limit:         20,
+		Search:        "",
+		SortField:     "name",
+		SortDirection: "asc",
+	}
+
+	query, err := query.Parse(r)
+	if err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err = validate.Struct(query); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	brands, meta, err := h.brandService.List(r.Context(), query)
+	if err != nil {
+		internalServerError(w, r, err, h.logger)
+		return
+	}
+
+	brandsWithMeta := struct {
+		Meta   domain.Meta    `json:"meta"`
+		Brands []domain.Brand `json:"brands"`
+	}{
+		Meta:   meta,
+		Brands: brands,
+	}
+
+	if err = jsonResponse(w, http.StatusOK, brandsWithMeta); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *BrandHandler) ListBrandProducts(w http.ResponseWriter, r *http.Request) {
+	slug := getBrandSlugFromCtx(r.Context())
+
+	query := domain.PaginatedProductsQuery{
+		Offset:        0,
+		Limit:         20,
+		Search:        "",
+		SortField:     "name",
+		SortDirection: "desc",
+		Pagination:    domain.PaginationOffset,
+	}
+
+	query, err := query.Parse(r)
+	if err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err = validate.Struct(query); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if query.Pagination == domain.PaginationCursor {
+		badRequestResponse(w, r, errors.New("brand products only support offset pagination"), h.logger)
+		return
+	}
+
+	products, meta, err := h.brandService.ListProducts(r.Context(), slug, query)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	productsWithMeta := struct {
+		Meta     domain.Meta             `json:"meta"`
+		Products []domain.ProductSummary `json:"products"`
+	}{
+		Meta:     meta,
+		Products: products,
+	}
+
+	if notModified(w, r, listETag(products, meta), time.Time{}) {
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, productsWithMeta); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *BrandHandler) BrandSlugMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slug := chi.URLParam(r, "slug")
+		if slug == "" {
+			badRequestResponse(w, r, errors.New("missing brand slug"), h.logger)
+			return
+		}
+
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, brandSlugCtx, slug)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getBrandSlugFromCtx(ctx context.Context) string {
+	val := ctx.Value(brandSlugCtx)
+	if val == nil {
+		return ""
+	}
+	return val.(string)
+}
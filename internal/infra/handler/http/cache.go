@@ -0,0 +1,127 @@
+package http
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheControl sends value as Cache-Control with successful responses, and
+// with 304s, which must repeat it. Errors are left uncacheable.
+func cacheControl(value string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if value == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(&cacheControlWriter{ResponseWriter: w, value: value}, r)
+		})
+	}
+}
+
+type cacheControlWriter struct {
+	http.ResponseWriter
+	value       string
+	wroteHeader bool
+}
+
+func (w *cacheControlWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if status == http.StatusOK || status == http.StatusNotModified {
+			w.Header().Set("Cache-Control", w.value)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cacheControlWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// notModified sets the response's validators and reports whether the
+// request's conditional headers show the client already holds this
+// representation, in which case it has answered 304. If-None-Match wins
+// over If-Modified-Since; a zero lastModified sends no Last-Modified.
+func notModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if header := strings.Join(r.Header.Values("If-None-Match"), ","); header != "" {
+		if !etagListMatches(header, etag) {
+			return false
+		}
+	} else if header = r.Header.Get("If-Modified-Since"); header != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(header)
+		// HTTP dates have whole seconds, so a change within the second the
+		// client's copy is dated could be missed; that's what ETags are for.
+		if err != nil || lastModified.Truncate(time.Second).After(since) {
+			return false
+		}
+	} else {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagListMatches compares etag weakly against each tag in an If-None-Match
+// header, where "*" matches anything.
+func etagListMatches(header, etag string) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listETag is the weak entity tag of a page of products. It moves when any
+// product on the page changes, by way of the newest updated_at, and when
+// the page's make-up, the brands and categories it shows, or the rest of
+// the payload (meta, facets) does, none of which touch updated_at.
+func listETag(products []domain.ProductSummary, rest ...any) string {
+	var newest time.Time
+	hash := fnv.New64a()
+	embedded := make([]any, 0, 2*len(products))
+	for _, product := range products {
+		if product.UpdatedAt.After(newest) {
+			newest = product.UpdatedAt
+		}
+		hash.Write(binary.BigEndian.AppendUint64(nil, uint64(product.Id)))
+		embedded = append(embedded, product.Category, product.Brand)
+	}
+
+	if err := json.NewEncoder(hash).Encode([]any{embedded, rest}); err != nil {
+		return ""
+	}
+
+	return `W/"` + strconv.FormatInt(newest.UnixMicro(), 36) + "-" + strconv.FormatUint(hash.Sum64(), 36) + `"`
+}
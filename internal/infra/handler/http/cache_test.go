@@ -0,0 +1,274 @@
+package http
+
+import (
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNotModified(t *testing.T) {
+	lastModified := time.Date(2025, 3, 14, 12, 0, 0, 500_000_000, time.UTC)
+
+	tests := []struct {
+		name         string
+		method       string
+		headers      map[string]string
+		etag         string
+		lastModified time.Time
+		want         bool
+	}{
+		{
+			name:         "should_serve_without_conditional_headers",
+			method:       http.MethodGet,
+			etag:         `"3"`,
+			lastModified: lastModified,
+			want:         false,
+		},
+		{
+			name:    "should_match_same_etag",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-None-Match": `"3"`},
+			etag:    `"3"`,
+			want:    true,
+		},
+		{
+			name:    "should_match_weak_etag_weakly",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-None-Match": `W/"3"`},
+			etag:    `"3"`,
+			want:    true,
+		},
+		{
+			name:    "should_match_any_etag_with_star",
+			method:  http.MethodHead,
+			headers: map[string]string{"If-None-Match": "*"},
+			etag:    `"3"`,
+			want:    true,
+		},
+		{
+			name:    "should_serve_changed_etag",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-None-Match": `"2"`},
+			etag:    `"3"`,
+			want:    false,
+		},
+		{
+			name:         "should_prefer_if_none_match_over_if_modified_since",
+			method:       http.MethodGet,
+			headers:      map[string]string{"If-None-Match": `"2"`, "If-Modified-Since": lastModified.Format(http.TimeFormat)},
+			etag:         `"3"`,
+			lastModified: lastModified,
+			want:         false,
+		},
+		{
+			name:         "should_match_if_modified_since_within_same_second",
+			method:       http.MethodGet,
+			headers:      map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)},
+			etag:         `"3"`,
+			lastModified: lastModified,
+			want:         true,
+		},
+		{
+			name:         "should_serve_modified_since",
+			method:       http.MethodGet,
+			headers:      map[string]string{"If-Modified-Since": lastModified.Add(-time.Second).Format(http.TimeFormat)},
+			etag:         `"3"`,
+			lastModified: lastModified,
+			want:         false,
+		},
+		{
+			name:         "should_serve_unparseable_if_modified_since",
+			method:       http.MethodGet,
+			headers:      map[string]string{"If-Modified-Since": "yesterday"},
+			lastModified: lastModified,
+			want:         false,
+		},
+		{
+			name:    "should_ignore_if_modified_since_without_last_modified",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)},
+			etag:    `W/"list"`,
+			want:    false,
+		},
+		{
+			name:    "should_ignore_conditions_on_writes",
+			method:  http.MethodPut,
+			headers: map[string]string{"If-None-Match": "*"},
+			etag:    `"3"`,
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/products/1", nil)
+			for key, value := range tt.headers {
+				r.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+
+			got := notModified(w, r, tt.etag, tt.lastModified)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.etag, w.Header().Get("ETag"))
+			if tt.lastModified.IsZero() {
+				assert.Empty(t, w.Header().Get("Last-Modified"))
+			} else {
+				assert.Equal(t, tt.lastModified.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+			}
+			if tt.want {
+				assert.Equal(t, http.StatusNotModified, w.Code)
+			}
+		})
+	}
+}
+
+func TestEtagListMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		etag   string
+		want   bool
+	}{
+		{name: "should_match_exact_tag", header: `"a"`, etag: `"a"`, want: true},
+		{name: "should_match_weak_against_strong", header: `W/"a"`, etag: `"a"`, want: true},
+		{name: "should_match_strong_against_weak", header: `"a"`, etag: `W/"a"`, want: true},
+		{name: "should_match_any_tag_in_list", header: `"x", W/"a" ,"y"`, etag: `W/"a"`, want: true},
+		{name: "should_match_star", header: `*`, etag: `"a"`, want: true},
+		{name: "should_not_match_other_tags", header: `"x", "y"`, etag: `"a"`, want: false},
+		{name: "should_not_match_without_etag", header: `*`, etag: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, etagListMatches(tt.header, tt.etag))
+		})
+	}
+}
+
+func TestListETag(t *testing.T) {
+	updatedAt := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	summary := func(id int64, updatedAt time.Time) domain.ProductSummary {
+		return domain.ProductSummary{BaseProduct: domain.BaseProduct{Id: id}, UpdatedAt: updatedAt}
+	}
+	page := []domain.ProductSummary{summary(1, updatedAt), summary(2, updatedAt.Add(-time.Hour))}
+	meta := domain.Meta{TotalItems: 2}
+	base := listETag(page, meta)
+
+	tests := []struct {
+		name     string
+		products []domain.ProductSummary
+		meta     domain.Meta
+		same     bool
+	}{
+		{
+			name:     "should_be_stable_for_same_page",
+			products: []domain.ProductSummary{summary(1, updatedAt), summary(2, updatedAt.Add(-time.Hour))},
+			meta:     meta,
+			same:     true,
+		},
+		{
+			name:     "should_move_when_product_changes",
+			products: []domain.ProductSummary{summary(1, updatedAt), summary(2, updatedAt.Add(time.Microsecond))},
+			meta:     meta,
+		},
+		{
+			name:     "should_move_when_page_make_up_changes",
+			products: []domain.ProductSummary{summary(1, updatedAt), summary(3, updatedAt.Add(-time.Hour))},
+			meta:     meta,
+		},
+		{
+			name: "should_move_when_brand_is_renamed",
+			products: []domain.ProductSummary{
+				{BaseProduct: domain.BaseProduct{Id: 1}, Brand: &domain.BrandSummary{Id: 4, Name: "Renamed"}, UpdatedAt: updatedAt},
+				summary(2, updatedAt.Add(-time.Hour)),
+			},
+			meta: meta,
+		},
+		{
+			name:     "should_move_when_meta_changes",
+			products: page,
+			meta:     domain.Meta{TotalItems: 3},
+		},
+	}
+
+	assert.Regexp(t, `^W/".+-.+"$`, base)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			etag := listETag(tt.products, tt.meta)
+
+			if tt.same {
+				assert.Equal(t, base, etag)
+			} else {
+				assert.NotEqual(t, base, etag)
+			}
+		})
+	}
+}
+
+func TestCacheControlWriter(t *testing.T) {
+	tests := []struct {
+		name         string
+		handler      http.HandlerFunc
+		wantStatus   int
+		cacheControl string
+	}{
+		{
+			name: "should_set_on_implicit_ok",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("{}"))
+			},
+			wantStatus:   http.StatusOK,
+			cacheControl: "public, max-age=60",
+		},
+		{
+			name: "should_keep_on_not_modified",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				notModified(w, r, `"3"`, time.Time{})
+			},
+			wantStatus:   http.StatusNotModified,
+			cacheControl: "public, max-age=60",
+		},
+		{
+			name: "should_leave_errors_uncacheable",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "should_not_set_when_first_status_is_error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.WriteHeader(http.StatusOK)
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+			r.Header.Set("If-None-Match", `"3"`)
+			w := httptest.NewRecorder()
+
+			cacheControl("public, max-age=60")(tt.handler).ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.cacheControl, w.Header().Get("Cache-Control"))
+		})
+	}
+
+	t.Run("should_pass_through_without_value", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		wrapped := cacheControl("")(handler)
+
+		w := httptest.NewRecorder()
+		wrapped.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Empty(t, w.Header().Get("Cache-Control"))
+	})
+}
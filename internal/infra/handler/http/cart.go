@@ -0,0 +1,168 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type cartItemIdKey string
+
+const cartItemIdCtx cartItemIdKey = "cartItemId"
+
+// cartTokenHeader carries the token of an anonymous visitor's cart. It is
+// sent back on every cart response so clients can pick up a new token.
+const cartTokenHeader = "X-Cart-Token"
+
+type CartHandler struct {
+	config      *config.Config
+	logger      *zap.SugaredLogger
+	cartService port.CartService
+}
+
+func NewCartHandler(config *config.Config, logger *zap.SugaredLogger, cartService port.CartService) *CartHandler {
+	return &CartHandler{
+		config:      config,
+		logger:      logger,
+		cartService: cartService,
+	}
+}
+
+func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
+	cart, err := h.cartService.Get(r.Context(), cartOwner(r))
+	if err != nil {
+		internalServerError(w, r, err, h.logger)
+		return
+	}
+
+	h.cartResponse(w, r, http.StatusOK, cart)
+}
+
+type addCartItemRequest struct {
+	ProductId int64  `json:"product_id" validate:"required,min=1"`
+	VariantId *int64 `json:"variant_id" validate:"omitempty,min=1"`
+	Quantity  int64  `json:"quantity" validate:"required,min=1,max=99"`
+}
+
+func (h *CartHandler) AddCartItem(w http.ResponseWriter, r *http.Request) {
+	var req addCartItemRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	cart, err := h.cartService.AddItem(r.Context(), cartOwner(r), req.ProductId, req.VariantId, req.Quantity)
+	if err != nil {
+		h.handleWriteError(w, r, err)
+		return
+	}
+
+	h.cartResponse(w, r, http.StatusOK, cart)
+}
+
+type updateCartItemRequest struct {
+	Quantity int64 `json:"quantity" validate:"required,min=1,max=99"`
+}
+
+func (h *CartHandler) UpdateCartItem(w http.ResponseWriter, r *http.Request) {
+	id := getCartItemIdFromCtx(r.Context())
+
+	var req updateCartItemRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	cart, err := h.cartService.UpdateItem(r.Context(), cartOwner(r), id, req.Quantity)
+	if err != nil {
+		h.handleWriteError(w, r, err)
+		return
+	}
+
+	h.cartResponse(w, r, http.StatusOK, cart)
+}
+
+func (h *CartHandler) RemoveCartItem(w http.ResponseWriter, r *http.Request) {
+	id := getCartItemIdFromCtx(r.Context())
+
+	cart, err := h.cartService.RemoveItem(r.Context(), cartOwner(r), id)
+	if err != nil {
+		h.handleWriteError(w, r, err)
+		return
+	}
+
+	h.cartResponse(w, r, http.StatusOK, cart)
+}
+
+func (h *CartHandler) cartResponse(w http.ResponseWriter, r *http.Request, status int, cart *domain.Cart) {
+	if cart.Token != nil {
+		w.Header().Set(cartTokenHeader, *cart.Token)
+	}
+
+	if err := jsonResponse(w, status, cart); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *CartHandler) handleWriteError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		notFoundResponse(w, r, err, h.logger)
+	case errors.Is(err, domain.ErrInvalidCartItem), errors.Is(err, domain.ErrCurrencyMismatch):
+		badRequestResponse(w, r, err, h.logger)
+	case errors.Is(err, domain.ErrInsufficientStock):
+		conflictResponse(w, r, err, h.logger)
+	default:
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *CartHandler) CartItemIdMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idStr := chi.URLParam(r, "itemId")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			badRequestResponse(w, r, err, h.logger)
+			return
+		}
+
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, cartItemIdCtx, id)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getCartItemIdFromCtx(ctx context.Context) int64 {
+	val := ctx.Value(cartItemIdCtx)
+	if val == nil {
+		return 0
+	}
+	return val.(int64)
+}
+
+// cartOwner identifies whose cart a request is about: the signed-in
+// customer, or else the visitor holding the cart token.
+func cartOwner(r *http.Request) domain.CartOwner {
+	owner := domain.CartOwner{Token: r.Header.Get(cartTokenHeader)}
+	if principal := getPrincipalFromCtx(r.Context()); principal != nil && principal.IsUser() {
+		owner.CustomerId = principal.UserId
+	}
+	return owner
+}
@@ -0,0 +1,224 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type categorySlugKey string
+
+const categorySlugCtx categorySlugKey = "categorySlug"
+
+type CategoryHandler struct {
+	config          *config.Config
+	logger          *zap.SugaredLogger
+	categoryService port.CategoryService
+}
+
+func NewCategoryHandler(config *config.Config, logger *zap.SugaredLogger, categoryService port.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		config:          config,
+		logger:          logger,
+		categoryService: categoryService,
+	}
+}
+
+func (h *CategoryHandler) GetCategoryTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.categoryService.Tree(r.Context())
+	if err != nil {
+		internalServerError(w, r, err, h.logger)
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, tree); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	slug := getCategorySlugFromCtx(r.Context())
+
+	category, err := h.categoryService.GetBySlug(r.Context(), slug)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, category); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type createCategoryRequest struct {
+	Name        string  `json:"name" validate:"required,min=2,max=255"`
+	Description *string `json:"description" validate:"omitempty,min=16,max=1000"`
+	ImageUrl    *string `json:"image_url" validate:"omitempty,url,max=255"`
+	ParentID    *int64  `json:"parent_id" validate:"omitempty,min=1"`
+}
+
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req createCategoryRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	category := &domain.Category{
+		Name:        req.Name,
+		Description: req.Description,
+		ImageUrl:    req.ImageUrl,
+		ParentId:    req.ParentID,
+	}
+
+	if err := h.categoryService.Create(r.Context(), category); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrParentNotFound):
+			badRequestResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrConflict):
+			conflictResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusCreated, category); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type updateCategoryRequest struct {
+	Name        string  `json:"name" validate:"required,min=2,max=255"`
+	Description *string `json:"description" validate:"omitempty,min=16,max=1000"`
+	ImageUrl    *string `json:"image_url" validate:"omitempty,url,max=255"`
+}
+
+func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	slug := getCategorySlugFromCtx(r.Context())
+
+	var req updateCategoryRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	category := &domain.Category{
+		Name:        req.Name,
+		Description: req.Description,
+		ImageUrl:    req.ImageUrl,
+	}
+
+	if err := h.categoryService.Update(r.Context(), slug, category); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrConflict):
+			conflictResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusOK, category); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type moveCategoryRequest struct {
+	ParentID *int64 `json:"parent_id" validate:"omitempty,min=1"`
+}
+
+func (h *CategoryHandler) MoveCategory(w http.ResponseWriter, r *http.Request) {
+	slug := getCategorySlugFromCtx(r.Context())
+
+	var req moveCategoryRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	category, err := h.categoryService.Move(r.Context(), slug, req.ParentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrParentNotFound), errors.Is(err, domain.ErrCategoryCycle):
+			badRequestResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, category); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *CategoryHandler) DeactivateCategory(w http.ResponseWriter, r *http.Request) {
+	slug := getCategorySlugFromCtx(r.Context())
+
+	if err := h.categoryService.Deactivate(r.Context(), slug); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *CategoryHandler) CategorySlugMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slug := chi.URLParam(r, "slug")
+		if slug == "" {
+			badRequestResponse(w, r, errors.New("missing category slug"), h.logger)
+			return
+		}
+
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, categorySlugCtx, slug)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getCategorySlugFromCtx(ctx context.Context) string {
+	val := ctx.Value(categorySlugCtx)
+	if val == nil {
+		return ""
+	}
+	return val.(string)
+}
@@ -19,3 +19,33 @@ func notFoundResponse(w http.ResponseWriter, r *http.Request, err error, logger
 	logger.Warnw("not found response", "path", r.URL.Path, "method", r.Method, "error", err.Error())
 	_ = jsonErrorResponse(w, http.StatusNotFound, "not found")
 }
+
+func conflictResponse(w http.ResponseWriter, r *http.Request, err error, logger *zap.SugaredLogger) {
+	logger.Warnw("conflict response", "path", r.URL.Path, "method", r.Method, "error", err.Error())
+	_ = jsonErrorResponse(w, http.StatusConflict, err.Error())
+}
+
+func unsupportedMediaTypeResponse(w http.ResponseWriter, r *http.Request, err error, logger *zap.SugaredLogger) {
+	logger.Warnw("unsupported media type response", "path", r.URL.Path, "method", r.Method, "error", err.Error())
+	_ = jsonErrorResponse(w, http.StatusUnsupportedMediaType, err.Error())
+}
+
+func unauthorizedResponse(w http.ResponseWriter, r *http.Request, err error, logger *zap.SugaredLogger) {
+	logger.Warnw("unauthorized response", "path", r.URL.Path, "method", r.Method, "error", err.Error())
+	_ = jsonErrorResponse(w, http.StatusUnauthorized, err.Error())
+}
+
+func paymentRequiredResponse(w http.ResponseWriter, r *http.Request, err error, logger *zap.SugaredLogger) {
+	logger.Warnw("payment required response", "path", r.URL.Path, "method", r.Method, "error", err.Error())
+	_ = jsonErrorResponse(w, http.StatusPaymentRequired, err.Error())
+}
+
+func forbiddenResponse(w http.ResponseWriter, r *http.Request, err error, logger *zap.SugaredLogger) {
+	logger.Warnw("forbidden response", "path", r.URL.Path, "method", r.Method, "error", err.Error())
+	_ = jsonErrorResponse(w, http.StatusForbidden, err.Error())
+}
+
+func preconditionRequiredResponse(w http.ResponseWriter, r *http.Request, err error, logger *zap.SugaredLogger) {
+	logger.Warnw("precondition required response", "path", r.URL.Path, "method", r.Method, "error", err.Error())
+	_ = jsonErrorResponse(w, http.StatusPreconditionRequired, err.Error())
+}
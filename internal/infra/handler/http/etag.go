@@ -0,0 +1,72 @@
+package http
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errIfMatchRequired = errors.New("If-Match header is required")
+
+// versionETag is the strong entity tag of a resource at version.
+func versionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// productETag is the entity tag of a product as GetProduct shows it: its
+// version, then a digest of the brand and categories it embeds, which can
+// change without the product's version moving. Writes only compare the
+// version, so renaming a brand doesn't fail everyone's If-Match.
+func productETag(product *domain.Product) string {
+	hash := fnv.New64a()
+	write := func(id int64, updatedAt time.Time) {
+		hash.Write(binary.BigEndian.AppendUint64(nil, uint64(id)))
+		hash.Write(binary.BigEndian.AppendUint64(nil, uint64(updatedAt.UnixMicro())))
+	}
+
+	if product.Brand != nil {
+		write(product.Brand.Id, product.Brand.UpdatedAt)
+	}
+	for category := product.Category; category != nil; category = category.Parent {
+		write(category.Id, category.UpdatedAt)
+	}
+
+	return `"` + strconv.FormatInt(product.Version, 10) + "-" + strconv.FormatUint(hash.Sum64(), 36) + `"`
+}
+
+// productLastModified is when the product, or anything it embeds, last
+// changed.
+func productLastModified(product *domain.Product) time.Time {
+	lastModified := product.UpdatedAt
+	if product.Brand != nil && product.Brand.UpdatedAt.After(lastModified) {
+		lastModified = product.Brand.UpdatedAt
+	}
+	for category := product.Category; category != nil; category = category.Parent {
+		if category.UpdatedAt.After(lastModified) {
+			lastModified = category.UpdatedAt
+		}
+	}
+	return lastModified
+}
+
+// parseVersionETag is the inverse of versionETag and reads the version out
+// of a productETag. Any other tag, a weak one included, gets -1, which no
+// version matches.
+func parseVersionETag(tag string) int64 {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return -1
+	}
+
+	value, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || version <= 0 {
+		return -1
+	}
+
+	return version
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestProductETag(t *testing.T) {
+	updatedAt := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	newProduct := func() *domain.Product {
+		return &domain.Product{
+			Brand: &domain.Brand{Id: 4, UpdatedAt: updatedAt},
+			Category: &domain.Category{
+				Id:        7,
+				UpdatedAt: updatedAt,
+				Parent:    &domain.Category{Id: 2, UpdatedAt: updatedAt},
+			},
+			Version:   3,
+			UpdatedAt: updatedAt,
+		}
+	}
+	base := productETag(newProduct())
+
+	t.Run("should_keep_version_readable_for_if_match", func(t *testing.T) {
+		assert.Equal(t, int64(3), parseVersionETag(base))
+	})
+
+	t.Run("should_move_when_brand_changes", func(t *testing.T) {
+		product := newProduct()
+		product.Brand.UpdatedAt = updatedAt.Add(time.Second)
+
+		assert.NotEqual(t, base, productETag(product))
+		assert.Equal(t, product.Brand.UpdatedAt, productLastModified(product))
+	})
+
+	t.Run("should_move_when_ancestor_category_changes", func(t *testing.T) {
+		product := newProduct()
+		product.Category.Parent.UpdatedAt = updatedAt.Add(time.Second)
+
+		assert.NotEqual(t, base, productETag(product))
+		assert.Equal(t, product.Category.Parent.UpdatedAt, productLastModified(product))
+	})
+
+	t.Run("should_move_when_category_leaves_breadcrumb", func(t *testing.T) {
+		product := newProduct()
+		product.Category.Parent = nil
+
+		assert.NotEqual(t, base, productETag(product))
+	})
+}
+
+func TestParseVersionETag(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+		want int64
+	}{
+		{name: "should_read_version_tag", tag: `"12"`, want: 12},
+		{name: "should_read_product_tag", tag: `"12-1a2b"`, want: 12},
+		{name: "should_reject_weak_tag", tag: `W/"12"`, want: -1},
+		{name: "should_reject_unquoted_tag", tag: `12`, want: -1},
+		{name: "should_reject_zero_version", tag: `"0"`, want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseVersionETag(tt.tag))
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type imageIdKey string
+
+const imageIdCtx imageIdKey = "imageId"
+
+// imageFormField is the multipart field uploads are read from.
+const imageFormField = "image"
+
+type ImageHandler struct {
+	config       *config.Config
+	logger       *zap.SugaredLogger
+	imageService port.ImageService
+}
+
+func NewImageHandler(config *config.Config, logger *zap.SugaredLogger, imageService port.ImageService) *ImageHandler {
+	return &ImageHandler{
+		config:       config,
+		logger:       logger,
+		imageService: imageService,
+	}
+}
+
+func (h *ImageHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	productId := getProductIdFromCtx(r.Context())
+
+	maxBytes := int64(h.config.Storage.MaxUploadSize)
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+	if err := r.ParseMultipartForm(maxBytes); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, _, err := r.FormFile(imageFormField)
+	if err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	image, err := h.imageService.Upload(r.Context(), productId, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrUnsupportedImage):
+			unsupportedMediaTypeResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrImageTooLarge):
+			badRequestResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusCreated, image); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type reorderImagesRequest struct {
+	ImageIds []int64 `json:"image_ids" validate:"required,min=1"`
+}
+
+func (h *ImageHandler) ReorderImages(w http.ResponseWriter, r *http.Request) {
+	productId := getProductIdFromCtx(r.Context())
+
+	var req reorderImagesRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	images, err := h.imageService.Reorder(r.Context(), productId, req.ImageIds)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidImageOrder):
+			badRequestResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, images); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *ImageHandler) SetPrimaryImage(w http.ResponseWriter, r *http.Request) {
+	productId := getProductIdFromCtx(r.Context())
+	id := getImageIdFromCtx(r.Context())
+
+	if err := h.imageService.SetPrimary(r.Context(), productId, id); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *ImageHandler) DeleteImage(w http.ResponseWriter, r *http.Request) {
+	productId := getProductIdFromCtx(r.Context())
+	id := getImageIdFromCtx(r.Context())
+
+	if err := h.imageService.Delete(r.Context(), productId, id); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *ImageHandler) ImageIdMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idStr := chi.URLParam(r, "imageId")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			badRequestResponse(w, r, err, h.logger)
+			return
+		}
+
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, imageIdCtx, id)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getImageIdFromCtx(ctx context.Context) int64 {
+	val := ctx.Value(imageIdCtx)
+	if val == nil {
+		return 0
+	}
+	return val.(int64)
+}
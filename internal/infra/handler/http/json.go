@@ -3,10 +3,35 @@ package http
 import (
 	"encoding/json"
 	"github.com/go-playground/validator/v10"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
 	"net/http"
+	"reflect"
+	"strconv"
 )
 
-var validate = validator.New(validator.WithRequiredStructEnabled())
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Money is validated by its amount in minor units, so bounds are stated
+	// in them too: min=100 is the 1.00 floor prices had as decimals.
+	v.RegisterCustomTypeFunc(func(field reflect.Value) any {
+		if money, ok := field.Interface().(domain.Money); ok {
+			return money.Amount
+		}
+		return nil
+	}, domain.Money{})
+
+	// maxbytes bounds a string's length in bytes where max counts runes,
+	// as bcrypt's 72 byte limit on passwords needs.
+	v.RegisterValidation("maxbytes", func(fl validator.FieldLevel) bool {
+		limit, err := strconv.Atoi(fl.Param())
+		return err == nil && len(fl.Field().String()) <= limit
+	})
+
+	return v
+}
 
 type response struct {
 	Data any `json:"data"`
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestPasswordByteLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{name: "should_accept_72_bytes", password: strings.Repeat("a", 72), valid: true},
+		{name: "should_accept_multibyte_within_72_bytes", password: strings.Repeat("é", 36), valid: true},
+		{name: "should_reject_73_bytes", password: strings.Repeat("a", 73)},
+		{name: "should_reject_multibyte_over_72_bytes", password: strings.Repeat("é", 40)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registerErr := validate.Struct(&registerRequest{Email: "jane@example.com", Name: "Jane", Password: tt.password})
+			resetErr := validate.Struct(&resetPasswordRequest{Token: "abc", Password: tt.password})
+
+			if tt.valid {
+				assert.NoError(t, registerErr)
+				assert.NoError(t, resetErr)
+			} else {
+				assert.Error(t, registerErr)
+				assert.Error(t, resetErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"net/http"
+	"os"
+)
+
+// fileOnlyFS serves files but not directories, so the media route can't be
+// used to list every upload, images of unpublished products included.
+type fileOnlyFS struct {
+	fs http.FileSystem
+}
+
+func (f fileOnlyFS) Open(name string) (http.File, error) {
+	file, err := f.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if info.IsDir() {
+		file.Close()
+		return nil, os.ErrNotExist
+	}
+
+	return file, nil
+}
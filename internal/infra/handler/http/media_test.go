@@ -0,0 +1,39 @@
+package http
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileOnlyFS(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "products", "1"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "products", "1", "a.jpg"), []byte("jpeg"), 0o644))
+
+	handler := http.FileServer(fileOnlyFS{fs: http.Dir(dir)})
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "should_serve_file", path: "/products/1/a.jpg", status: http.StatusOK},
+		{name: "should_not_list_root", path: "/", status: http.StatusNotFound},
+		{name: "should_not_list_subdirectory", path: "/products/1/", status: http.StatusNotFound},
+		{name: "should_not_redirect_to_subdirectory", path: "/products", status: http.StatusNotFound},
+		{name: "should_not_find_missing_file", path: "/products/1/b.jpg", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type orderIdKey string
+
+const orderIdCtx orderIdKey = "orderId"
+
+type OrderHandler struct {
+	config       *config.Config
+	logger       *zap.SugaredLogger
+	orderService port.OrderService
+}
+
+func NewOrderHandler(config *config.Config, logger *zap.SugaredLogger, orderService port.OrderService) *OrderHandler {
+	return &OrderHandler{
+		config:       config,
+		logger:       logger,
+		orderService: orderService,
+	}
+}
+
+func (h *OrderHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	order, err := h.orderService.Checkout(r.Context(), cartOwner(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrEmptyCart), errors.Is(err, domain.ErrCurrencyMismatch):
+			badRequestResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrInsufficientStock), errors.Is(err, domain.ErrItemsUnavailable):
+			conflictResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusCreated, order); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	id := getOrderIdFromCtx(r.Context())
+
+	order, err := h.orderService.GetById(r.Context(), cartOwner(r), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, order); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	query := domain.PaginatedOrdersQuery{
+		Offset:        0,
+		Limit:         20,
+		SortDirection: "desc",
+	}
+
+	query, err := query.Parse(r)
+	if err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err = validate.Struct(query); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	orders, meta, err := h.orderService.List(r.Context(), cartOwner(r), query)
+	if err != nil {
+		internalServerError(w, r, err, h.logger)
+		return
+	}
+
+	ordersWithMeta := struct {
+		Meta   domain.Meta    `json:"meta"`
+		Orders []domain.Order `json:"orders"`
+	}{
+		Meta:   meta,
+		Orders: orders,
+	}
+
+	if err = jsonResponse(w, http.StatusOK, ordersWithMeta); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *OrderHandler) AdminGetOrder(w http.ResponseWriter, r *http.Request) {
+	id := getOrderIdFromCtx(r.Context())
+
+	order, err := h.orderService.AdminGetById(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, order); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type transitionOrderRequest struct {
+	Status string  `json:"status" validate:"required,oneof=paid fulfilling shipped delivered cancelled refunded"`
+	Reason *string `json:"reason" validate:"omitempty,max=1000"`
+}
+
+func (h *OrderHandler) TransitionOrder(w http.ResponseWriter, r *http.Request) {
+	id := getOrderIdFromCtx(r.Context())
+
+	var req transitionOrderRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	order, err := h.orderService.Transition(r.Context(), id, domain.OrderStatus(req.Status), actor(r), req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrInvalidTransition), errors.Is(err, domain.ErrConflict):
+			conflictResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, order); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+// actor names who is making a change, for audit trails.
+func actor(r *http.Request) string {
+	if principal := getPrincipalFromCtx(r.Context()); principal != nil {
+		return principal.Actor()
+	}
+	return "anonymous"
+}
+
+func (h *OrderHandler) OrderIdMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idStr := chi.URLParam(r, "id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			badRequestResponse(w, r, err, h.logger)
+			return
+		}
+
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, orderIdCtx, id)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getOrderIdFromCtx(ctx context.Context) int64 {
+	val := ctx.Value(orderIdCtx)
+	if val == nil {
+		return 0
+	}
+	return val.(int64)
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+// paymentSignatureHeader carries the provider's signature of a webhook body.
+const paymentSignatureHeader = "X-Payment-Signature"
+
+// maxWebhookSize bounds webhook bodies; provider events are small.
+const maxWebhookSize = 64 << 10
+
+type PaymentHandler struct {
+	config         *config.Config
+	logger         *zap.SugaredLogger
+	paymentService port.PaymentService
+}
+
+func NewPaymentHandler(config *config.Config, logger *zap.SugaredLogger, paymentService port.PaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		config:         config,
+		logger:         logger,
+		paymentService: paymentService,
+	}
+}
+
+func (h *PaymentHandler) AuthorizePayment(w http.ResponseWriter, r *http.Request) {
+	id := getOrderIdFromCtx(r.Context())
+
+	payment, err := h.paymentService.Authorize(r.Context(), cartOwner(r), id)
+	if err != nil {
+		h.paymentError(w, r, err)
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusCreated, payment); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *PaymentHandler) CapturePayment(w http.ResponseWriter, r *http.Request) {
+	id := getOrderIdFromCtx(r.Context())
+
+	payment, err := h.paymentService.Capture(r.Context(), id, actor(r))
+	if err != nil {
+		h.paymentError(w, r, err)
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusCreated, payment); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *PaymentHandler) VoidPayment(w http.ResponseWriter, r *http.Request) {
+	id := getOrderIdFromCtx(r.Context())
+
+	payment, err := h.paymentService.Void(r.Context(), id, actor(r))
+	if err != nil {
+		h.paymentError(w, r, err)
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusCreated, payment); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type refundPaymentRequest struct {
+	Reason *string `json:"reason" validate:"omitempty,max=1000"`
+}
+
+func (h *PaymentHandler) RefundPayment(w http.ResponseWriter, r *http.Request) {
+	id := getOrderIdFromCtx(r.Context())
+
+	// The body is optional; a refund needs no reason.
+	var req refundPaymentRequest
+	if err := readJSON(w, r, &req); err != nil && !errors.Is(err, io.EOF) {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	payment, err := h.paymentService.Refund(r.Context(), id, actor(r), req.Reason)
+	if err != nil {
+		h.paymentError(w, r, err)
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusCreated, payment); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *PaymentHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
+	id := getOrderIdFromCtx(r.Context())
+
+	payments, err := h.paymentService.ListByOrder(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, payments); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+// HandleWebhook takes provider callbacks. The signature covers the raw
+// body, so it is read before anything is decoded.
+func (h *PaymentHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookSize))
+	if err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	err = h.paymentService.HandleWebhook(r.Context(), payload, r.Header.Get(paymentSignatureHeader))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidSignature):
+			unauthorizedResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrPaymentAmountMismatch):
+			badRequestResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PaymentHandler) paymentError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		notFoundResponse(w, r, err, h.logger)
+	case errors.Is(err, domain.ErrPaymentDeclined):
+		paymentRequiredResponse(w, r, err, h.logger)
+	case errors.Is(err, domain.ErrOrderNotPayable),
+		errors.Is(err, domain.ErrNoAuthorization),
+		errors.Is(err, domain.ErrInvalidTransition),
+		errors.Is(err, domain.ErrConflict):
+		conflictResponse(w, r, err, h.logger)
+	default:
+		internalServerError(w, r, err, h.logger)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"go.uber.org/zap"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type productIdKey string
@@ -44,19 +45,24 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if notModified(w, r, productETag(product), productLastModified(product)) {
+		return
+	}
+
 	if err = jsonResponse(w, http.StatusOK, product); err != nil {
 		internalServerError(w, r, err, h.logger)
 	}
 }
 
 type createProductRequest struct {
-	Name        string   `json:"name" validate:"required,min=6,max=255"`
-	Description *string  `json:"description" validate:"omitempty,min=32,max=1000"`
-	Stock       int64    `json:"stock" validate:"required,min=0"`
-	Price       float64  `json:"price" validate:"required,min=1"`
-	SalePrice   *float64 `json:"sale_price" validate:"omitempty,min=1"`
-	CategoryID  int64    `json:"category_id" validate:"required,min=1"`
-	BrandID     int64    `json:"brand_id" validate:"required,min=1"`
+	Name        string         `json:"name" validate:"required,min=6,max=255"`
+	Description *string        `json:"description" validate:"omitempty,min=32,max=1000"`
+	Stock       int64          `json:"stock" validate:"min=0"`
+	Price       domain.Money   `json:"price" validate:"required,min=100"`
+	SalePrice   *domain.Money  `json:"sale_price" validate:"omitempty,min=100"`
+	CategoryID  int64          `json:"category_id" validate:"required,min=1"`
+	BrandID     int64          `json:"brand_id" validate:"required,min=1"`
+	Attributes  map[string]any `json:"attributes"`
 }
 
 func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
@@ -81,25 +87,42 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 			BrandId:    req.BrandID,
 		},
 		Description: req.Description,
+		Attributes:  attributesFromRequest(req.Attributes),
 	}
 
 	if err := h.productService.Create(r.Context(), product); err != nil {
-		internalServerError(w, r, err, h.logger)
+		switch {
+		case errors.Is(err, domain.ErrCurrencyMismatch), errors.Is(err, domain.ErrInvalidAttribute):
+			badRequestResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(product.Version))
 	if err := jsonResponse(w, http.StatusCreated, product); err != nil {
 		internalServerError(w, r, err, h.logger)
 	}
 }
 
+// DeleteProduct honours If-Match, refusing with 412 and the current
+// product when it has changed since the client read it.
 func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	id := getProductIdFromCtx(r.Context())
 
-	if err := h.productService.Delete(r.Context(), id); err != nil {
+	version, ok := h.ifMatchVersion(r)
+	if !ok {
+		preconditionRequiredResponse(w, r, errIfMatchRequired, h.logger)
+		return
+	}
+
+	if err := h.productService.Delete(r.Context(), id, version); err != nil {
 		switch {
 		case errors.Is(err, domain.ErrNotFound):
 			notFoundResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrVersionMismatch):
+			h.preconditionFailed(w, r, id, err)
 		default:
 			internalServerError(w, r, err, h.logger)
 		}
@@ -112,18 +135,27 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 }
 
 type updateProductRequest struct {
-	Name        string   `json:"name" validate:"min=6,max=255"`
-	Description *string  `json:"description" validate:"omitempty,required,min=32,max=1000"`
-	Stock       int64    `json:"stock" validate:"required,min=0"`
-	Price       float64  `json:"price" validate:"required,min=1"`
-	SalePrice   *float64 `json:"sale_price" validate:"omitempty,min=1"`
-	CategoryID  int64    `json:"category_id" validate:"required,min=1"`
-	BrandID     int64    `json:"brand_id" validate:"required,min=1"`
+	Name        string         `json:"name" validate:"min=6,max=255"`
+	Description *string        `json:"description" validate:"omitempty,required,min=32,max=1000"`
+	Stock       int64          `json:"stock" validate:"min=0"`
+	Price       domain.Money   `json:"price" validate:"required,min=100"`
+	SalePrice   *domain.Money  `json:"sale_price" validate:"omitempty,min=100"`
+	CategoryID  int64          `json:"category_id" validate:"required,min=1"`
+	BrandID     int64          `json:"brand_id" validate:"required,min=1"`
+	Attributes  map[string]any `json:"attributes"`
 }
 
+// UpdateProduct honours If-Match like DeleteProduct, so two people editing
+// the same product can't silently overwrite each other.
 func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	id := getProductIdFromCtx(r.Context())
 
+	version, ok := h.ifMatchVersion(r)
+	if !ok {
+		preconditionRequiredResponse(w, r, errIfMatchRequired, h.logger)
+		return
+	}
+
 	var req updateProductRequest
 	if err := readJSON(w, r, &req); err != nil {
 		badRequestResponse(w, r, err, h.logger)
@@ -146,23 +178,66 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 			BrandId:    req.BrandID,
 		},
 		Description: req.Description,
+		Attributes:  attributesFromRequest(req.Attributes),
+		Version:     version,
 	}
 
 	if err := h.productService.Update(r.Context(), product); err != nil {
 		switch {
 		case errors.Is(err, domain.ErrNotFound):
 			notFoundResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrVersionMismatch):
+			h.preconditionFailed(w, r, id, err)
+		case errors.Is(err, domain.ErrCurrencyMismatch), errors.Is(err, domain.ErrInvalidAttribute):
+			badRequestResponse(w, r, err, h.logger)
 		default:
 			internalServerError(w, r, err, h.logger)
 		}
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(product.Version))
 	if err := jsonResponse(w, http.StatusOK, product); err != nil {
 		internalServerError(w, r, err, h.logger)
 	}
 }
 
+// ifMatchVersion reads the product version a write is conditional on. No
+// If-Match, or "*", means any version (0); ok is false when the header is
+// missing but config requires it.
+func (h *ProductHandler) ifMatchVersion(r *http.Request) (version int64, ok bool) {
+	switch header := r.Header.Get("If-Match"); header {
+	case "":
+		return 0, !h.config.Http.RequireIfMatch
+	case "*":
+		return 0, true
+	default:
+		return parseVersionETag(header), true
+	}
+}
+
+// preconditionFailed answers a write based on a stale version with the
+// product as it is now, so the client can reapply its change.
+func (h *ProductHandler) preconditionFailed(w http.ResponseWriter, r *http.Request, id int64, cause error) {
+	h.logger.Warnw("precondition failed response", "path", r.URL.Path, "method", r.Method, "error", cause.Error())
+
+	product, err := h.productService.GetById(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	w.Header().Set("ETag", productETag(product))
+	if err = jsonResponse(w, http.StatusPreconditionFailed, product); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
 func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 	query := domain.PaginatedProductsQuery{
 		Offset:        0,
@@ -170,6 +245,7 @@ func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 		Search:        "",
 		SortField:     "name",
 		SortDirection: "desc",
+		Pagination:    domain.PaginationOffset,
 	}
 
 	query, err := query.Parse(r)
@@ -178,23 +254,51 @@ func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Searches are ranked by relevance unless the client chose an order,
+	// either directly or through the cursor it is paging with.
+	if query.Search != "" && query.Cursor == nil && r.URL.Query().Get("sort_field") == "" {
+		query.SortField = "relevance"
+	}
+
 	if err = validate.Struct(query); err != nil {
 		badRequestResponse(w, r, err, h.logger)
 		return
 	}
 
-	products, meta, err := h.productService.List(r.Context(), query)
+	var products []domain.ProductSummary
+	var meta any
+	if query.Pagination == domain.PaginationCursor {
+		products, meta, err = h.productService.ListByCursor(r.Context(), query)
+	} else {
+		products, meta, err = h.productService.List(r.Context(), query)
+	}
 	if err != nil {
 		internalServerError(w, r, err, h.logger)
 		return
 	}
 
+	var facets *domain.Facets
+	if len(query.Facets) > 0 {
+		f, err := h.productService.Facets(r.Context(), query)
+		if err != nil {
+			internalServerError(w, r, err, h.logger)
+			return
+		}
+		facets = &f
+	}
+
 	productsWithMeta := struct {
-		Meta     domain.Meta             `json:"meta"`
+		Meta     any                     `json:"meta"`
 		Products []domain.ProductSummary `json:"products"`
+		Facets   *domain.Facets          `json:"facets,omitempty"`
 	}{
 		Meta:     meta,
 		Products: products,
+		Facets:   facets,
+	}
+
+	if notModified(w, r, listETag(products, meta, facets), time.Time{}) {
+		return
 	}
 
 	if err = jsonResponse(w, http.StatusOK, productsWithMeta); err != nil {
@@ -202,6 +306,17 @@ func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func attributesFromRequest(raw map[string]any) []domain.ProductAttribute {
+	attributes := make([]domain.ProductAttribute, 0, len(raw))
+	for code, value := range raw {
+		attributes = append(attributes, domain.ProductAttribute{
+			Code:  code,
+			Value: value,
+		})
+	}
+	return attributes
+}
+
 func (h *ProductHandler) ProductIdMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		idStr := chi.URLParam(r, "id")
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"io"
+	"mime"
+	"net/http"
+)
+
+const (
+	mergePatchMediaType = "application/merge-patch+json"
+	jsonPatchMediaType  = "application/json-patch+json"
+)
+
+var (
+	errInvalidPatch          = errors.New("invalid patch")
+	errUnsupportedPatchMedia = fmt.Errorf("patch must be %s or %s", mergePatchMediaType, jsonPatchMediaType)
+)
+
+// productDocument is a product as a createProductRequest, the document
+// patches apply to. Prices are always objects here, whatever the money
+// format, so patching one can't lose its currency.
+type productDocument struct {
+	Name        string         `json:"name"`
+	Description *string        `json:"description"`
+	Stock       int64          `json:"stock"`
+	Price       moneyDocument  `json:"price"`
+	SalePrice   *moneyDocument `json:"sale_price"`
+	CategoryID  int64          `json:"category_id"`
+	BrandID     int64          `json:"brand_id"`
+	Attributes  map[string]any `json:"attributes"`
+}
+
+type moneyDocument struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func newProductDocument(product *domain.Product) productDocument {
+	doc := productDocument{
+		Name:        product.Name,
+		Description: product.Description,
+		Stock:       product.Stock,
+		Price:       moneyDocument(product.Price),
+		CategoryID:  product.CategoryId,
+		BrandID:     product.BrandId,
+		Attributes:  make(map[string]any, len(product.Attributes)),
+	}
+
+	if product.SalePrice != nil {
+		salePrice := moneyDocument(*product.SalePrice)
+		doc.SalePrice = &salePrice
+	}
+
+	for _, attribute := range product.Attributes {
+		doc.Attributes[attribute.Code] = attribute.Value
+	}
+
+	return doc
+}
+
+// PatchProduct applies a JSON Merge Patch (RFC 7396) or JSON Patch
+// (RFC 6902) to the product, told apart by Content-Type. The patched
+// product has to pass the same checks as a new one. If-Match is honoured
+// as for UpdateProduct.
+func (h *ProductHandler) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	id := getProductIdFromCtx(r.Context())
+
+	version, ok := h.ifMatchVersion(r)
+	if !ok {
+		preconditionRequiredResponse(w, r, errIfMatchRequired, h.logger)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != mergePatchMediaType && mediaType != jsonPatchMediaType {
+		w.Header().Set("Accept-Patch", mergePatchMediaType+", "+jsonPatchMediaType)
+		unsupportedMediaTypeResponse(w, r, errUnsupportedPatchMedia, h.logger)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1_048_578)
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	product, err := h.productService.Patch(r.Context(), id, version, func(product *domain.Product) error {
+		return applyProductPatch(product, mediaType, patch)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrVersionMismatch):
+			h.preconditionFailed(w, r, id, err)
+		case errors.Is(err, jsonpatch.ErrTestFailed):
+			conflictResponse(w, r, err, h.logger)
+		case errors.Is(err, errInvalidPatch),
+			errors.Is(err, domain.ErrCurrencyMismatch),
+			errors.Is(err, domain.ErrInvalidAttribute):
+			badRequestResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	w.Header().Set("ETag", productETag(product))
+	if err = jsonResponse(w, http.StatusOK, product); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+// applyProductPatch patches product's document and, if the result is a
+// valid createProductRequest, copies it back onto product.
+func applyProductPatch(product *domain.Product, mediaType string, patch []byte) error {
+	original, err := json.Marshal(newProductDocument(product))
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	switch mediaType {
+	case mergePatchMediaType:
+		patched, err = jsonpatch.MergePatch(original, patch)
+	case jsonPatchMediaType:
+		var ops jsonpatch.Patch
+		if ops, err = jsonpatch.DecodePatch(patch); err == nil {
+			patched, err = ops.Apply(original)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errInvalidPatch, err)
+	}
+
+	var req createProductRequest
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&req); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidPatch, err)
+	}
+
+	if err = validate.Struct(&req); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidPatch, err)
+	}
+
+	product.Name = req.Name
+	product.Description = req.Description
+	product.Stock = req.Stock
+	product.Price = req.Price
+	product.SalePrice = req.SalePrice
+	product.CategoryId = req.CategoryID
+	product.BrandId = req.BrandID
+	product.Attributes = attributesFromRequest(req.Attributes)
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newPatchableProduct() *domain.Product {
+	return &domain.Product{
+		BaseProduct: domain.BaseProduct{
+			Id:         1,
+			Name:       "Running shoes",
+			Stock:      12,
+			Price:      domain.Money{Amount: 9999, Currency: "USD"},
+			CategoryId: 2,
+			BrandId:    3,
+		},
+	}
+}
+
+func TestApplyProductPatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		patch     string
+		wantStock int64
+		wantErr   error
+	}{
+		{
+			name:      "should_merge_stock_down_to_zero",
+			mediaType: mergePatchMediaType,
+			patch:     `{"stock":0}`,
+			wantStock: 0,
+		},
+		{
+			name:      "should_replace_stock_with_zero",
+			mediaType: jsonPatchMediaType,
+			patch:     `[{"op":"replace","path":"/stock","value":0}]`,
+			wantStock: 0,
+		},
+		{
+			name:      "should_reject_negative_stock",
+			mediaType: mergePatchMediaType,
+			patch:     `{"stock":-1}`,
+			wantStock: 12,
+			wantErr:   errInvalidPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := newPatchableProduct()
+
+			err := applyProductPatch(product, tt.mediaType, []byte(tt.patch))
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantStock, product.Stock)
+			assert.Equal(t, "Running shoes", product.Name)
+		})
+	}
+}
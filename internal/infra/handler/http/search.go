@@ -0,0 +1,50 @@
+package http
+
+import (
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type SearchHandler struct {
+	config        *config.Config
+	logger        *zap.SugaredLogger
+	searchService port.SearchService
+}
+
+func NewSearchHandler(config *config.Config, logger *zap.SugaredLogger, searchService port.SearchService) *SearchHandler {
+	return &SearchHandler{
+		config:        config,
+		logger:        logger,
+		searchService: searchService,
+	}
+}
+
+func (h *SearchHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	query := domain.SuggestQuery{
+		Limit: 5,
+	}
+
+	query, err := query.Parse(r)
+	if err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err = validate.Struct(query); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	suggestions, err := h.searchService.Suggest(r.Context(), query)
+	if err != nil {
+		internalServerError(w, r, err, h.logger)
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, suggestions); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
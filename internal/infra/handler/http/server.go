@@ -3,12 +3,17 @@ package http
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
 	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
 	"go.uber.org/zap"
 	"net/http"
 	"time"
 )
 
+// mediaPath is where local storage is served; STORAGE_PUBLIC_URL should
+// point at it.
+const mediaPath = "/media/"
+
 type Server struct {
 	config   *config.Config
 	logger   *zap.SugaredLogger
@@ -16,8 +21,23 @@ type Server struct {
 }
 
 type Handlers struct {
-	Health  *HealthHandler
-	Product *ProductHandler
+	Health    *HealthHandler
+	Product   *ProductHandler
+	Brand     *BrandHandler
+	Category  *CategoryHandler
+	Variant   *VariantHandler
+	Attribute *AttributeHandler
+	Search    *SearchHandler
+	Image     *ImageHandler
+	Cart      *CartHandler
+	Order     *OrderHandler
+	Payment   *PaymentHandler
+	Auth      *AuthHandler
+	User      *UserHandler
+	Session   *SessionHandler
+	ApiKey    *ApiKeyHandler
+	Account   *AccountHandler
+	TwoFactor *TwoFactorHandler
 }
 
 func NewServer(config *config.Config, logger *zap.SugaredLogger, handlers *Handlers) *Server {
@@ -37,21 +57,190 @@ func (s *Server) Mount() http.Handler {
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second))
 
+	// Uploaded files are served straight from the local storage directory,
+	// one at a time; its directories aren't listed.
+	r.Handle(mediaPath+"*", http.StripPrefix(mediaPath, http.FileServer(fileOnlyFS{fs: http.Dir(s.config.Storage.LocalDir)})))
+
 	r.Route("/v1", func(r chi.Router) {
+		r.Use(s.handlers.Auth.Authenticate)
+
 		r.Get("/health", s.handlers.Health.CheckHealth)
+		r.Get("/search/suggest", s.handlers.Search.Suggest)
+
+		// Catalog reads are public; changing it takes a catalog manager.
+		manageCatalog := s.handlers.Auth.RequirePermission(domain.PermissionProductsWrite)
 
 		r.Route("/products", func(r chi.Router) {
-			r.Get("/", s.handlers.Product.ListProducts)
-			r.Post("/", s.handlers.Product.CreateProduct)
+			r.With(cacheControl(s.config.Cache.Products)).Get("/", s.handlers.Product.ListProducts)
+			r.With(manageCatalog).Post("/", s.handlers.Product.CreateProduct)
 
 			r.Route("/{id}", func(r chi.Router) {
 				r.Use(s.handlers.Product.ProductIdMiddleware)
 
-				r.Get("/", s.handlers.Product.GetProduct)
-				r.Put("/", s.handlers.Product.UpdateProduct)
-				r.Delete("/", s.handlers.Product.DeleteProduct)
+				r.With(cacheControl(s.config.Cache.Product)).Get("/", s.handlers.Product.GetProduct)
+
+				r.Group(func(r chi.Router) {
+					r.Use(manageCatalog)
+
+					r.Put("/", s.handlers.Product.UpdateProduct)
+					r.Patch("/", s.handlers.Product.PatchProduct)
+					r.Delete("/", s.handlers.Product.DeleteProduct)
+
+					r.Put("/options", s.handlers.Variant.SetOptions)
+
+					r.Route("/images", func(r chi.Router) {
+						r.Post("/", s.handlers.Image.UploadImage)
+						r.Put("/order", s.handlers.Image.ReorderImages)
+
+						r.Route("/{imageId}", func(r chi.Router) {
+							r.Use(s.handlers.Image.ImageIdMiddleware)
+
+							r.Put("/primary", s.handlers.Image.SetPrimaryImage)
+							r.Delete("/", s.handlers.Image.DeleteImage)
+						})
+					})
+
+					r.Route("/variants", func(r chi.Router) {
+						r.Post("/", s.handlers.Variant.CreateVariant)
+
+						r.Route("/{variantId}", func(r chi.Router) {
+							r.Use(s.handlers.Variant.VariantIdMiddleware)
+
+							r.Put("/", s.handlers.Variant.UpdateVariant)
+							r.Delete("/", s.handlers.Variant.DeleteVariant)
+						})
+					})
+				})
+			})
+
+		})
+
+		r.Post("/auth/register", s.handlers.User.Register)
+		r.Post("/auth/login", s.handlers.User.Login)
+		r.Post("/auth/login/2fa", s.handlers.User.CompleteLogin)
+		r.Post("/auth/refresh", s.handlers.Session.Refresh)
+		r.With(s.handlers.Auth.RequireUser).Post("/auth/logout", s.handlers.Session.Logout)
+		r.Post("/auth/password/forgot", s.handlers.Account.ForgotPassword)
+		r.Post("/auth/password/reset", s.handlers.Account.ResetPassword)
+		r.Post("/auth/email/verify", s.handlers.Account.VerifyEmail)
+
+		r.Route("/me", func(r chi.Router) {
+			r.Use(s.handlers.Auth.RequireUser)
+
+			r.Get("/", s.handlers.User.GetMe)
+			r.Put("/", s.handlers.User.UpdateMe)
+			r.Post("/email/verification", s.handlers.Account.ResendVerification)
+
+			r.Route("/2fa", func(r chi.Router) {
+				r.Post("/totp", s.handlers.TwoFactor.Enrol)
+				r.Post("/totp/confirm", s.handlers.TwoFactor.Confirm)
+				r.Delete("/totp", s.handlers.TwoFactor.Disable)
+				r.Post("/recovery-codes", s.handlers.TwoFactor.RegenerateRecoveryCodes)
+			})
+
+			r.Route("/sessions", func(r chi.Router) {
+				r.Get("/", s.handlers.Session.ListSessions)
+				r.Delete("/", s.handlers.Session.RevokeAllSessions)
+
+				r.Route("/{sessionId}", func(r chi.Router) {
+					r.Use(s.handlers.Session.SessionIdMiddleware)
+
+					r.Delete("/", s.handlers.Session.RevokeSession)
+				})
+			})
+		})
+
+		r.Route("/cart", func(r chi.Router) {
+			r.Get("/", s.handlers.Cart.GetCart)
+			r.Post("/items", s.handlers.Cart.AddCartItem)
+
+			r.Route("/items/{itemId}", func(r chi.Router) {
+				r.Use(s.handlers.Cart.CartItemIdMiddleware)
+
+				r.Put("/", s.handlers.Cart.UpdateCartItem)
+				r.Delete("/", s.handlers.Cart.RemoveCartItem)
+			})
+		})
+
+		r.Post("/checkout", s.handlers.Order.Checkout)
+
+		r.Route("/orders", func(r chi.Router) {
+			r.Get("/", s.handlers.Order.ListOrders)
+
+			r.Route("/{id}", func(r chi.Router) {
+				r.Use(s.handlers.Order.OrderIdMiddleware)
+
+				r.Get("/", s.handlers.Order.GetOrder)
+				r.Post("/payments", s.handlers.Payment.AuthorizePayment)
+			})
+		})
+
+		r.Post("/payments/webhook", s.handlers.Payment.HandleWebhook)
+
+		r.Route("/admin/orders/{id}", func(r chi.Router) {
+			r.Use(s.handlers.Auth.RequirePermission(domain.PermissionOrdersManage))
+			r.Use(s.handlers.Order.OrderIdMiddleware)
+
+			r.Get("/", s.handlers.Order.AdminGetOrder)
+			r.Post("/status", s.handlers.Order.TransitionOrder)
+
+			r.Route("/payments", func(r chi.Router) {
+				r.Get("/", s.handlers.Payment.ListPayments)
+				r.Post("/capture", s.handlers.Payment.CapturePayment)
+				r.Post("/void", s.handlers.Payment.VoidPayment)
+				r.Post("/refund", s.handlers.Payment.RefundPayment)
+			})
+		})
+
+		r.Route("/admin/api-keys", func(r chi.Router) {
+			r.Use(s.handlers.Auth.RequireUser)
+			r.Use(s.handlers.Auth.RequirePermission(domain.PermissionApiKeysManage))
+
+			r.Get("/", s.handlers.ApiKey.ListApiKeys)
+			r.Post("/", s.handlers.ApiKey.CreateApiKey)
+
+			r.Route("/{apiKeyId}", func(r chi.Router) {
+				r.Use(s.handlers.ApiKey.ApiKeyIdMiddleware)
+
+				r.Delete("/", s.handlers.ApiKey.RevokeApiKey)
 			})
+		})
 
+		r.Route("/brands", func(r chi.Router) {
+			r.With(cacheControl(s.config.Cache.Brands)).Get("/", s.handlers.Brand.ListBrands)
+			r.With(manageCatalog).Post("/", s.handlers.Brand.CreateBrand)
+
+			r.Route("/{slug}", func(r chi.Router) {
+				r.Use(s.handlers.Brand.BrandSlugMiddleware)
+
+				r.With(cacheControl(s.config.Cache.Brands)).Get("/", s.handlers.Brand.GetBrand)
+				r.With(cacheControl(s.config.Cache.Products)).Get("/products", s.handlers.Brand.ListBrandProducts)
+				r.With(manageCatalog).Put("/", s.handlers.Brand.UpdateBrand)
+				r.With(manageCatalog).Delete("/", s.handlers.Brand.DeleteBrand)
+			})
+		})
+
+		r.Route("/categories", func(r chi.Router) {
+			r.With(cacheControl(s.config.Cache.Categories)).Get("/", s.handlers.Category.GetCategoryTree)
+			r.With(manageCatalog).Post("/", s.handlers.Category.CreateCategory)
+
+			r.Route("/{slug}", func(r chi.Router) {
+				r.Use(s.handlers.Category.CategorySlugMiddleware)
+
+				r.With(cacheControl(s.config.Cache.Categories)).Get("/", s.handlers.Category.GetCategory)
+				r.With(cacheControl(s.config.Cache.Categories)).Get("/attributes", s.handlers.Attribute.ListAttributes)
+
+				r.Group(func(r chi.Router) {
+					r.Use(manageCatalog)
+
+					r.Put("/", s.handlers.Category.UpdateCategory)
+					r.Put("/parent", s.handlers.Category.MoveCategory)
+					r.Delete("/", s.handlers.Category.DeactivateCategory)
+
+					r.Post("/attributes", s.handlers.Attribute.CreateAttribute)
+					r.Delete("/attributes/{code}", s.handlers.Attribute.DeleteAttribute)
+				})
+			})
 		})
 	})
 
@@ -0,0 +1,168 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+type sessionIdKey string
+
+const sessionIdCtx sessionIdKey = "sessionId"
+
+// maxUserAgentLength matches the sessions.user_agent column.
+const maxUserAgentLength = 512
+
+type SessionHandler struct {
+	config         *config.Config
+	logger         *zap.SugaredLogger
+	sessionService port.SessionService
+}
+
+func NewSessionHandler(config *config.Config, logger *zap.SugaredLogger, sessionService port.SessionService) *SessionHandler {
+	return &SessionHandler{
+		config:         config,
+		logger:         logger,
+		sessionService: sessionService,
+	}
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required,max=128"`
+}
+
+func (h *SessionHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	tokens, err := h.sessionService.Refresh(r.Context(), req.RefreshToken, sessionClient(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidToken), errors.Is(err, domain.ErrRefreshTokenReused):
+			unauthorizedResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, tokens); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+// Logout ends the session the request's access token belongs to.
+func (h *SessionHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	principal := getPrincipalFromCtx(r.Context())
+
+	err := h.sessionService.Revoke(r.Context(), principal.UserId, principal.SessionId)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		internalServerError(w, r, err, h.logger)
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.sessionService.List(r.Context(), getPrincipalFromCtx(r.Context()))
+	if err != nil {
+		internalServerError(w, r, err, h.logger)
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, sessions); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	principal := getPrincipalFromCtx(r.Context())
+	id := getSessionIdFromCtx(r.Context())
+
+	if err := h.sessionService.Revoke(r.Context(), principal.UserId, id); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+// RevokeAllSessions logs the user out everywhere, this device included.
+func (h *SessionHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	principal := getPrincipalFromCtx(r.Context())
+
+	if _, err := h.sessionService.RevokeAll(r.Context(), principal.UserId); err != nil {
+		internalServerError(w, r, err, h.logger)
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *SessionHandler) SessionIdMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idStr := chi.URLParam(r, "sessionId")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			badRequestResponse(w, r, err, h.logger)
+			return
+		}
+
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, sessionIdCtx, id)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getSessionIdFromCtx(ctx context.Context) int64 {
+	val := ctx.Value(sessionIdCtx)
+	if val == nil {
+		return 0
+	}
+	return val.(int64)
+}
+
+// sessionClient describes the device behind a request. The address comes
+// from middleware.RealIP, which leaves a bare IP when a proxy header was
+// present and host:port otherwise.
+func sessionClient(r *http.Request) domain.SessionClient {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	return domain.SessionClient{UserAgent: userAgent, Ip: ip}
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type TwoFactorHandler struct {
+	config           *config.Config
+	logger           *zap.SugaredLogger
+	twoFactorService port.TwoFactorService
+}
+
+func NewTwoFactorHandler(config *config.Config, logger *zap.SugaredLogger, twoFactorService port.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		config:           config,
+		logger:           logger,
+		twoFactorService: twoFactorService,
+	}
+}
+
+// twoFactorCodeRequest takes a code from the authenticator app or, where a
+// recovery code would do, one of those.
+type twoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Enrol returns a new secret and the otpauth URI to render as a QR code.
+func (h *TwoFactorHandler) Enrol(w http.ResponseWriter, r *http.Request) {
+	principal := getPrincipalFromCtx(r.Context())
+
+	provisioning, err := h.twoFactorService.Enrol(r.Context(), principal.UserId)
+	if err != nil {
+		h.twoFactorError(w, r, err)
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusCreated, provisioning); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *TwoFactorHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	principal := getPrincipalFromCtx(r.Context())
+
+	var req twoFactorCodeRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	codes, err := h.twoFactorService.Confirm(r.Context(), principal.UserId, req.Code)
+	if err != nil {
+		h.twoFactorError(w, r, err)
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, &recoveryCodesResponse{codes}); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	principal := getPrincipalFromCtx(r.Context())
+
+	var req twoFactorCodeRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := h.twoFactorService.Disable(r.Context(), principal.UserId, req.Code); err != nil {
+		h.twoFactorError(w, r, err)
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	principal := getPrincipalFromCtx(r.Context())
+
+	var req twoFactorCodeRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(r.Context(), principal.UserId, req.Code)
+	if err != nil {
+		h.twoFactorError(w, r, err)
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, &recoveryCodesResponse{codes}); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *TwoFactorHandler) twoFactorError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidTwoFactorCode):
+		badRequestResponse(w, r, err, h.logger)
+	case errors.Is(err, domain.ErrTwoFactorRequired):
+		forbiddenResponse(w, r, err, h.logger)
+	case errors.Is(err, domain.ErrTwoFactorNotEnrolled), errors.Is(err, domain.ErrTwoFactorAlreadyActive):
+		conflictResponse(w, r, err, h.logger)
+	case errors.Is(err, domain.ErrNotFound):
+		notFoundResponse(w, r, err, h.logger)
+	default:
+		internalServerError(w, r, err, h.logger)
+	}
+}
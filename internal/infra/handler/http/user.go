@@ -0,0 +1,215 @@
+package http
+
+import (
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type UserHandler struct {
+	config      *config.Config
+	logger      *zap.SugaredLogger
+	userService port.UserService
+	cartService port.CartService
+}
+
+func NewUserHandler(config *config.Config, logger *zap.SugaredLogger, userService port.UserService, cartService port.CartService) *UserHandler {
+	return &UserHandler{
+		config:      config,
+		logger:      logger,
+		userService: userService,
+		cartService: cartService,
+	}
+}
+
+// bcrypt refuses passwords over 72 bytes.
+type registerRequest struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Name     string `json:"name" validate:"required,min=2,max=255"`
+	Password string `json:"password" validate:"required,min=8,maxbytes=72"`
+}
+
+func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var req registerRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	user, err := h.userService.Register(r.Context(), req.Email, req.Name, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrConflict):
+			conflictResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusCreated, user); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type loginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,maxbytes=72"`
+}
+
+// twoFactorChallengeResponse is what Login answers with instead of tokens
+// when the account has two-factor authentication.
+type twoFactorChallengeResponse struct {
+	TwoFactorRequired bool `json:"two_factor_required"`
+	*domain.TwoFactorChallenge
+}
+
+// Login exchanges credentials for an access and refresh token pair. A
+// visitor's cart, sent along as usual, is merged into the customer's.
+// Accounts with two-factor authentication get a challenge instead,
+// answered at CompleteLogin.
+func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	user, result, err := h.userService.Login(r.Context(), req.Email, req.Password, sessionClient(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidCredentials):
+			unauthorizedResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if result.Challenge != nil {
+		if err = jsonResponse(w, http.StatusOK, &twoFactorChallengeResponse{true, result.Challenge}); err != nil {
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	h.signedIn(w, r, user, result.Tokens)
+}
+
+type completeLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required,max=128"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
+
+// CompleteLogin takes the challenge from Login with a code from the user's
+// authenticator app, or a recovery code, and signs them in.
+func (h *UserHandler) CompleteLogin(w http.ResponseWriter, r *http.Request) {
+	var req completeLoginRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	user, tokens, err := h.userService.CompleteLogin(r.Context(), req.ChallengeToken, req.Code, sessionClient(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidToken),
+			errors.Is(err, domain.ErrInvalidTwoFactorCode),
+			errors.Is(err, domain.ErrTwoFactorNotEnrolled):
+			unauthorizedResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	h.signedIn(w, r, user, tokens)
+}
+
+func (h *UserHandler) signedIn(w http.ResponseWriter, r *http.Request, user *domain.User, tokens *domain.AuthTokens) {
+	if cartToken := r.Header.Get(cartTokenHeader); cartToken != "" {
+		if _, err := h.cartService.Merge(r.Context(), cartToken, user.Id); err != nil {
+			// Signing in matters more than the cart; the visitor's cart
+			// stays reachable by its token.
+			h.logger.Errorw("merging cart on login failed", "user", user.Id, "error", err.Error())
+		}
+	}
+
+	if err := jsonResponse(w, http.StatusOK, tokens); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	principal := getPrincipalFromCtx(r.Context())
+
+	user, err := h.userService.GetById(r.Context(), principal.UserId)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, user); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type updateMeRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+	Name  string `json:"name" validate:"required,min=2,max=255"`
+}
+
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	principal := getPrincipalFromCtx(r.Context())
+
+	var req updateMeRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(r.Context(), principal.UserId, req.Email, req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrConflict):
+			conflictResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err = jsonResponse(w, http.StatusOK, user); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/app/port"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/config"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type variantIdKey string
+
+const variantIdCtx variantIdKey = "variantId"
+
+type VariantHandler struct {
+	config         *config.Config
+	logger         *zap.SugaredLogger
+	variantService port.VariantService
+}
+
+func NewVariantHandler(config *config.Config, logger *zap.SugaredLogger, variantService port.VariantService) *VariantHandler {
+	return &VariantHandler{
+		config:         config,
+		logger:         logger,
+		variantService: variantService,
+	}
+}
+
+type productOptionRequest struct {
+	Name   string   `json:"name" validate:"required,min=1,max=64"`
+	Values []string `json:"values" validate:"required,min=1,dive,required,max=64"`
+}
+
+type setOptionsRequest struct {
+	Options []productOptionRequest `json:"options" validate:"max=3,dive"`
+}
+
+func (h *VariantHandler) SetOptions(w http.ResponseWriter, r *http.Request) {
+	productId := getProductIdFromCtx(r.Context())
+
+	var req setOptionsRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	options := make([]domain.ProductOption, 0, len(req.Options))
+	for _, option := range req.Options {
+		options = append(options, domain.ProductOption{
+			Name:   option.Name,
+			Values: option.Values,
+		})
+	}
+
+	if err := h.variantService.SetOptions(r.Context(), productId, options); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		case errors.Is(err, domain.ErrInvalidVariantOptions):
+			badRequestResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusOK, options); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+type variantRequest struct {
+	Sku     string            `json:"sku" validate:"required,min=1,max=64"`
+	Barcode *string           `json:"barcode" validate:"omitempty,min=8,max=64"`
+	Price   *domain.Money     `json:"price" validate:"omitempty,min=100"`
+	Stock   int64             `json:"stock" validate:"min=0"`
+	Options map[string]string `json:"options" validate:"required"`
+}
+
+func (h *VariantHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	productId := getProductIdFromCtx(r.Context())
+
+	var req variantRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	variant := &domain.ProductVariant{
+		ProductId: productId,
+		Sku:       req.Sku,
+		Barcode:   req.Barcode,
+		Price:     req.Price,
+		Stock:     req.Stock,
+		Options:   req.Options,
+	}
+
+	if err := h.variantService.Create(r.Context(), variant); err != nil {
+		h.handleWriteError(w, r, err)
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusCreated, variant); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *VariantHandler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	productId := getProductIdFromCtx(r.Context())
+	id := getVariantIdFromCtx(r.Context())
+
+	var req variantRequest
+	if err := readJSON(w, r, &req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	if err := validate.Struct(&req); err != nil {
+		badRequestResponse(w, r, err, h.logger)
+		return
+	}
+
+	variant := &domain.ProductVariant{
+		Id:        id,
+		ProductId: productId,
+		Sku:       req.Sku,
+		Barcode:   req.Barcode,
+		Price:     req.Price,
+		Stock:     req.Stock,
+		Options:   req.Options,
+	}
+
+	if err := h.variantService.Update(r.Context(), variant); err != nil {
+		h.handleWriteError(w, r, err)
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusOK, variant); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *VariantHandler) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	productId := getProductIdFromCtx(r.Context())
+	id := getVariantIdFromCtx(r.Context())
+
+	if err := h.variantService.Delete(r.Context(), productId, id); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			notFoundResponse(w, r, err, h.logger)
+		default:
+			internalServerError(w, r, err, h.logger)
+		}
+		return
+	}
+
+	if err := jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *VariantHandler) handleWriteError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		notFoundResponse(w, r, err, h.logger)
+	case errors.Is(err, domain.ErrInvalidVariantOptions), errors.Is(err, domain.ErrCurrencyMismatch):
+		badRequestResponse(w, r, err, h.logger)
+	case errors.Is(err, domain.ErrConflict):
+		conflictResponse(w, r, err, h.logger)
+	default:
+		internalServerError(w, r, err, h.logger)
+	}
+}
+
+func (h *VariantHandler) VariantIdMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idStr := chi.URLParam(r, "variantId")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			badRequestResponse(w, r, err, h.logger)
+			return
+		}
+
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, variantIdCtx, id)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getVariantIdFromCtx(ctx context.Context) int64 {
+	val := ctx.Value(variantIdCtx)
+	if val == nil {
+		return 0
+	}
+	return val.(int64)
+}
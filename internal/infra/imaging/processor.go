@@ -0,0 +1,104 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"golang.org/x/image/draw"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+// supportedTypes are the content types accepted for upload. The type is
+// sniffed from the file itself rather than trusted from the request.
+var supportedTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+const jpegQuality = 85
+
+// maxPixels caps width × height. Decoded images take about four bytes a
+// pixel whatever their file size, so a small, highly compressed upload
+// could otherwise claim gigabytes.
+const maxPixels = 50_000_000
+
+type Processor struct{}
+
+func NewProcessor() *Processor {
+	return &Processor{}
+}
+
+// Process validates an uploaded image and renders a thumbnail for each
+// size. Images are never scaled up, so small originals yield thumbnails
+// at their own size. PNGs keep their transparency; everything else is
+// re-encoded as JPEG.
+func (p *Processor) Process(data []byte, sizes []domain.ThumbnailSize) (*domain.ProcessedImage, error) {
+	mtype := mimetype.Detect(data)
+	if !mimetype.EqualsAny(mtype.String(), supportedTypes...) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnsupportedImage, mtype.String())
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrUnsupportedImage, err)
+	}
+	if int64(config.Width)*int64(config.Height) > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", domain.ErrImageTooLarge, config.Width, config.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrUnsupportedImage, err)
+	}
+
+	bounds := src.Bounds()
+	processed := &domain.ProcessedImage{
+		MimeType:  mtype.String(),
+		Extension: mtype.Extension(),
+		Width:     bounds.Dx(),
+		Height:    bounds.Dy(),
+	}
+
+	for _, size := range sizes {
+		thumbnail := domain.Thumbnail{Size: size.Name}
+
+		var buf bytes.Buffer
+		scaled := scale(src, size.Width)
+		if mtype.Is("image/png") {
+			err = png.Encode(&buf, scaled)
+			thumbnail.MimeType, thumbnail.Extension = "image/png", ".png"
+		} else {
+			err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: jpegQuality})
+			thumbnail.MimeType, thumbnail.Extension = "image/jpeg", ".jpg"
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		thumbnail.Data = buf.Bytes()
+		processed.Thumbnails = append(processed.Thumbnails, thumbnail)
+	}
+
+	return processed, nil
+}
+
+// scale fits src to width, preserving its aspect ratio.
+func scale(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	if bounds.Dx() <= width {
+		width = bounds.Dx()
+	}
+
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	return dst
+}
@@ -0,0 +1,69 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader is just the signature and header chunk of a PNG, enough for its
+// dimensions to be read without any pixels behind them.
+func pngHeader(width, height uint32) []byte {
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	ihdr = append(ihdr, 8, 6, 0, 0, 0)
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, uint32(len(ihdr)-4))
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestProcess(t *testing.T) {
+	sizes := []domain.ThumbnailSize{{Name: "small", Width: 100}, {Name: "large", Width: 1000}}
+
+	t.Run("should_scale_thumbnails_without_upscaling", func(t *testing.T) {
+		processed, err := NewProcessor().Process(encodePNG(t, 400, 200), sizes)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", processed.MimeType)
+		assert.Equal(t, 400, processed.Width)
+		assert.Len(t, processed.Thumbnails, 2)
+
+		small, err := png.Decode(bytes.NewReader(processed.Thumbnails[0].Data))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Pt(100, 50), small.Bounds().Size())
+
+		large, err := png.Decode(bytes.NewReader(processed.Thumbnails[1].Data))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Pt(400, 200), large.Bounds().Size())
+	})
+
+	t.Run("should_reject_oversized_dimensions_before_decoding", func(t *testing.T) {
+		_, err := NewProcessor().Process(pngHeader(10_000, 5_001), sizes)
+
+		assert.ErrorIs(t, err, domain.ErrImageTooLarge)
+	})
+
+	t.Run("should_reject_non_images", func(t *testing.T) {
+		_, err := NewProcessor().Process([]byte("<html><body>not an image</body></html>"), sizes)
+
+		assert.ErrorIs(t, err, domain.ErrUnsupportedImage)
+	})
+}
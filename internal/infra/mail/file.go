@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileTransport writes each email to dir as an .eml file for local
+// development, where opening the file stands in for a mailbox. Without a
+// dir the email is only logged.
+type FileTransport struct {
+	dir    string
+	from   string
+	logger *zap.SugaredLogger
+}
+
+func NewFileTransport(dir, from string, logger *zap.SugaredLogger) *FileTransport {
+	return &FileTransport{
+		dir:    dir,
+		from:   from,
+		logger: logger,
+	}
+}
+
+func (t *FileTransport) Send(ctx context.Context, email domain.Email) error {
+	if t.dir == "" {
+		t.logger.Infow("email", "to", email.To, "subject", email.Subject, "body", email.Body)
+		return nil
+	}
+
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%d.eml", time.Now().UTC().Format("20060102T150405"), email.Id)
+	path := filepath.Join(t.dir, name)
+	if err := os.WriteFile(path, message(t.from, email), 0o644); err != nil {
+		return err
+	}
+
+	t.logger.Infow("email written", "to", email.To, "subject", email.Subject, "path", path)
+	return nil
+}
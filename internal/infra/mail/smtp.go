@@ -0,0 +1,66 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPTransport delivers email through an SMTP relay, upgrading to TLS
+// when the server offers it.
+type SMTPTransport struct {
+	addr     string
+	host     string
+	from     string
+	username string
+	password string
+}
+
+func NewSMTPTransport(host string, port int, username, password, from string) *SMTPTransport {
+	return &SMTPTransport{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		from:     from,
+		username: username,
+		password: password,
+	}
+}
+
+func (t *SMTPTransport) Send(ctx context.Context, email domain.Email) error {
+	var auth smtp.Auth
+	if t.username != "" {
+		auth = smtp.PlainAuth("", t.username, t.password, t.host)
+	}
+
+	// net/smtp takes no context; run it aside so a hung server can't hold
+	// the sender past its deadline.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(t.addr, auth, t.from, []string{email.To}, message(t.from, email))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func message(from string, email domain.Email) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", email.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&b, "\r\n%s", email.Body)
+	return b.Bytes()
+}
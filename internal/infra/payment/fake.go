@@ -0,0 +1,167 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"sync"
+)
+
+const FakeProvider = "fake"
+
+// declinedCents makes amounts ending in .13 fail, so declines can be tried
+// out without a real card.
+const declinedCents = 13
+
+type fakePayment struct {
+	amount   domain.Money
+	captured bool
+	voided   bool
+	refunded int64
+}
+
+// FakeGateway is an in-memory payment provider for development and tests.
+// It approves everything except amounts ending in .13 and signs webhooks
+// with HMAC-SHA256 like a real provider would.
+type FakeGateway struct {
+	mu       sync.Mutex
+	secret   []byte
+	payments map[string]*fakePayment
+}
+
+func NewFakeGateway(webhookSecret string) *FakeGateway {
+	return &FakeGateway{
+		secret:   []byte(webhookSecret),
+		payments: map[string]*fakePayment{},
+	}
+}
+
+func (g *FakeGateway) Provider() string {
+	return FakeProvider
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, orderId int64, amount domain.Money) (*domain.PaymentResult, error) {
+	reference, err := newReference()
+	if err != nil {
+		return nil, err
+	}
+
+	if amount.Amount%100 == declinedCents {
+		return declined(reference, "card declined"), nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.payments[reference] = &fakePayment{amount: amount}
+	return succeeded(reference), nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, reference string, amount domain.Money) (*domain.PaymentResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment, ok := g.payments[reference]
+	switch {
+	case !ok:
+		return declined(reference, "unknown authorization"), nil
+	case payment.voided:
+		return declined(reference, "authorization was voided"), nil
+	case payment.amount.LessThan(amount):
+		return declined(reference, "amount exceeds authorization"), nil
+	}
+
+	payment.captured = true
+	return succeeded(reference), nil
+}
+
+func (g *FakeGateway) Void(ctx context.Context, reference string) (*domain.PaymentResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment, ok := g.payments[reference]
+	switch {
+	case !ok:
+		return declined(reference, "unknown authorization"), nil
+	case payment.captured:
+		return declined(reference, "payment was already captured"), nil
+	}
+
+	payment.voided = true
+	return succeeded(reference), nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, reference string, amount domain.Money) (*domain.PaymentResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment, ok := g.payments[reference]
+	switch {
+	case !ok:
+		return declined(reference, "unknown authorization"), nil
+	case payment.voided:
+		return declined(reference, "authorization was voided"), nil
+	case payment.refunded+amount.Amount > payment.amount.Amount:
+		return declined(reference, "amount exceeds what was paid"), nil
+	}
+
+	payment.refunded += amount.Amount
+	return succeeded(reference), nil
+}
+
+func (g *FakeGateway) ParseWebhook(payload []byte, signature string) (*domain.PaymentEvent, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, g.sign(payload)) {
+		return nil, domain.ErrInvalidSignature
+	}
+
+	var event domain.PaymentEvent
+	if err = json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("decoding webhook: %w", err)
+	}
+
+	if event.Type != domain.PaymentEventSucceeded && event.Type != domain.PaymentEventFailed {
+		return nil, fmt.Errorf("unknown webhook event type %q", event.Type)
+	}
+
+	g.mu.Lock()
+	if payment, ok := g.payments[event.Reference]; ok && event.Type == domain.PaymentEventSucceeded {
+		payment.captured = true
+	}
+	g.mu.Unlock()
+
+	return &event, nil
+}
+
+// Sign returns the signature the fake provider would send with payload,
+// for crafting callbacks by hand.
+func (g *FakeGateway) Sign(payload []byte) string {
+	return hex.EncodeToString(g.sign(payload))
+}
+
+func (g *FakeGateway) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func succeeded(reference string) *domain.PaymentResult {
+	return &domain.PaymentResult{Reference: reference, Status: domain.PaymentStatusSucceeded}
+}
+
+func declined(reference, reason string) *domain.PaymentResult {
+	return &domain.PaymentResult{Reference: reference, Status: domain.PaymentStatusFailed, Error: reason}
+}
+
+func newReference() (string, error) {
+	token := make([]byte, 12)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return FakeProvider + "_" + hex.EncodeToString(token), nil
+}
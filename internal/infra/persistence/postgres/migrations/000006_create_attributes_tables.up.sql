@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS attribute_definitions (
+    id BIGSERIAL PRIMARY KEY,
+    category_id BIGINT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    code VARCHAR(64) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    unit VARCHAR(32),
+    allowed_values TEXT[] NOT NULL DEFAULT '{}',
+    is_required BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT attribute_definitions_category_code_unique UNIQUE (category_id, code),
+    CONSTRAINT attribute_definitions_type_check CHECK (type IN ('number', 'enum', 'boolean', 'text'))
+);
+
+CREATE TABLE IF NOT EXISTS product_attribute_values (
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    attribute_id BIGINT NOT NULL REFERENCES attribute_definitions(id) ON DELETE CASCADE,
+    value_text TEXT,
+    value_number NUMERIC,
+    value_boolean BOOLEAN,
+
+    PRIMARY KEY (product_id, attribute_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_attribute_values_attribute_number ON product_attribute_values(attribute_id, value_number);
+CREATE INDEX IF NOT EXISTS idx_product_attribute_values_attribute_text ON product_attribute_values(attribute_id, value_text);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_products_search_vector;
+
+DROP TRIGGER IF EXISTS categories_refresh_products_search_vector_trigger ON categories;
+DROP FUNCTION IF EXISTS categories_refresh_products_search_vector();
+
+DROP TRIGGER IF EXISTS brands_refresh_products_search_vector_trigger ON brands;
+DROP FUNCTION IF EXISTS brands_refresh_products_search_vector();
+
+DROP TRIGGER IF EXISTS products_search_vector_trigger ON products;
+DROP FUNCTION IF EXISTS products_search_vector_update();
+
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
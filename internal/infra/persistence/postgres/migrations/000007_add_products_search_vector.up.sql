@@ -0,0 +1,48 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+CREATE OR REPLACE FUNCTION products_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE((SELECT name FROM brands WHERE id = NEW.brand_id), '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE((SELECT name FROM categories WHERE id = NEW.category_id), '')), 'C') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS products_search_vector_trigger ON products;
+CREATE TRIGGER products_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF name, description, brand_id, category_id ON products
+    FOR EACH ROW EXECUTE FUNCTION products_search_vector_update();
+
+-- Renaming a brand or category changes the text of every product under it.
+CREATE OR REPLACE FUNCTION brands_refresh_products_search_vector() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE products SET name = name WHERE brand_id = NEW.id;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS brands_refresh_products_search_vector_trigger ON brands;
+CREATE TRIGGER brands_refresh_products_search_vector_trigger
+    AFTER UPDATE OF name ON brands
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION brands_refresh_products_search_vector();
+
+CREATE OR REPLACE FUNCTION categories_refresh_products_search_vector() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE products SET name = name WHERE category_id = NEW.id;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS categories_refresh_products_search_vector_trigger ON categories;
+CREATE TRIGGER categories_refresh_products_search_vector_trigger
+    AFTER UPDATE OF name ON categories
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION categories_refresh_products_search_vector();
+
+UPDATE products SET name = name;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING gin (search_vector);
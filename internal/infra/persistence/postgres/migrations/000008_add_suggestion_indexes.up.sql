@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_categories_name ON categories USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_brands_name ON brands USING gin (name gin_trgm_ops);
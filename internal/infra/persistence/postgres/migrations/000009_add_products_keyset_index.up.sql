@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_products_active_name_id ON products (name, id) WHERE is_active = true;
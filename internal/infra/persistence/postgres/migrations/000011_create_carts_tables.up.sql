@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS carts (
+    id BIGSERIAL PRIMARY KEY,
+    token VARCHAR(64),
+    customer_id BIGINT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT carts_token_unique UNIQUE (token),
+    CONSTRAINT carts_customer_unique UNIQUE (customer_id),
+    CONSTRAINT carts_owner_present CHECK (token IS NOT NULL OR customer_id IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_carts_expires_at ON carts(expires_at);
+
+CREATE TABLE IF NOT EXISTS cart_items (
+    id BIGSERIAL PRIMARY KEY,
+    cart_id BIGINT NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant_id BIGINT REFERENCES product_variants(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT cart_items_line_unique UNIQUE NULLS NOT DISTINCT (cart_id, product_id, variant_id),
+    CONSTRAINT cart_items_quantity_positive CHECK (quantity > 0)
+);
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id BIGINT,
+    guest_token VARCHAR(64),
+    status VARCHAR(32) NOT NULL DEFAULT 'pending_payment',
+    currency CHAR(3) NOT NULL,
+    subtotal DECIMAL(12, 2) NOT NULL,
+    total DECIMAL(12, 2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT orders_owner_present CHECK (customer_id IS NOT NULL OR guest_token IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON orders(customer_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_orders_guest_token ON orders(guest_token, created_at);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    product_id BIGINT REFERENCES products(id) ON DELETE SET NULL,
+    variant_id BIGINT REFERENCES product_variants(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    sku VARCHAR(64),
+    unit_price DECIMAL(10, 2) NOT NULL,
+    quantity INTEGER NOT NULL,
+    line_total DECIMAL(12, 2) NOT NULL,
+
+    CONSTRAINT order_items_quantity_positive CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id);
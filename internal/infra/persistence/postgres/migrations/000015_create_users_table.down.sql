@@ -0,0 +1,4 @@
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_customer_id_fkey;
+ALTER TABLE carts DROP CONSTRAINT IF EXISTS carts_customer_id_fkey;
+
+DROP TABLE IF EXISTS users;
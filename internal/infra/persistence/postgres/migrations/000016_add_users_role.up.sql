@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(32) NOT NULL DEFAULT 'customer',
+    ADD CONSTRAINT users_role_valid CHECK (role IN ('customer', 'catalog_manager', 'admin'));
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS recovery_codes;
+DROP TABLE IF EXISTS user_totp;
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS two_factor;
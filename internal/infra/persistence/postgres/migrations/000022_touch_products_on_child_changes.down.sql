@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS product_images_touch_product_trigger ON product_images;
+DROP TRIGGER IF EXISTS product_variants_touch_product_trigger ON product_variants;
+DROP FUNCTION IF EXISTS touch_parent_product();
@@ -0,0 +1,23 @@
+-- A product's representation includes its variants and images, so changing
+-- either changes the product too: its version and updated_at move on, and
+-- with them its ETag and Last-Modified.
+CREATE OR REPLACE FUNCTION touch_parent_product() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE products SET updated_at = NOW(), version = version + 1 WHERE id = OLD.product_id;
+    ELSE
+        UPDATE products SET updated_at = NOW(), version = version + 1 WHERE id = NEW.product_id;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS product_variants_touch_product_trigger ON product_variants;
+CREATE TRIGGER product_variants_touch_product_trigger
+    AFTER INSERT OR UPDATE OR DELETE ON product_variants
+    FOR EACH ROW EXECUTE FUNCTION touch_parent_product();
+
+DROP TRIGGER IF EXISTS product_images_touch_product_trigger ON product_images;
+CREATE TRIGGER product_images_touch_product_trigger
+    AFTER INSERT OR UPDATE OR DELETE ON product_images
+    FOR EACH ROW EXECUTE FUNCTION touch_parent_product();
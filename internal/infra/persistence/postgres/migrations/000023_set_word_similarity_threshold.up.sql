@@ -0,0 +1,8 @@
+-- Product search matches names with the <% operator so the trigram index on
+-- products.name can serve it; the operator takes its cutoff from this
+-- setting. Sessions opened after the migration pick it up.
+DO $$
+BEGIN
+    EXECUTE format('ALTER DATABASE %I SET pg_trgm.word_similarity_threshold = 0.3', current_database());
+END
+$$;
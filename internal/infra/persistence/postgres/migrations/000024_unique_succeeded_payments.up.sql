@@ -0,0 +1,4 @@
+-- A provider reference is authorized, captured, voided or refunded once.
+-- Replayed or concurrent callbacks must not record a second success that
+-- reconciliation would count twice.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_succeeded_reference ON payments(provider, reference, operation) WHERE status = 'succeeded';
@@ -0,0 +1,3 @@
+-- An email verification token proves control of the address it was sent
+-- to, not of whatever address the account has when it is redeemed.
+ALTER TABLE user_tokens ADD COLUMN IF NOT EXISTS email VARCHAR(255);
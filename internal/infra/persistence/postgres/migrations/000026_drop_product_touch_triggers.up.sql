@@ -0,0 +1,6 @@
+-- Variant and image writes took the product row after their own, the
+-- reverse of checkout's order, and could deadlock with it. Repositories now
+-- move the product's version on themselves, locking the product first.
+DROP TRIGGER IF EXISTS product_images_touch_product_trigger ON product_images;
+DROP TRIGGER IF EXISTS product_variants_touch_product_trigger ON product_variants;
+DROP FUNCTION IF EXISTS touch_parent_product();
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS brands_name_unique;
+DROP INDEX IF EXISTS brands_slug_unique;
+ALTER TABLE brands ADD CONSTRAINT brands_slug_unique UNIQUE (slug);
+ALTER TABLE brands ADD CONSTRAINT brands_name_unique UNIQUE (name);
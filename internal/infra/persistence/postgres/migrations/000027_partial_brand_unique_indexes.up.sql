@@ -0,0 +1,6 @@
+-- Deleting a brand only deactivates it, so its name and slug have to be
+-- free for a brand created after it.
+ALTER TABLE brands DROP CONSTRAINT IF EXISTS brands_slug_unique;
+ALTER TABLE brands DROP CONSTRAINT IF EXISTS brands_name_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS brands_slug_unique ON brands(slug) WHERE is_active;
+CREATE UNIQUE INDEX IF NOT EXISTS brands_name_unique ON brands(name) WHERE is_active;
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+)
+
+type ApiKeyRepository struct {
+	db *sql.DB
+}
+
+func NewApiKeyRepository(db *sql.DB) *ApiKeyRepository {
+	return &ApiKeyRepository{
+		db: db,
+	}
+}
+
+func (r *ApiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.ApiKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE prefix = $1;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	key, err := scanApiKey(postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, prefix))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, domain.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+func (r *ApiKeyRepository) List(ctx context.Context) ([]domain.ApiKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		ORDER BY created_at DESC, id DESC;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []domain.ApiKey{}
+	for rows.Next() {
+		key, err := scanApiKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *ApiKeyRepository) Create(ctx context.Context, key *domain.ApiKey) error {
+	query := `
+		INSERT INTO 
+		    api_keys (name, prefix, key_hash, scopes, created_by, expires_at)
+		VALUES 
+		    ($1, $2, $3, $4, $5, $6)
+		RETURNING
+			id, created_at;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	return postgres.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		pq.Array(scopes),
+		key.CreatedBy,
+		key.ExpiresAt,
+	).Scan(&key.Id, &key.CreatedAt)
+}
+
+func (r *ApiKeyRepository) Revoke(ctx context.Context, id int64) error {
+	query := `
+		UPDATE api_keys SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// TouchLastUsed skips the write when the key was used within the last
+// minute, so busy integrations don't turn every request into an UPDATE.
+func (r *ApiKeyRepository) TouchLastUsed(ctx context.Context, id int64) error {
+	query := `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, id)
+	return err
+}
+
+const apiKeyColumns = `
+	id, name, prefix, key_hash, scopes, created_by, last_used_at, expires_at, revoked_at, created_at
+`
+
+func scanApiKey(row rowScanner) (*domain.ApiKey, error) {
+	var key domain.ApiKey
+	var scopes []string
+
+	err := row.Scan(
+		&key.Id,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&scopes),
+		&key.CreatedBy,
+		&key.LastUsedAt,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = make([]domain.Permission, len(scopes))
+	for i, scope := range scopes {
+		key.Scopes[i] = domain.Permission(scope)
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+)
+
+type AttributeRepository struct {
+	db *sql.DB
+}
+
+func NewAttributeRepository(db *sql.DB) *AttributeRepository {
+	return &AttributeRepository{
+		db: db,
+	}
+}
+
+// ListDefinitions returns the attributes defined on the category and its
+// ancestors. When a code is defined more than once, the definition closest
+// to the category wins.
+func (r *AttributeRepository) ListDefinitions(ctx context.Context, categoryId int64) ([]domain.AttributeDefinition, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, 0 AS depth FROM categories WHERE id = $1
+			UNION
+			SELECT c.id, c.parent_id, a.depth + 1 FROM categories c
+			JOIN ancestors a ON c.id = a.parent_id
+		)
+		SELECT id, category_id, code, name, type, unit, allowed_values, is_required FROM (
+			SELECT DISTINCT ON (ad.code)
+				ad.id, ad.category_id, ad.code, ad.name, ad.type, ad.unit, ad.allowed_values, ad.is_required
+			FROM attribute_definitions ad
+			JOIN ancestors a ON ad.category_id = a.id
+			ORDER BY ad.code, a.depth
+		) definitions
+		ORDER BY name;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query, categoryId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	definitions := []domain.AttributeDefinition{}
+	for rows.Next() {
+		var definition domain.AttributeDefinition
+		err = rows.Scan(
+			&definition.Id,
+			&definition.CategoryId,
+			&definition.Code,
+			&definition.Name,
+			&definition.Type,
+			&definition.Unit,
+			pq.Array(&definition.Values),
+			&definition.Required,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		definitions = append(definitions, definition)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return definitions, nil
+}
+
+func (r *AttributeRepository) CreateDefinition(ctx context.Context, definition *domain.AttributeDefinition) error {
+	query := `
+		INSERT INTO 
+		    attribute_definitions (category_id, code, name, type, unit, allowed_values, is_required)
+		VALUES 
+		    ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING
+			id;
+	`
+
+	values := definition.Values
+	if values == nil {
+		values = []string{}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	err := postgres.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		definition.CategoryId,
+		definition.Code,
+		definition.Name,
+		definition.Type,
+		definition.Unit,
+		pq.Array(values),
+		definition.Required,
+	).Scan(&definition.Id)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err, "attribute_definitions_category_code_unique"):
+			return fmt.Errorf("%w: attribute %q is already defined for this category", domain.ErrConflict, definition.Code)
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *AttributeRepository) DeleteDefinition(ctx context.Context, categoryId int64, code string) error {
+	query := `
+		DELETE FROM attribute_definitions WHERE category_id = $1 AND code = $2;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, categoryId, code)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// SetProductValues replaces all attribute values of a product.
+func (r *AttributeRepository) SetProductValues(ctx context.Context, productId int64, attributes []domain.ProductAttribute) error {
+	ids := make([]int64, 0, len(attributes))
+	texts := make([]sql.NullString, 0, len(attributes))
+	numbers := make([]sql.NullFloat64, 0, len(attributes))
+	booleans := make([]sql.NullBool, 0, len(attributes))
+
+	for _, attribute := range attributes {
+		var text sql.NullString
+		var number sql.NullFloat64
+		var boolean sql.NullBool
+
+		switch value := attribute.Value.(type) {
+		case string:
+			text = sql.NullString{String: value, Valid: true}
+		case float64:
+			number = sql.NullFloat64{Float64: value, Valid: true}
+		case bool:
+			boolean = sql.NullBool{Bool: value, Valid: true}
+		default:
+			return fmt.Errorf("unsupported value %v for attribute %s", value, attribute.Code)
+		}
+
+		ids = append(ids, attribute.AttributeId)
+		texts = append(texts, text)
+		numbers = append(numbers, number)
+		booleans = append(booleans, boolean)
+	}
+
+	query := `
+		WITH deleted AS (
+			DELETE FROM product_attribute_values 
+			WHERE product_id = $1 AND attribute_id <> ALL($2::bigint[])
+		)
+		INSERT INTO product_attribute_values (product_id, attribute_id, value_text, value_number, value_boolean)
+		SELECT $1, v.attribute_id, v.value_text, v.value_number, v.value_boolean
+		FROM unnest($2::bigint[], $3::text[], $4::numeric[], $5::boolean[])
+			AS v(attribute_id, value_text, value_number, value_boolean)
+		ON CONFLICT (product_id, attribute_id) DO UPDATE SET
+			value_text = EXCLUDED.value_text,
+			value_number = EXCLUDED.value_number,
+			value_boolean = EXCLUDED.value_boolean;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := postgres.Conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		productId,
+		pq.Array(ids),
+		pq.Array(texts),
+		pq.Array(numbers),
+		pq.Array(booleans),
+	)
+	return err
+}
+
+// listProductAttributes is shared with ProductRepository, which loads
+// attribute values as part of the product aggregate.
+func listProductAttributes(ctx context.Context, db queryer, productId int64) ([]domain.ProductAttribute, error) {
+	query := `
+		SELECT 
+			ad.id, ad.code, ad.name, ad.type, ad.unit, pav.value_text, pav.value_number, pav.value_boolean
+		FROM product_attribute_values pav
+		JOIN attribute_definitions ad ON pav.attribute_id = ad.id
+		WHERE pav.product_id = $1
+		ORDER BY ad.name;
+	`
+
+	rows, err := db.QueryContext(ctx, query, productId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attributes := []domain.ProductAttribute{}
+	for rows.Next() {
+		var attribute domain.ProductAttribute
+		var text sql.NullString
+		var number sql.NullFloat64
+		var boolean sql.NullBool
+
+		err = rows.Scan(
+			&attribute.AttributeId,
+			&attribute.Code,
+			&attribute.Name,
+			&attribute.Type,
+			&attribute.Unit,
+			&text,
+			&number,
+			&boolean,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case text.Valid:
+			attribute.Value = text.String
+		case number.Valid:
+			attribute.Value = number.Float64
+		case boolean.Valid:
+			attribute.Value = boolean.Bool
+		}
+
+		attributes = append(attributes, attribute)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attributes, nil
+}
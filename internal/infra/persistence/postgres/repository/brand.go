@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type BrandRepository struct {
+	db *sql.DB
+}
+
+func NewBrandRepository(db *sql.DB) *BrandRepository {
+	return &BrandRepository{
+		db: db,
+	}
+}
+
+func (r *BrandRepository) GetBySlug(ctx context.Context, slug string) (*domain.Brand, error) {
+	query := `
+		SELECT 
+			id, name, slug, description, logo_url
+		FROM brands
+		WHERE slug = $1 AND is_active = true;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	var brand domain.Brand
+	err := postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, slug).Scan(
+		&brand.Id,
+		&brand.Name,
+		&brand.Slug,
+		&brand.Description,
+		&brand.LogoUrl,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, domain.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &brand, nil
+}
+
+func (r *BrandRepository) Create(ctx context.Context, brand *domain.Brand) error {
+	query := `
+		INSERT INTO 
+		    brands (name, slug, description, logo_url)
+		VALUES 
+		    ($1, $2, $3, $4)
+		RETURNING
+			id;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	err := postgres.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		brand.Name,
+		brand.Slug,
+		brand.Description,
+		brand.LogoUrl,
+	).Scan(&brand.Id)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err, "brands_name_unique"):
+			return fmt.Errorf("%w: brand name %q is already taken", domain.ErrConflict, brand.Name)
+		case isUniqueViolation(err, "brands_slug_unique"):
+			return fmt.Errorf("%w: brand slug %q is already taken", domain.ErrConflict, brand.Slug)
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *BrandRepository) Delete(ctx context.Context, id int64) error {
+	query := `
+		UPDATE brands SET is_active = false, updated_at = NOW() WHERE id = $1 AND is_active = true;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *BrandRepository) Update(ctx context.Context, brand *domain.Brand) error {
+	query := `
+		UPDATE 
+		    brands 
+		SET
+		    name = $1,
+		    slug = $2,
+		    description = $3,
+		    logo_url = $4,
+		    updated_at = NOW()
+		WHERE 
+		    id = $5 AND is_active = true
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		brand.Name,
+		brand.Slug,
+		brand.Description,
+		brand.LogoUrl,
+		brand.Id,
+	)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err, "brands_name_unique"):
+			return fmt.Errorf("%w: brand name %q is already taken", domain.ErrConflict, brand.Name)
+		case isUniqueViolation(err, "brands_slug_unique"):
+			return fmt.Errorf("%w: brand slug %q is already taken", domain.ErrConflict, brand.Slug)
+		default:
+			return err
+		}
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *BrandRepository) SlugExists(ctx context.Context, candidate string) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM brands WHERE slug = $1 AND is_active = true);
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	var exists bool
+	err := postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, candidate).Scan(&exists)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+
+	return exists, nil
+}
+
+func (r *BrandRepository) List(ctx context.Context, q domain.PaginatedBrandsQuery) ([]domain.Brand, domain.Meta, error) {
+	var query strings.Builder
+	query.WriteString(`
+		SELECT 
+			id, name, slug, description, logo_url,
+			COUNT(id) OVER()
+		FROM brands
+		WHERE is_active = true AND name ILIKE '%' || $1 || '%'
+	`)
+
+	params := []any{q.Search}
+	paramIndex := len(params) + 1
+
+	validSortFields := map[string]string{
+		"name": "name",
+	}
+
+	sortField, exists := validSortFields[q.SortField]
+	if !exists {
+		sortField = "name"
+	}
+
+	query.WriteString(" ORDER BY ")
+	query.WriteString(sortField)
+	query.WriteString(" ")
+	query.WriteString(q.SortDirection)
+
+	query.WriteString(" LIMIT $")
+	query.WriteString(strconv.Itoa(paramIndex))
+	params = append(params, q.Limit)
+	paramIndex++
+
+	query.WriteString(" OFFSET $")
+	query.WriteString(strconv.Itoa(paramIndex))
+	params = append(params, q.Offset)
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	var brands []domain.Brand
+	var count int
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query.String(), params...)
+	if err != nil {
+		return nil, domain.Meta{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var brand domain.Brand
+		err = rows.Scan(
+			&brand.Id,
+			&brand.Name,
+			&brand.Slug,
+			&brand.Description,
+			&brand.LogoUrl,
+			&count,
+		)
+		if err != nil {
+			return nil, domain.Meta{}, err
+		}
+
+		brands = append(brands, brand)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, domain.Meta{}, err
+	}
+
+	currentPage := (q.Offset / q.Limit) + 1
+	totalPages := int(math.Ceil(float64(count) / float64(q.Limit)))
+	meta := domain.Meta{
+		TotalItems:  count,
+		CurrentPage: currentPage,
+		PageSize:    q.Limit,
+		TotalPages:  totalPages,
+	}
+
+	return brands, meta, nil
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+	"time"
+)
+
+type CartRepository struct {
+	db *sql.DB
+}
+
+func NewCartRepository(db *sql.DB) *CartRepository {
+	return &CartRepository{
+		db: db,
+	}
+}
+
+// GetByToken returns the anonymous cart for token. Expired carts are
+// treated as gone even before they are purged.
+func (r *CartRepository) GetByToken(ctx context.Context, token string) (*domain.Cart, error) {
+	return r.get(ctx, "token = $1", token)
+}
+
+func (r *CartRepository) GetByCustomer(ctx context.Context, customerId int64) (*domain.Cart, error) {
+	return r.get(ctx, "customer_id = $1", customerId)
+}
+
+func (r *CartRepository) get(ctx context.Context, condition string, arg any) (*domain.Cart, error) {
+	query := `
+		SELECT id, token, customer_id, expires_at, created_at, updated_at
+		FROM carts
+		WHERE ` + condition + ` AND expires_at > NOW();
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	var cart domain.Cart
+	err := postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, arg).Scan(
+		&cart.Id,
+		&cart.Token,
+		&cart.CustomerId,
+		&cart.ExpiresAt,
+		&cart.CreatedAt,
+		&cart.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, domain.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	cart.Items, err = listCartItems(ctx, postgres.Conn(ctx, r.db), cart.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cart.Recalculate(); err != nil {
+		return nil, err
+	}
+
+	return &cart, nil
+}
+
+func (r *CartRepository) Create(ctx context.Context, cart *domain.Cart) error {
+	query := `
+		INSERT INTO 
+		    carts (token, customer_id, expires_at)
+		VALUES 
+		    ($1, $2, $3)
+		ON CONFLICT (customer_id) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		RETURNING
+			id, created_at, updated_at;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	return postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, cart.Token, cart.CustomerId, cart.ExpiresAt).Scan(
+		&cart.Id,
+		&cart.CreatedAt,
+		&cart.UpdatedAt,
+	)
+}
+
+func (r *CartRepository) Touch(ctx context.Context, id int64, expiresAt time.Time) error {
+	query := `
+		UPDATE carts SET expires_at = $1, updated_at = NOW() WHERE id = $2;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, expiresAt, id)
+	return err
+}
+
+// AddItem adds a line to a cart, or adds to the quantity of the line for
+// the same product and variant if the cart already has one.
+func (r *CartRepository) AddItem(ctx context.Context, cartId int64, item *domain.CartItem) error {
+	query := `
+		INSERT INTO 
+		    cart_items (cart_id, product_id, variant_id, quantity)
+		VALUES 
+		    ($1, $2, $3, $4)
+		ON CONFLICT ON CONSTRAINT cart_items_line_unique DO UPDATE 
+		SET quantity = cart_items.quantity + EXCLUDED.quantity, updated_at = NOW()
+		RETURNING
+			id;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	return postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, cartId, item.ProductId, item.VariantId, item.Quantity).Scan(&item.Id)
+}
+
+func (r *CartRepository) UpdateItem(ctx context.Context, cartId, itemId, quantity int64) error {
+	query := `
+		UPDATE cart_items SET quantity = $1, updated_at = NOW() WHERE id = $2 AND cart_id = $3;
+	`
+
+	return r.execItem(ctx, query, quantity, itemId, cartId)
+}
+
+func (r *CartRepository) RemoveItem(ctx context.Context, cartId, itemId int64) error {
+	query := `
+		DELETE FROM cart_items WHERE id = $1 AND cart_id = $2;
+	`
+
+	return r.execItem(ctx, query, itemId, cartId)
+}
+
+func (r *CartRepository) execItem(ctx context.Context, query string, args ...any) error {
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// AssignCustomer hands an anonymous cart over to a customer; the token
+// stops working once the cart belongs to someone.
+func (r *CartRepository) AssignCustomer(ctx context.Context, id, customerId int64) error {
+	query := `
+		UPDATE carts SET customer_id = $1, token = NULL, updated_at = NOW() WHERE id = $2;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, customerId, id)
+	return err
+}
+
+// Merge moves the lines of one cart into another, adding up quantities of
+// lines both carts have, and deletes the emptied cart. Merged quantities are
+// capped at the stock there is, lines priced in anything but currency are
+// left behind, so the cart keeps a single currency, and so are lines out
+// of stock. An empty currency takes every line.
+func (r *CartRepository) Merge(ctx context.Context, fromId, intoId int64, currency string) error {
+	mergeQuery := `
+		INSERT INTO cart_items (cart_id, product_id, variant_id, quantity)
+		SELECT $1, ci.product_id, ci.variant_id, LEAST(ci.quantity + COALESCE(existing.quantity, 0), COALESCE(v.stock, p.stock))
+		FROM cart_items ci
+		JOIN products p ON ci.product_id = p.id
+		LEFT JOIN product_variants v ON ci.variant_id = v.id
+		LEFT JOIN cart_items existing ON existing.cart_id = $1
+			AND existing.product_id = ci.product_id
+			AND existing.variant_id IS NOT DISTINCT FROM ci.variant_id
+		WHERE ci.cart_id = $2 AND ($3 = '' OR p.currency = $3) AND COALESCE(v.stock, p.stock) > 0
+		ON CONFLICT ON CONSTRAINT cart_items_line_unique DO UPDATE 
+		SET quantity = EXCLUDED.quantity, updated_at = NOW();
+	`
+
+	deleteQuery := `
+		DELETE FROM carts WHERE id = $1;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, mergeQuery, intoId, fromId, currency); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, deleteQuery, fromId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *CartRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query := `
+		DELETE FROM carts WHERE expires_at <= NOW();
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// listCartItems loads the lines of a cart priced from the catalog: a
+// variant's own price, else the product's sale price, else its regular
+// price. Lines whose product or variant was removed are left out.
+func listCartItems(ctx context.Context, db queryer, cartId int64) ([]domain.CartItem, error) {
+	query := `
+		SELECT 
+			ci.id, ci.product_id, ci.variant_id, p.name, p.slug, v.sku,
+			COALESCE(v.price, p.sale_price, p.price), p.currency, COALESCE(v.stock, p.stock), ci.quantity
+		FROM cart_items ci
+		JOIN products p ON ci.product_id = p.id
+		LEFT JOIN product_variants v ON ci.variant_id = v.id
+		WHERE ci.cart_id = $1 AND p.is_active = true AND (ci.variant_id IS NULL OR v.is_active = true)
+		ORDER BY ci.id;
+	`
+
+	rows, err := db.QueryContext(ctx, query, cartId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []domain.CartItem{}
+	for rows.Next() {
+		var item domain.CartItem
+		var price, currency string
+
+		err = rows.Scan(
+			&item.Id,
+			&item.ProductId,
+			&item.VariantId,
+			&item.Name,
+			&item.Slug,
+			&item.Sku,
+			&price,
+			&currency,
+			&item.Stock,
+			&item.Quantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if item.UnitPrice, err = domain.ParseMoney(price, currency); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
 	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
 )
@@ -20,24 +21,24 @@ func (r *CategoryRepository) GetById(ctx context.Context, id int64) (*domain.Cat
 	query := `
 		WITH RECURSIVE tree AS (
 		    SELECT 
-		        id, name, slug, description, parent_id, image_url
+		        id, name, slug, description, parent_id, image_url, updated_at
 			FROM categories WHERE id = $1 AND is_active = true
 			UNION ALL
 			SELECT 
-			    c.id, c.name, c.slug, c.description, c.parent_id, c.image_url
+			    c.id, c.name, c.slug, c.description, c.parent_id, c.image_url, c.updated_at
 			FROM categories c
 			JOIN tree t ON c.id = t.parent_id
 			WHERE c.is_active = true
 		    )
 		SELECT 
-		    id, name, slug, description, parent_id, image_url
+		    id, name, slug, description, parent_id, image_url, updated_at
 		FROM tree;
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := r.db.QueryContext(ctx, query, id)
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query, id)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -59,6 +60,7 @@ func (r *CategoryRepository) GetById(ctx context.Context, id int64) (*domain.Cat
 			&category.Description,
 			&category.ParentId,
 			&category.ImageUrl,
+			&category.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -93,3 +95,288 @@ func (r *CategoryRepository) GetById(ctx context.Context, id int64) (*domain.Cat
 
 	return requestedCategory, nil
 }
+
+func (r *CategoryRepository) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	query := `
+		SELECT id FROM categories WHERE slug = $1 AND is_active = true;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	var id int64
+	err := postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, slug).Scan(&id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, domain.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return r.GetById(ctx, id)
+}
+
+func (r *CategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	query := `
+		INSERT INTO 
+		    categories (name, slug, description, image_url, parent_id)
+		VALUES 
+		    ($1, $2, $3, $4, $5)
+		RETURNING
+			id;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	err := postgres.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		category.Name,
+		category.Slug,
+		category.Description,
+		category.ImageUrl,
+		category.ParentId,
+	).Scan(&category.Id)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err, "categories_name_unique"):
+			return fmt.Errorf("%w: category name %q is already taken", domain.ErrConflict, category.Name)
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *CategoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	query := `
+		UPDATE 
+		    categories 
+		SET
+		    name = $1,
+		    slug = $2,
+		    description = $3,
+		    image_url = $4,
+		    updated_at = NOW()
+		WHERE 
+		    id = $5 AND is_active = true
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		category.Name,
+		category.Slug,
+		category.Description,
+		category.ImageUrl,
+		category.Id,
+	)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err, "categories_name_unique"):
+			return fmt.Errorf("%w: category name %q is already taken", domain.ErrConflict, category.Name)
+		default:
+			return err
+		}
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *CategoryRepository) UpdateParent(ctx context.Context, id int64, parentId *int64) error {
+	query := `
+		UPDATE categories SET parent_id = $1, updated_at = NOW() WHERE id = $2 AND is_active = true;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, parentId, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// LockPath locks category id together with parentId and its ancestors, the
+// rows a move's cycle check reads, in id order so concurrent moves queue
+// rather than deadlock. It must run inside a transaction.
+func (r *CategoryRepository) LockPath(ctx context.Context, id, parentId int64) error {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM categories WHERE id = $2
+			UNION
+			SELECT c.id, c.parent_id FROM categories c
+			JOIN ancestors a ON c.id = a.parent_id
+		)
+		SELECT id FROM categories
+		WHERE id = $1 OR id IN (SELECT id FROM ancestors)
+		ORDER BY id
+		FOR UPDATE;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, id, parentId)
+	return err
+}
+
+func (r *CategoryRepository) Deactivate(ctx context.Context, id int64) error {
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $1 AND is_active = true
+			UNION
+			SELECT c.id FROM categories c
+			JOIN subtree s ON c.parent_id = s.id
+			WHERE c.is_active = true
+		)
+		UPDATE categories SET is_active = false, updated_at = NOW()
+		WHERE id IN (SELECT id FROM subtree);
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *CategoryRepository) SlugExists(ctx context.Context, candidate string) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM categories WHERE slug = $1);
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	var exists bool
+	err := postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, candidate).Scan(&exists)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+
+	return exists, nil
+}
+
+func (r *CategoryRepository) ListActive(ctx context.Context) ([]domain.Category, error) {
+	query := `
+		SELECT 
+			id, name, slug, description, parent_id, image_url
+		FROM categories
+		WHERE is_active = true
+		ORDER BY name;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []domain.Category
+	for rows.Next() {
+		var category domain.Category
+		err = rows.Scan(
+			&category.Id,
+			&category.Name,
+			&category.Slug,
+			&category.Description,
+			&category.ParentId,
+			&category.ImageUrl,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (r *CategoryRepository) ListChildren(ctx context.Context, parentId int64) ([]domain.CategorySummary, error) {
+	query := `
+		SELECT 
+			id, name, slug
+		FROM categories
+		WHERE parent_id = $1 AND is_active = true
+		ORDER BY name;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query, parentId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	children := []domain.CategorySummary{}
+	for rows.Next() {
+		var child domain.CategorySummary
+		if err = rows.Scan(&child.Id, &child.Name, &child.Slug); err != nil {
+			return nil, err
+		}
+
+		children = append(children, child)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return children, nil
+}
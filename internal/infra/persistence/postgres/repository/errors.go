@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"errors"
+	"github.com/lib/pq"
+)
+
+const uniqueViolationCode = "23505"
+
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == uniqueViolationCode && pqErr.Constraint == constraint
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+)
+
+type ImageRepository struct {
+	db *sql.DB
+}
+
+func NewImageRepository(db *sql.DB) *ImageRepository {
+	return &ImageRepository{
+		db: db,
+	}
+}
+
+func (r *ImageRepository) ListByProduct(ctx context.Context, productId int64) ([]domain.ProductImage, error) {
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	return listProductImages(ctx, postgres.Conn(ctx, r.db), productId)
+}
+
+// Create inserts the image. When a concurrent upload has already become the
+// product's primary image, this one is stored as an ordinary image instead.
+func (r *ImageRepository) Create(ctx context.Context, image *domain.ProductImage) error {
+	query := `
+		INSERT INTO 
+		    product_images (product_id, url, mime_type, width, height, position, is_primary, thumbnails, storage_keys)
+		VALUES 
+		    ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (product_id) WHERE is_primary DO NOTHING
+		RETURNING
+			id, created_at;
+	`
+
+	thumbnails, err := json.Marshal(image.Thumbnails)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = touchProduct(ctx, tx, image.ProductId); err != nil {
+		return err
+	}
+
+	insert := func() error {
+		return tx.QueryRowContext(
+			ctx,
+			query,
+			image.ProductId,
+			image.Url,
+			image.MimeType,
+			image.Width,
+			image.Height,
+			image.Position,
+			image.IsPrimary,
+			string(thumbnails),
+			pq.Array(image.StorageKeys),
+		).Scan(&image.Id, &image.CreatedAt)
+	}
+
+	err = insert()
+	if image.IsPrimary && errors.Is(err, sql.ErrNoRows) {
+		image.IsPrimary = false
+		err = insert()
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes an image and returns it so its files can be cleaned up.
+// When the primary image goes, the first remaining one takes its place.
+func (r *ImageRepository) Delete(ctx context.Context, productId, id int64) (*domain.ProductImage, error) {
+	query := `
+		DELETE FROM product_images
+		WHERE id = $1 AND product_id = $2
+		RETURNING ` + productImageColumns
+
+	promoteQuery := `
+		UPDATE product_images SET is_primary = true
+		WHERE id = (
+			SELECT id FROM product_images
+			WHERE product_id = $1
+			ORDER BY position, id
+			LIMIT 1
+		);
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err = touchProduct(ctx, tx, productId); err != nil {
+		return nil, err
+	}
+
+	image, err := scanProductImage(tx.QueryRowContext(ctx, query, id, productId))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, domain.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if image.IsPrimary {
+		if _, err = tx.ExecContext(ctx, promoteQuery, productId); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return image, nil
+}
+
+// SetPrimary marks one image as primary. The previous primary is cleared
+// first since at most one image per product may hold the flag.
+func (r *ImageRepository) SetPrimary(ctx context.Context, productId, id int64) error {
+	clearQuery := `
+		UPDATE product_images SET is_primary = false
+		WHERE product_id = $1 AND is_primary AND id <> $2;
+	`
+
+	setQuery := `
+		UPDATE product_images SET is_primary = true
+		WHERE id = $1 AND product_id = $2;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = touchProduct(ctx, tx, productId); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, clearQuery, productId, id); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, setQuery, id, productId)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+// Reorder sets image positions to their index in ids.
+func (r *ImageRepository) Reorder(ctx context.Context, productId int64, ids []int64) error {
+	query := `
+		UPDATE product_images pi
+		SET position = o.ordinality - 1
+		FROM unnest($1::bigint[]) WITH ORDINALITY o(id, ordinality)
+		WHERE pi.id = o.id AND pi.product_id = $2;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = touchProduct(ctx, tx, productId); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, query, pq.Array(ids), productId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const productImageColumns = `
+	id, product_id, url, mime_type, width, height, position, is_primary, thumbnails, storage_keys, created_at
+`
+
+func listProductImages(ctx context.Context, db queryer, productId int64) ([]domain.ProductImage, error) {
+	query := `
+		SELECT ` + productImageColumns + `
+		FROM product_images
+		WHERE product_id = $1
+		ORDER BY position, id;
+	`
+
+	rows, err := db.QueryContext(ctx, query, productId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := []domain.ProductImage{}
+	for rows.Next() {
+		image, err := scanProductImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, *image)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+func scanProductImage(row rowScanner) (*domain.ProductImage, error) {
+	var image domain.ProductImage
+	var thumbnails []byte
+
+	err := row.Scan(
+		&image.Id,
+		&image.ProductId,
+		&image.Url,
+		&image.MimeType,
+		&image.Width,
+		&image.Height,
+		&image.Position,
+		&image.IsPrimary,
+		&thumbnails,
+		pq.Array(&image.StorageKeys),
+		&image.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(thumbnails, &image.Thumbnails); err != nil {
+		return nil, err
+	}
+
+	return &image, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 type MockProductRepository struct {
@@ -34,8 +35,8 @@ func (r *MockProductRepository) Update(ctx context.Context, product *domain.Prod
 	return args.Error(0)
 }
 
-func (r *MockProductRepository) Delete(ctx context.Context, id int64) error {
-	args := r.Called(ctx, id)
+func (r *MockProductRepository) Delete(ctx context.Context, id, version int64) error {
+	args := r.Called(ctx, id, version)
 	return args.Error(0)
 }
 
@@ -60,6 +61,33 @@ func (r *MockProductRepository) List(ctx context.Context, q domain.PaginatedProd
 	return products, meta, args.Error(2)
 }
 
+func (r *MockProductRepository) ListByCursor(ctx context.Context, q domain.PaginatedProductsQuery) ([]domain.ProductSummary, domain.CursorMeta, error) {
+	args := r.Called(ctx, q)
+
+	var products []domain.ProductSummary
+	if args.Get(0) != nil {
+		products = args.Get(0).([]domain.ProductSummary)
+	}
+
+	var meta domain.CursorMeta
+	if args.Get(1) != nil {
+		meta = args.Get(1).(domain.CursorMeta)
+	}
+
+	return products, meta, args.Error(2)
+}
+
+func (r *MockProductRepository) Facets(ctx context.Context, q domain.PaginatedProductsQuery) (domain.Facets, error) {
+	args := r.Called(ctx, q)
+
+	var facets domain.Facets
+	if args.Get(0) != nil {
+		facets = args.Get(0).(domain.Facets)
+	}
+
+	return facets, args.Error(1)
+}
+
 func (r *MockCategoryRepository) GetById(ctx context.Context, id int64) (*domain.Category, error) {
 	args := r.Called(ctx, id)
 
@@ -69,3 +97,594 @@ func (r *MockCategoryRepository) GetById(ctx context.Context, id int64) (*domain
 
 	return args.Get(0).(*domain.Category), args.Error(1)
 }
+
+type MockBrandRepository struct {
+	mock.Mock
+}
+
+func (r *MockBrandRepository) GetBySlug(ctx context.Context, slug string) (*domain.Brand, error) {
+	args := r.Called(ctx, slug)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Brand), args.Error(1)
+}
+
+func (r *MockBrandRepository) Create(ctx context.Context, brand *domain.Brand) error {
+	args := r.Called(ctx, brand)
+	return args.Error(0)
+}
+
+func (r *MockBrandRepository) Update(ctx context.Context, brand *domain.Brand) error {
+	args := r.Called(ctx, brand)
+	return args.Error(0)
+}
+
+func (r *MockBrandRepository) Delete(ctx context.Context, id int64) error {
+	args := r.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (r *MockBrandRepository) SlugExists(ctx context.Context, candidate string) (bool, error) {
+	args := r.Called(ctx, candidate)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *MockBrandRepository) List(ctx context.Context, q domain.PaginatedBrandsQuery) ([]domain.Brand, domain.Meta, error) {
+	args := r.Called(ctx, q)
+
+	var brands []domain.Brand
+	if args.Get(0) != nil {
+		brands = args.Get(0).([]domain.Brand)
+	}
+
+	var meta domain.Meta
+	if args.Get(1) != nil {
+		meta = args.Get(1).(domain.Meta)
+	}
+
+	return brands, meta, args.Error(2)
+}
+
+func (r *MockCategoryRepository) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	args := r.Called(ctx, slug)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
+func (r *MockCategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	args := r.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (r *MockCategoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	args := r.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (r *MockCategoryRepository) UpdateParent(ctx context.Context, id int64, parentId *int64) error {
+	args := r.Called(ctx, id, parentId)
+	return args.Error(0)
+}
+
+func (r *MockCategoryRepository) LockPath(ctx context.Context, id, parentId int64) error {
+	args := r.Called(ctx, id, parentId)
+	return args.Error(0)
+}
+
+func (r *MockCategoryRepository) Deactivate(ctx context.Context, id int64) error {
+	args := r.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (r *MockCategoryRepository) SlugExists(ctx context.Context, candidate string) (bool, error) {
+	args := r.Called(ctx, candidate)
+	return args.Bool(0), args.Error(1)
+}
+
+func (r *MockCategoryRepository) ListActive(ctx context.Context) ([]domain.Category, error) {
+	args := r.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]domain.Category), args.Error(1)
+}
+
+func (r *MockCategoryRepository) ListChildren(ctx context.Context, parentId int64) ([]domain.CategorySummary, error) {
+	args := r.Called(ctx, parentId)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]domain.CategorySummary), args.Error(1)
+}
+
+type MockVariantRepository struct {
+	mock.Mock
+}
+
+func (r *MockVariantRepository) GetById(ctx context.Context, productId, id int64) (*domain.ProductVariant, error) {
+	args := r.Called(ctx, productId, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.ProductVariant), args.Error(1)
+}
+
+func (r *MockVariantRepository) ListByProduct(ctx context.Context, productId int64) ([]domain.ProductVariant, error) {
+	args := r.Called(ctx, productId)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]domain.ProductVariant), args.Error(1)
+}
+
+func (r *MockVariantRepository) Create(ctx context.Context, variant *domain.ProductVariant) error {
+	args := r.Called(ctx, variant)
+	return args.Error(0)
+}
+
+func (r *MockVariantRepository) Update(ctx context.Context, variant *domain.ProductVariant) error {
+	args := r.Called(ctx, variant)
+	return args.Error(0)
+}
+
+func (r *MockVariantRepository) Delete(ctx context.Context, productId, id int64) error {
+	args := r.Called(ctx, productId, id)
+	return args.Error(0)
+}
+
+func (r *MockVariantRepository) SetOptions(ctx context.Context, productId int64, options []domain.ProductOption) error {
+	args := r.Called(ctx, productId, options)
+	return args.Error(0)
+}
+
+type MockAttributeRepository struct {
+	mock.Mock
+}
+
+func (r *MockAttributeRepository) ListDefinitions(ctx context.Context, categoryId int64) ([]domain.AttributeDefinition, error) {
+	args := r.Called(ctx, categoryId)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]domain.AttributeDefinition), args.Error(1)
+}
+
+func (r *MockAttributeRepository) CreateDefinition(ctx context.Context, definition *domain.AttributeDefinition) error {
+	args := r.Called(ctx, definition)
+	return args.Error(0)
+}
+
+func (r *MockAttributeRepository) DeleteDefinition(ctx context.Context, categoryId int64, code string) error {
+	args := r.Called(ctx, categoryId, code)
+	return args.Error(0)
+}
+
+func (r *MockAttributeRepository) SetProductValues(ctx context.Context, productId int64, attributes []domain.ProductAttribute) error {
+	args := r.Called(ctx, productId, attributes)
+	return args.Error(0)
+}
+
+type MockSearchRepository struct {
+	mock.Mock
+}
+
+func (r *MockSearchRepository) Suggest(ctx context.Context, q domain.SuggestQuery) (*domain.Suggestions, error) {
+	args := r.Called(ctx, q)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Suggestions), args.Error(1)
+}
+
+type MockImageRepository struct {
+	mock.Mock
+}
+
+func (r *MockImageRepository) ListByProduct(ctx context.Context, productId int64) ([]domain.ProductImage, error) {
+	args := r.Called(ctx, productId)
+
+	var images []domain.ProductImage
+	if args.Get(0) != nil {
+		images = args.Get(0).([]domain.ProductImage)
+	}
+
+	return images, args.Error(1)
+}
+
+func (r *MockImageRepository) Create(ctx context.Context, image *domain.ProductImage) error {
+	args := r.Called(ctx, image)
+	return args.Error(0)
+}
+
+func (r *MockImageRepository) Delete(ctx context.Context, productId, id int64) (*domain.ProductImage, error) {
+	args := r.Called(ctx, productId, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.ProductImage), args.Error(1)
+}
+
+func (r *MockImageRepository) SetPrimary(ctx context.Context, productId, id int64) error {
+	args := r.Called(ctx, productId, id)
+	return args.Error(0)
+}
+
+func (r *MockImageRepository) Reorder(ctx context.Context, productId int64, ids []int64) error {
+	args := r.Called(ctx, productId, ids)
+	return args.Error(0)
+}
+
+type MockCartRepository struct {
+	mock.Mock
+}
+
+func (r *MockCartRepository) GetByToken(ctx context.Context, token string) (*domain.Cart, error) {
+	args := r.Called(ctx, token)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Cart), args.Error(1)
+}
+
+func (r *MockCartRepository) GetByCustomer(ctx context.Context, customerId int64) (*domain.Cart, error) {
+	args := r.Called(ctx, customerId)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Cart), args.Error(1)
+}
+
+func (r *MockCartRepository) Create(ctx context.Context, cart *domain.Cart) error {
+	args := r.Called(ctx, cart)
+	return args.Error(0)
+}
+
+func (r *MockCartRepository) Touch(ctx context.Context, id int64, expiresAt time.Time) error {
+	args := r.Called(ctx, id, expiresAt)
+	return args.Error(0)
+}
+
+func (r *MockCartRepository) AddItem(ctx context.Context, cartId int64, item *domain.CartItem) error {
+	args := r.Called(ctx, cartId, item)
+	return args.Error(0)
+}
+
+func (r *MockCartRepository) UpdateItem(ctx context.Context, cartId, itemId, quantity int64) error {
+	args := r.Called(ctx, cartId, itemId, quantity)
+	return args.Error(0)
+}
+
+func (r *MockCartRepository) RemoveItem(ctx context.Context, cartId, itemId int64) error {
+	args := r.Called(ctx, cartId, itemId)
+	return args.Error(0)
+}
+
+func (r *MockCartRepository) AssignCustomer(ctx context.Context, id, customerId int64) error {
+	args := r.Called(ctx, id, customerId)
+	return args.Error(0)
+}
+
+func (r *MockCartRepository) Merge(ctx context.Context, fromId, intoId int64, currency string) error {
+	args := r.Called(ctx, fromId, intoId, currency)
+	return args.Error(0)
+}
+
+func (r *MockCartRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	args := r.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (r *MockOrderRepository) GetById(ctx context.Context, id int64) (*domain.Order, error) {
+	args := r.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (r *MockOrderRepository) ListByOwner(ctx context.Context, owner domain.CartOwner, q domain.PaginatedOrdersQuery) ([]domain.Order, domain.Meta, error) {
+	args := r.Called(ctx, owner, q)
+
+	var orders []domain.Order
+	if args.Get(0) != nil {
+		orders = args.Get(0).([]domain.Order)
+	}
+
+	var meta domain.Meta
+	if args.Get(1) != nil {
+		meta = args.Get(1).(domain.Meta)
+	}
+
+	return orders, meta, args.Error(2)
+}
+
+func (r *MockOrderRepository) PlaceFromCart(ctx context.Context, cartId int64, order *domain.Order) error {
+	args := r.Called(ctx, cartId, order)
+	return args.Error(0)
+}
+
+func (r *MockOrderRepository) Transition(ctx context.Context, orderId int64, transition *domain.OrderTransition) error {
+	args := r.Called(ctx, orderId, transition)
+	return args.Error(0)
+}
+
+type MockPaymentRepository struct {
+	mock.Mock
+}
+
+func (r *MockPaymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
+	args := r.Called(ctx, payment)
+	return args.Error(0)
+}
+
+func (r *MockPaymentRepository) ListByOrder(ctx context.Context, orderId int64) ([]domain.Payment, error) {
+	args := r.Called(ctx, orderId)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]domain.Payment), args.Error(1)
+}
+
+func (r *MockPaymentRepository) GetAuthorization(ctx context.Context, provider, reference string) (*domain.Payment, error) {
+	args := r.Called(ctx, provider, reference)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Payment), args.Error(1)
+}
+
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (r *MockUserRepository) GetById(ctx context.Context, id int64) (*domain.User, error) {
+	args := r.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (r *MockUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	args := r.Called(ctx, email)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (r *MockUserRepository) Create(ctx context.Context, user *domain.User) error {
+	args := r.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (r *MockUserRepository) Update(ctx context.Context, user *domain.User) error {
+	args := r.Called(ctx, user)
+	return args.Error(0)
+}
+
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (r *MockSessionRepository) GetById(ctx context.Context, id int64) (*domain.Session, error) {
+	args := r.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Session), args.Error(1)
+}
+
+func (r *MockSessionRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	args := r.Called(ctx, tokenHash)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.RefreshToken), args.Error(1)
+}
+
+func (r *MockSessionRepository) Create(ctx context.Context, session *domain.Session, token *domain.RefreshToken) error {
+	args := r.Called(ctx, session, token)
+	return args.Error(0)
+}
+
+func (r *MockSessionRepository) Rotate(ctx context.Context, used *domain.RefreshToken, next *domain.RefreshToken, client domain.SessionClient) error {
+	args := r.Called(ctx, used, next, client)
+	return args.Error(0)
+}
+
+func (r *MockSessionRepository) ListActive(ctx context.Context, userId int64) ([]domain.Session, error) {
+	args := r.Called(ctx, userId)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]domain.Session), args.Error(1)
+}
+
+func (r *MockSessionRepository) Revoke(ctx context.Context, userId, sessionId int64) error {
+	args := r.Called(ctx, userId, sessionId)
+	return args.Error(0)
+}
+
+func (r *MockSessionRepository) RevokeAll(ctx context.Context, userId int64) (int64, error) {
+	args := r.Called(ctx, userId)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockApiKeyRepository struct {
+	mock.Mock
+}
+
+func (r *MockApiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.ApiKey, error) {
+	args := r.Called(ctx, prefix)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.ApiKey), args.Error(1)
+}
+
+func (r *MockApiKeyRepository) List(ctx context.Context) ([]domain.ApiKey, error) {
+	args := r.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]domain.ApiKey), args.Error(1)
+}
+
+func (r *MockApiKeyRepository) Create(ctx context.Context, key *domain.ApiKey) error {
+	args := r.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (r *MockApiKeyRepository) Revoke(ctx context.Context, id int64) error {
+	args := r.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (r *MockApiKeyRepository) TouchLastUsed(ctx context.Context, id int64) error {
+	args := r.Called(ctx, id)
+	return args.Error(0)
+}
+
+type MockUserTokenRepository struct {
+	mock.Mock
+}
+
+func (r *MockUserTokenRepository) Create(ctx context.Context, token *domain.UserToken, email *domain.Email) error {
+	args := r.Called(ctx, token, email)
+	return args.Error(0)
+}
+
+func (r *MockUserTokenRepository) Consume(ctx context.Context, purpose domain.UserTokenPurpose, tokenHash string) (*domain.UserToken, error) {
+	args := r.Called(ctx, purpose, tokenHash)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.UserToken), args.Error(1)
+}
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (r *MockOutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.Email, error) {
+	args := r.Called(ctx, limit, lease)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]domain.Email), args.Error(1)
+}
+
+func (r *MockOutboxRepository) MarkSent(ctx context.Context, id int64) error {
+	args := r.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (r *MockOutboxRepository) MarkFailed(ctx context.Context, id int64, reason string, retryAt *time.Time) error {
+	args := r.Called(ctx, id, reason, retryAt)
+	return args.Error(0)
+}
+
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (r *MockTwoFactorRepository) GetTOTP(ctx context.Context, userId int64) (*domain.TOTPEnrolment, error) {
+	args := r.Called(ctx, userId)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.TOTPEnrolment), args.Error(1)
+}
+
+func (r *MockTwoFactorRepository) SaveTOTP(ctx context.Context, enrolment *domain.TOTPEnrolment) error {
+	args := r.Called(ctx, enrolment)
+	return args.Error(0)
+}
+
+func (r *MockTwoFactorRepository) ConfirmTOTP(ctx context.Context, userId, step int64, codes []domain.RecoveryCode) error {
+	args := r.Called(ctx, userId, step, codes)
+	return args.Error(0)
+}
+
+func (r *MockTwoFactorRepository) DeleteTOTP(ctx context.Context, userId int64) error {
+	args := r.Called(ctx, userId)
+	return args.Error(0)
+}
+
+func (r *MockTwoFactorRepository) UseTOTPStep(ctx context.Context, userId, step int64) error {
+	args := r.Called(ctx, userId, step)
+	return args.Error(0)
+}
+
+func (r *MockTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userId int64, codes []domain.RecoveryCode) error {
+	args := r.Called(ctx, userId, codes)
+	return args.Error(0)
+}
+
+func (r *MockTwoFactorRepository) UseRecoveryCode(ctx context.Context, userId int64, codeHash string) error {
+	args := r.Called(ctx, userId, codeHash)
+	return args.Error(0)
+}
+
+// MockTransactor runs units of work straight away, without a transaction.
+type MockTransactor struct{}
+
+func (t *MockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
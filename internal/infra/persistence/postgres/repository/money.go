@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+)
+
+// priceColumns receives the DECIMAL price columns as text so they can be
+// converted to domain.Money without a float64 round trip.
+type priceColumns struct {
+	price     string
+	salePrice sql.NullString
+	currency  string
+}
+
+func (c *priceColumns) apply(product *domain.BaseProduct) error {
+	price, err := domain.ParseMoney(c.price, c.currency)
+	if err != nil {
+		return err
+	}
+	product.Price = price
+
+	product.SalePrice = nil
+	if c.salePrice.Valid {
+		salePrice, err := domain.ParseMoney(c.salePrice.String, c.currency)
+		if err != nil {
+			return err
+		}
+		product.SalePrice = &salePrice
+	}
+
+	return nil
+}
+
+func nullDecimal(m *domain.Money) any {
+	if m == nil {
+		return nil
+	}
+	return m.Decimal()
+}
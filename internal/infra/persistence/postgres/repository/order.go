@@ -0,0 +1,559 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+	"math"
+	"strings"
+)
+
+type OrderRepository struct {
+	db *sql.DB
+}
+
+func NewOrderRepository(db *sql.DB) *OrderRepository {
+	return &OrderRepository{
+		db: db,
+	}
+}
+
+func (r *OrderRepository) GetById(ctx context.Context, id int64) (*domain.Order, error) {
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE id = $1;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	order, err := scanOrder(postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, domain.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	order.Items, err = listOrderItems(ctx, postgres.Conn(ctx, r.db), order.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	order.History, err = listOrderHistory(ctx, postgres.Conn(ctx, r.db), order.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ListByOwner lists an owner's orders, newest first by default. Items are
+// left out; they come with the order itself.
+func (r *OrderRepository) ListByOwner(ctx context.Context, owner domain.CartOwner, q domain.PaginatedOrdersQuery) ([]domain.Order, domain.Meta, error) {
+	var query strings.Builder
+	query.WriteString(`
+		SELECT ` + orderColumns + `, COUNT(id) OVER()
+		FROM orders
+	`)
+
+	var ownerId any = owner.Token
+	if owner.IsAnonymous() {
+		query.WriteString(" WHERE guest_token = $1 AND customer_id IS NULL ")
+	} else {
+		query.WriteString(" WHERE customer_id = $1 ")
+		ownerId = owner.CustomerId
+	}
+
+	query.WriteString(" ORDER BY created_at ")
+	query.WriteString(q.SortDirection)
+	query.WriteString(", id ")
+	query.WriteString(q.SortDirection)
+	query.WriteString(" LIMIT $2 OFFSET $3")
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query.String(), ownerId, q.Limit, q.Offset)
+	if err != nil {
+		return nil, domain.Meta{}, err
+	}
+	defer rows.Close()
+
+	orders := []domain.Order{}
+	var count int
+	for rows.Next() {
+		order, err := scanOrder(rows, &count)
+		if err != nil {
+			return nil, domain.Meta{}, err
+		}
+		orders = append(orders, *order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, domain.Meta{}, err
+	}
+
+	currentPage := (q.Offset / q.Limit) + 1
+	totalPages := int(math.Ceil(float64(count) / float64(q.Limit)))
+	meta := domain.Meta{
+		TotalItems:  count,
+		CurrentPage: currentPage,
+		PageSize:    q.Limit,
+		TotalPages:  totalPages,
+	}
+
+	return orders, meta, nil
+}
+
+// PlaceFromCart runs checkout in one transaction. The cart, and then the
+// products and variants it holds, are locked in id order so concurrent
+// checkouts of the same stock queue up instead of overselling or
+// deadlocking. Lines are re-read under those locks, so prices and stock are
+// exactly what is being sold. Lines whose product or variant has since been
+// withdrawn are removed and reported with ErrItemsUnavailable instead of
+// being dropped from the order unseen; checking out again places the rest.
+// A checkout that still loses a deadlock is run again from the start.
+func (r *OrderRepository) PlaceFromCart(ctx context.Context, cartId int64, order *domain.Order) error {
+	return postgres.Retry(ctx, func() error {
+		return r.placeFromCart(ctx, cartId, order)
+	})
+}
+
+func (r *OrderRepository) placeFromCart(ctx context.Context, cartId int64, order *domain.Order) error {
+	lockCartQuery := `
+		SELECT id FROM carts WHERE id = $1 FOR UPDATE;
+	`
+
+	lockProductsQuery := `
+		SELECT id FROM products
+		WHERE id IN (SELECT product_id FROM cart_items WHERE cart_id = $1)
+		ORDER BY id
+		FOR UPDATE;
+	`
+
+	lockVariantsQuery := `
+		SELECT id FROM product_variants
+		WHERE id IN (SELECT variant_id FROM cart_items WHERE cart_id = $1)
+		ORDER BY id
+		FOR UPDATE;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, lockQuery := range []string{lockCartQuery, lockProductsQuery, lockVariantsQuery} {
+		if _, err = tx.ExecContext(ctx, lockQuery, cartId); err != nil {
+			return err
+		}
+	}
+
+	unavailable, err := removeUnavailableCartItems(ctx, tx, cartId)
+	if err != nil {
+		return err
+	}
+	if len(unavailable) > 0 {
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %s removed from the cart", domain.ErrItemsUnavailable, strings.Join(unavailable, ", "))
+	}
+
+	lines, err := listCartItems(ctx, tx, cartId)
+	if err != nil {
+		return err
+	}
+
+	if err = order.Fill(lines); err != nil {
+		return err
+	}
+
+	if err = adjustStock(ctx, tx, order.Items, -1); err != nil {
+		return err
+	}
+
+	if err = insertOrder(ctx, tx, order); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM cart_items WHERE cart_id = $1;", cartId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// removeUnavailableCartItems deletes the cart's lines for inactive products
+// or variants, the ones listCartItems leaves out, and returns their names.
+func removeUnavailableCartItems(ctx context.Context, tx *postgres.Tx, cartId int64) ([]string, error) {
+	query := `
+		DELETE FROM cart_items ci
+		USING products p
+		WHERE ci.cart_id = $1 AND ci.product_id = p.id AND (
+			NOT p.is_active
+			OR EXISTS (SELECT 1 FROM product_variants v WHERE v.id = ci.variant_id AND NOT v.is_active)
+		)
+		RETURNING p.name;
+	`
+
+	rows, err := tx.QueryContext(ctx, query, cartId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// Transition stores a status change along with its history entry. The
+// update only applies if the order is still in the status the transition
+// starts from, so two concurrent changes can't both succeed. One that
+// loses a deadlock, say putting stock back while a checkout takes it, is
+// run again.
+func (r *OrderRepository) Transition(ctx context.Context, orderId int64, transition *domain.OrderTransition) error {
+	return postgres.Retry(ctx, func() error {
+		return r.transition(ctx, orderId, transition)
+	})
+}
+
+func (r *OrderRepository) transition(ctx context.Context, orderId int64, transition *domain.OrderTransition) error {
+	updateQuery := `
+		UPDATE orders SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3;
+	`
+
+	historyQuery := `
+		INSERT INTO 
+		    order_status_history (order_id, from_status, to_status, actor, reason)
+		VALUES 
+		    ($1, $2, $3, $4, $5)
+		RETURNING
+			id, created_at;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, updateQuery, transition.To, orderId, transition.From)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("%w: order %d is no longer %s", domain.ErrConflict, orderId, transition.From)
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		historyQuery,
+		orderId,
+		transition.From,
+		transition.To,
+		transition.Actor,
+		transition.Reason,
+	).Scan(&transition.Id, &transition.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if transition.RestoresStock() {
+		items, err := listOrderItems(ctx, tx, orderId)
+		if err != nil {
+			return err
+		}
+		if err = adjustStock(ctx, tx, items, 1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// adjustStock moves the quantities of items into or out of stock: variant
+// stock for variant lines, product stock for the rest. sign is -1 to take
+// stock and 1 to put it back. Every product involved moves on a version,
+// variant lines' included. The rows are locked first, products and then
+// variants in id order, as PlaceFromCart locks them, so checkouts and
+// cancellations queue up instead of deadlocking.
+func adjustStock(ctx context.Context, tx *postgres.Tx, items []domain.OrderItem, sign int64) error {
+	lockProductsQuery := `
+		SELECT id FROM products WHERE id = ANY($1::bigint[]) ORDER BY id FOR UPDATE;
+	`
+
+	lockVariantsQuery := `
+		SELECT id FROM product_variants WHERE id = ANY($1::bigint[]) ORDER BY id FOR UPDATE;
+	`
+
+	productsQuery := `
+		UPDATE products p
+		SET stock = p.stock + x.quantity, updated_at = NOW(), version = p.version + 1
+		FROM (
+			SELECT id, SUM(quantity) AS quantity
+			FROM unnest($1::bigint[], $2::int[]) u(id, quantity)
+			GROUP BY id
+		) x
+		WHERE p.id = x.id;
+	`
+
+	variantsQuery := `
+		UPDATE product_variants v
+		SET stock = v.stock + x.quantity, updated_at = NOW()
+		FROM unnest($1::bigint[], $2::int[]) x(id, quantity)
+		WHERE v.id = x.id;
+	`
+
+	var productIds, productQuantities, variantIds, variantQuantities []int64
+	for _, item := range items {
+		if item.VariantId != nil {
+			variantIds = append(variantIds, *item.VariantId)
+			variantQuantities = append(variantQuantities, sign*item.Quantity)
+			productIds = append(productIds, item.ProductId)
+			productQuantities = append(productQuantities, 0)
+		} else {
+			productIds = append(productIds, item.ProductId)
+			productQuantities = append(productQuantities, sign*item.Quantity)
+		}
+	}
+
+	if len(productIds) > 0 {
+		if _, err := tx.ExecContext(ctx, lockProductsQuery, pq.Array(productIds)); err != nil {
+			return err
+		}
+	}
+
+	if len(variantIds) > 0 {
+		if _, err := tx.ExecContext(ctx, lockVariantsQuery, pq.Array(variantIds)); err != nil {
+			return err
+		}
+	}
+
+	if len(productIds) > 0 {
+		if _, err := tx.ExecContext(ctx, productsQuery, pq.Array(productIds), pq.Array(productQuantities)); err != nil {
+			return err
+		}
+	}
+
+	if len(variantIds) > 0 {
+		if _, err := tx.ExecContext(ctx, variantsQuery, pq.Array(variantIds), pq.Array(variantQuantities)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func insertOrder(ctx context.Context, tx *postgres.Tx, order *domain.Order) error {
+	orderQuery := `
+		INSERT INTO 
+		    orders (customer_id, guest_token, status, currency, subtotal, total)
+		VALUES 
+		    ($1, $2, $3, $4, $5, $6)
+		RETURNING
+			id, created_at, updated_at;
+	`
+
+	itemQuery := `
+		INSERT INTO 
+		    order_items (order_id, product_id, variant_id, name, slug, sku, unit_price, quantity, line_total)
+		VALUES 
+		    ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING
+			id;
+	`
+
+	err := tx.QueryRowContext(
+		ctx,
+		orderQuery,
+		order.CustomerId,
+		order.GuestToken,
+		order.Status,
+		order.Total.Currency,
+		order.Subtotal.Decimal(),
+		order.Total.Decimal(),
+	).Scan(&order.Id, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		err = tx.QueryRowContext(
+			ctx,
+			itemQuery,
+			order.Id,
+			item.ProductId,
+			item.VariantId,
+			item.Name,
+			item.Slug,
+			item.Sku,
+			item.UnitPrice.Decimal(),
+			item.Quantity,
+			item.LineTotal.Decimal(),
+		).Scan(&item.Id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const orderColumns = `
+	id, customer_id, guest_token, status, currency, subtotal, total, created_at, updated_at
+`
+
+func scanOrder(row rowScanner, extra ...any) (*domain.Order, error) {
+	var order domain.Order
+	var currency, subtotal, total string
+
+	dest := []any{
+		&order.Id,
+		&order.CustomerId,
+		&order.GuestToken,
+		&order.Status,
+		&currency,
+		&subtotal,
+		&total,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if order.Subtotal, err = domain.ParseMoney(subtotal, currency); err != nil {
+		return nil, err
+	}
+	if order.Total, err = domain.ParseMoney(total, currency); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func listOrderItems(ctx context.Context, db queryer, orderId int64) ([]domain.OrderItem, error) {
+	query := `
+		SELECT 
+			oi.id, COALESCE(oi.product_id, 0), oi.variant_id, oi.name, oi.slug, oi.sku,
+			oi.unit_price, oi.quantity, oi.line_total, o.currency
+		FROM order_items oi
+		JOIN orders o ON oi.order_id = o.id
+		WHERE oi.order_id = $1
+		ORDER BY oi.id;
+	`
+
+	rows, err := db.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []domain.OrderItem{}
+	for rows.Next() {
+		var item domain.OrderItem
+		var unitPrice, lineTotal, currency string
+
+		err = rows.Scan(
+			&item.Id,
+			&item.ProductId,
+			&item.VariantId,
+			&item.Name,
+			&item.Slug,
+			&item.Sku,
+			&unitPrice,
+			&item.Quantity,
+			&lineTotal,
+			&currency,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if item.UnitPrice, err = domain.ParseMoney(unitPrice, currency); err != nil {
+			return nil, err
+		}
+		if item.LineTotal, err = domain.ParseMoney(lineTotal, currency); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func listOrderHistory(ctx context.Context, db queryer, orderId int64) ([]domain.OrderTransition, error) {
+	query := `
+		SELECT id, from_status, to_status, actor, reason, created_at
+		FROM order_status_history
+		WHERE order_id = $1
+		ORDER BY created_at, id;
+	`
+
+	rows, err := db.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []domain.OrderTransition{}
+	for rows.Next() {
+		var transition domain.OrderTransition
+		err = rows.Scan(
+			&transition.Id,
+			&transition.From,
+			&transition.To,
+			&transition.Actor,
+			&transition.Reason,
+			&transition.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, transition)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+	"time"
+)
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+// Claim pushes the next attempt of due emails out by lease and counts the
+// attempt. SKIP LOCKED lets several senders claim side by side, and an
+// email whose sender died is picked up again once the lease runs out.
+func (r *OutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.Email, error) {
+	query := `
+		UPDATE email_outbox
+		SET attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id
+			FROM email_outbox
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING
+			id, recipient, subject, body, attempts, created_at;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := []domain.Email{}
+	for rows.Next() {
+		var email domain.Email
+		err = rows.Scan(
+			&email.Id,
+			&email.To,
+			&email.Subject,
+			&email.Body,
+			&email.Attempts,
+			&email.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return emails, nil
+}
+
+func (r *OutboxRepository) MarkSent(ctx context.Context, id int64) error {
+	query := `
+		UPDATE email_outbox SET status = 'sent', sent_at = NOW(), last_error = NULL
+		WHERE id = $1;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, reason string, retryAt *time.Time) error {
+	query := `
+		UPDATE email_outbox 
+		SET last_error = $1, 
+		    status = CASE WHEN $2::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+		    next_attempt_at = COALESCE($2, next_attempt_at)
+		WHERE id = $3;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, reason, retryAt, id)
+	return err
+}
+
+// enqueueEmail adds email to the outbox as part of tx.
+func enqueueEmail(ctx context.Context, tx *postgres.Tx, email *domain.Email) error {
+	query := `
+		INSERT INTO 
+		    email_outbox (recipient, subject, body)
+		VALUES 
+		    ($1, $2, $3)
+		RETURNING
+			id, created_at;
+	`
+
+	return tx.QueryRowContext(ctx, query, email.To, email.Subject, email.Body).Scan(&email.Id, &email.CreatedAt)
+}
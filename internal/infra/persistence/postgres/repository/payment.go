@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+)
+
+type PaymentRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRepository(db *sql.DB) *PaymentRepository {
+	return &PaymentRepository{
+		db: db,
+	}
+}
+
+// Create records an attempt. Each succeeded operation is recorded once per
+// provider reference; a second one fails with domain.ErrConflict.
+func (r *PaymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
+	query := `
+		INSERT INTO 
+		    payments (order_id, provider, operation, status, amount, currency, reference, error)
+		VALUES 
+		    ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING
+			id, created_at;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	err := postgres.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		payment.OrderId,
+		payment.Provider,
+		payment.Operation,
+		payment.Status,
+		payment.Amount.Decimal(),
+		payment.Amount.Currency,
+		payment.Reference,
+		payment.Error,
+	).Scan(&payment.Id, &payment.CreatedAt)
+	if isUniqueViolation(err, "idx_payments_succeeded_reference") {
+		return fmt.Errorf("%w: %s %s is already recorded", domain.ErrConflict, payment.Operation, payment.Reference)
+	}
+	return err
+}
+
+func (r *PaymentRepository) ListByOrder(ctx context.Context, orderId int64) ([]domain.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE order_id = $1
+		ORDER BY created_at, id;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []domain.Payment{}
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, *payment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// GetAuthorization finds the successful authorization a provider reference
+// was issued for.
+func (r *PaymentRepository) GetAuthorization(ctx context.Context, provider, reference string) (*domain.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE provider = $1 AND reference = $2 AND operation = $3 AND status = $4
+		ORDER BY created_at DESC
+		LIMIT 1;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	payment, err := scanPayment(postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, provider, reference, domain.PaymentOperationAuthorize, domain.PaymentStatusSucceeded))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, domain.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return payment, nil
+}
+
+const paymentColumns = `
+	id, order_id, provider, operation, status, amount, currency, reference, error, created_at
+`
+
+func scanPayment(row rowScanner) (*domain.Payment, error) {
+	var payment domain.Payment
+	var amount, currency string
+
+	err := row.Scan(
+		&payment.Id,
+		&payment.OrderId,
+		&payment.Provider,
+		&payment.Operation,
+		&payment.Status,
+		&amount,
+		&currency,
+		&payment.Reference,
+		&payment.Error,
+		&payment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Amount, err = domain.ParseMoney(amount, currency); err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
@@ -3,12 +3,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
-	"github.com/lib/pq"
 	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
 	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
 	"math"
-	"strconv"
+	"slices"
 	"strings"
 )
 
@@ -25,8 +25,8 @@ func NewProductRepository(db *sql.DB) *ProductRepository {
 func (r *ProductRepository) GetById(ctx context.Context, id int64) (*domain.Product, error) {
 	query := `
 		SELECT 
-			p.id, p.name, p.slug, p.description, p.price, p.sale_price, p.stock, p.category_id, p.brand_id, p.created_at, p.updated_at,
-			b.id, b.name, b.slug, b.description, b.logo_url
+			p.id, p.name, p.slug, p.description, p.price, p.sale_price, p.currency, p.stock, p.category_id, p.brand_id, p.options, p.version, p.created_at, p.updated_at,
+			b.id, b.name, b.slug, b.description, b.logo_url, b.updated_at
 		FROM products p
 		LEFT JOIN brands b on p.brand_id = b.id
 		WHERE p.id = $1 AND p.is_active = true;
@@ -36,19 +36,24 @@ func (r *ProductRepository) GetById(ctx context.Context, id int64) (*domain.Prod
 	defer cancel()
 
 	var product domain.Product
+	var prices priceColumns
+	var options []byte
 	product.Category = &domain.Category{}
 	product.Brand = &domain.Brand{}
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&product.Id,
 		&product.Name,
 		&product.Slug,
 		&product.Description,
-		&product.Price,
-		&product.SalePrice,
+		&prices.price,
+		&prices.salePrice,
+		&prices.currency,
 		&product.Stock,
 		&product.CategoryId,
 		&product.BrandId,
+		&options,
+		&product.Version,
 		&product.CreatedAt,
 		&product.UpdatedAt,
 		&product.Brand.Id,
@@ -56,6 +61,7 @@ func (r *ProductRepository) GetById(ctx context.Context, id int64) (*domain.Prod
 		&product.Brand.Slug,
 		&product.Brand.Description,
 		&product.Brand.LogoUrl,
+		&product.Brand.UpdatedAt,
 	)
 	if err != nil {
 		switch {
@@ -66,36 +72,61 @@ func (r *ProductRepository) GetById(ctx context.Context, id int64) (*domain.Prod
 		}
 	}
 
+	if err = prices.apply(&product.BaseProduct); err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(options, &product.Options); err != nil {
+		return nil, err
+	}
+
+	product.Variants, err = listVariants(ctx, postgres.Conn(ctx, r.db), product.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	product.Attributes, err = listProductAttributes(ctx, postgres.Conn(ctx, r.db), product.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	product.Images, err = listProductImages(ctx, postgres.Conn(ctx, r.db), product.Id)
+	if err != nil {
+		return nil, err
+	}
+
 	return &product, nil
 }
 
 func (r *ProductRepository) Create(ctx context.Context, product *domain.Product) error {
 	query := `
 		INSERT INTO 
-		    products (name, slug, description, price, sale_price, stock, category_id, brand_id)
+		    products (name, slug, description, price, sale_price, currency, stock, category_id, brand_id)
 		VALUES 
-		    ($1, $2, $3, $4, $5, $6, $7, $8)
+		    ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING
-			id, created_at, updated_at;
+			id, version, created_at, updated_at;
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
 	defer cancel()
 
-	err := r.db.QueryRowContext(
+	err := postgres.Conn(ctx, r.db).QueryRowContext(
 		ctx,
 		query,
 		product.Name,
 		product.Slug,
 		product.Description,
-		product.Price,
-		product.SalePrice,
+		product.Price.Decimal(),
+		nullDecimal(product.SalePrice),
+		product.Price.Currency,
 		product.Stock,
 		product.CategoryId,
 		product.BrandId,
 	).
 		Scan(
 			&product.Id,
+			&product.Version,
 			&product.CreatedAt,
 			&product.UpdatedAt,
 		)
@@ -106,15 +137,18 @@ func (r *ProductRepository) Create(ctx context.Context, product *domain.Product)
 	return nil
 }
 
-func (r *ProductRepository) Delete(ctx context.Context, id int64) error {
+// Delete checks the version in the same statement, so a product edited
+// since the caller read it is never removed.
+func (r *ProductRepository) Delete(ctx context.Context, id, version int64) error {
 	query := `
-		UPDATE products SET is_active = false WHERE id = $1;
+		UPDATE products SET is_active = false, version = version + 1 
+		WHERE id = $1 AND ($2 = 0 OR version = $2);
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
 	defer cancel()
 
-	res, err := r.db.ExecContext(ctx, query, id)
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, id, version)
 
 	if err != nil {
 		return err
@@ -126,6 +160,9 @@ func (r *ProductRepository) Delete(ctx context.Context, id int64) error {
 	}
 
 	if rows == 0 {
+		if version != 0 {
+			return r.versionMismatch(ctx, id)
+		}
 		return domain.ErrNotFound
 	}
 
@@ -142,45 +179,66 @@ func (r *ProductRepository) Update(ctx context.Context, product *domain.Product)
 		    description = $3,
 		    price = $4,
 		    sale_price = $5,
-		    stock = $6,
-		    category_id = $7,
-		    brand_id = $8,
-			updated_at = $9
-		where 
-		    id = $10
+		    currency = $6,
+		    stock = $7,
+		    category_id = $8,
+		    brand_id = $9,
+			updated_at = $10,
+			version = version + 1
+		WHERE 
+		    id = $11 AND version = $12
+		RETURNING
+			version;
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
 	defer cancel()
 
-	res, err := r.db.ExecContext(
+	err := postgres.Conn(ctx, r.db).QueryRowContext(
 		ctx,
 		query,
 		product.Name,
 		product.Slug,
 		product.Description,
-		product.Price,
-		product.SalePrice,
+		product.Price.Decimal(),
+		nullDecimal(product.SalePrice),
+		product.Price.Currency,
 		product.Stock,
 		product.CategoryId,
 		product.BrandId,
 		product.UpdatedAt,
 		product.Id,
-	)
+		product.Version,
+	).Scan(&product.Version)
 	if err != nil {
-		return err
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return r.versionMismatch(ctx, product.Id)
+		default:
+			return err
+		}
 	}
 
-	rows, err := res.RowsAffected()
-	if err != nil {
+	return nil
+}
+
+// versionMismatch tells apart a product that is gone from one that was
+// changed, after a versioned write matched no row.
+func (r *ProductRepository) versionMismatch(ctx context.Context, id int64) error {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM products WHERE id = $1 AND is_active = true);
+	`
+
+	var exists bool
+	if err := postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
 		return err
 	}
 
-	if rows == 0 {
+	if !exists {
 		return domain.ErrNotFound
 	}
 
-	return nil
+	return domain.ErrVersionMismatch
 }
 
 func (r *ProductRepository) SlugExists(ctx context.Context, candidate string) (bool, error) {
@@ -192,7 +250,7 @@ func (r *ProductRepository) SlugExists(ctx context.Context, candidate string) (b
 	defer cancel()
 
 	var exists bool
-	err := r.db.QueryRowContext(ctx, query, candidate).Scan(&exists)
+	err := postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, candidate).Scan(&exists)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -206,99 +264,44 @@ func (r *ProductRepository) SlugExists(ctx context.Context, candidate string) (b
 }
 
 func (r *ProductRepository) List(ctx context.Context, q domain.PaginatedProductsQuery) ([]domain.ProductSummary, domain.Meta, error) {
-	var query strings.Builder
-	query.WriteString(`
-		SELECT 
-			p.id, p.name, p.slug, p.price, p.sale_price, p.stock, p.category_id, p.brand_id,
-			c.id, c.name, c.slug,
-			b.id, b.name, b.slug,
-			COUNT(p.id) OVER()
-		FROM products p
-		LEFT JOIN brands b ON p.brand_id = b.id
-		LEFT JOIN categories c ON p.category_id = c.id 
-		WHERE p.is_active = true AND (p.name ILIKE '%' || $1 || '%' OR p.description ILIKE '%' || $1 || '%')	
-	`)
-
-	params := []any{q.Search}
-	paramIndex := len(params) + 1
-
-	if len(q.Categories) > 0 {
-		query.WriteString(`
-			AND p.category_id in (
-				WITH RECURSIVE category_tree AS (
-					SELECT id FROM categories
-					WHERE slug = ANY($` + strconv.Itoa(paramIndex) + `)
-					UNION ALL
-					SELECT c.id FROM categories
-					JOIN category_tree ct ON c.parent_id = ct.id
-				)
-				SELECT id FROM category_tree
-			)
-		`)
-		params = append(params, pq.Array(q.Categories))
-		paramIndex++
-	}
-
-	query.WriteString("GROUP BY p.id, c.id, b.id")
-
-	validSortFields := map[string]string{
-		"name":  "p.name",
-		"price": "p.price",
-		"stock": "p.stock",
-	}
-
-	sortField, exists := validSortFields[q.SortField]
-	if !exists {
-		sortField = "p.name"
+	filter, err := newProductFilter(q, excludeNone)
+	if err != nil {
+		return nil, domain.Meta{}, err
 	}
 
+	var query strings.Builder
+	query.WriteString("SELECT ")
+	query.WriteString(productSummaryColumns)
+	query.WriteString(", COUNT(p.id) OVER()")
+	query.WriteString(productListFrom)
+	query.WriteString(filter.where.String())
+
+	query.WriteString("GROUP BY p.id, c.id, b.id, pi.id, pv.price, pv.effective_price, pv.stock")
+
 	query.WriteString(" ORDER BY ")
-	query.WriteString(sortField)
+	query.WriteString(filter.sortColumn(q.SortField).expr)
 	query.WriteString(" ")
 	query.WriteString(q.SortDirection)
 
-	query.WriteString(" LIMIT $")
-	query.WriteString(strconv.Itoa(paramIndex))
-	params = append(params, q.Limit)
-	paramIndex++
+	query.WriteString(" LIMIT ")
+	query.WriteString(filter.param(q.Limit))
 
-	query.WriteString(" OFFSET $")
-	query.WriteString(strconv.Itoa(paramIndex))
-	params = append(params, q.Offset)
+	query.WriteString(" OFFSET ")
+	query.WriteString(filter.param(q.Offset))
 
 	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
 	defer cancel()
 
 	var products []domain.ProductSummary
 	var count int
-	rows, err := r.db.QueryContext(ctx, query.String(), params...)
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query.String(), filter.params...)
 	if err != nil {
 		return nil, domain.Meta{}, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var product domain.ProductSummary
-		product.Category = &domain.CategorySummary{}
-		product.Brand = &domain.BrandSummary{}
-
-		err = rows.Scan(
-			&product.Id,
-			&product.Name,
-			&product.Slug,
-			&product.Price,
-			&product.SalePrice,
-			&product.Stock,
-			&product.CategoryId,
-			&product.BrandId,
-			&product.Category.Id,
-			&product.Category.Name,
-			&product.Category.Slug,
-			&product.Brand.Id,
-			&product.Brand.Name,
-			&product.Brand.Slug,
-			&count,
-		)
+		product, err := scanProductSummary(rows, &count)
 		if err != nil {
 			return nil, domain.Meta{}, err
 		}
@@ -321,3 +324,187 @@ func (r *ProductRepository) List(ctx context.Context, q domain.PaginatedProducts
 
 	return products, meta, nil
 }
+
+// ListByCursor pages through products with a keyset on the active sort
+// field and id, so deep pages cost the same as the first and rows inserted
+// mid-crawl don't shift later pages.
+func (r *ProductRepository) ListByCursor(ctx context.Context, q domain.PaginatedProductsQuery) ([]domain.ProductSummary, domain.CursorMeta, error) {
+	filter, err := newProductFilter(q, excludeNone)
+	if err != nil {
+		return nil, domain.CursorMeta{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	meta := domain.CursorMeta{PageSize: q.Limit}
+
+	if q.WithTotal {
+		var total int
+		countQuery := "SELECT COUNT(*) " + productListFrom + filter.where.String()
+		if err = postgres.Conn(ctx, r.db).QueryRowContext(ctx, countQuery, filter.params...).Scan(&total); err != nil {
+			return nil, domain.CursorMeta{}, err
+		}
+		meta.TotalItems = &total
+	}
+
+	column := filter.sortColumn(q.SortField)
+	backward := q.Cursor != nil && q.Cursor.Backward
+
+	// Prev cursors walk the listing in reverse; the page is flipped back
+	// into the requested order once fetched.
+	direction := q.SortDirection
+	if backward {
+		direction = reverseDirection(direction)
+	}
+
+	if q.Cursor != nil {
+		operator := ">"
+		if direction == "desc" {
+			operator = "<"
+		}
+		filter.where.WriteString(" AND (" + column.expr + ", p.id) " + operator +
+			" (" + filter.param(q.Cursor.Value) + "::" + column.cast + ", " + filter.param(q.Cursor.Id) + "::bigint) ")
+	}
+
+	var query strings.Builder
+	query.WriteString("SELECT ")
+	query.WriteString(productSummaryColumns)
+	query.WriteString(", (" + column.expr + ")::text")
+	query.WriteString(productListFrom)
+	query.WriteString(filter.where.String())
+
+	query.WriteString("GROUP BY p.id, c.id, b.id, pi.id, pv.price, pv.effective_price, pv.stock")
+
+	query.WriteString(" ORDER BY ")
+	query.WriteString(column.expr + " " + direction + ", p.id " + direction)
+
+	// One extra row tells whether another page follows.
+	query.WriteString(" LIMIT ")
+	query.WriteString(filter.param(q.Limit + 1))
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query.String(), filter.params...)
+	if err != nil {
+		return nil, domain.CursorMeta{}, err
+	}
+	defer rows.Close()
+
+	var products []domain.ProductSummary
+	var keys []string
+	for rows.Next() {
+		var key string
+		product, err := scanProductSummary(rows, &key)
+		if err != nil {
+			return nil, domain.CursorMeta{}, err
+		}
+
+		products = append(products, product)
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, domain.CursorMeta{}, err
+	}
+
+	hasMore := len(products) > q.Limit
+	if hasMore {
+		products, keys = products[:q.Limit], keys[:q.Limit]
+	}
+
+	if backward {
+		slices.Reverse(products)
+		slices.Reverse(keys)
+	}
+
+	if len(products) == 0 {
+		return products, meta, nil
+	}
+
+	cursorAt := func(i int, backward bool) *string {
+		c := domain.ProductCursor{
+			SortField:     q.SortField,
+			SortDirection: q.SortDirection,
+			Value:         keys[i],
+			Id:            products[i].Id,
+			Backward:      backward,
+		}.Encode()
+		return &c
+	}
+
+	if hasMore || backward {
+		meta.NextCursor = cursorAt(len(products)-1, false)
+	}
+	if (hasMore && backward) || (q.Cursor != nil && !backward) {
+		meta.PrevCursor = cursorAt(0, true)
+	}
+
+	return products, meta, nil
+}
+
+// productSummaryColumns are the columns scanProductSummary reads, in order.
+const productSummaryColumns = `
+			p.id, p.name, p.slug, COALESCE(pv.price, p.price), ` + summarySalePrice + `, p.currency, COALESCE(pv.stock, p.stock), p.category_id, p.brand_id,
+			c.id, c.name, c.slug,
+			b.id, b.name, b.slug,
+			pi.thumbnails->>'` + domain.SummaryThumbnail + `',
+			p.updated_at
+`
+
+// scanProductSummary scans productSummaryColumns followed by any extra
+// columns the listing selected.
+func scanProductSummary(rows *sql.Rows, extra ...any) (domain.ProductSummary, error) {
+	var product domain.ProductSummary
+	var prices priceColumns
+	product.Category = &domain.CategorySummary{}
+	product.Brand = &domain.BrandSummary{}
+
+	dest := []any{
+		&product.Id,
+		&product.Name,
+		&product.Slug,
+		&prices.price,
+		&prices.salePrice,
+		&prices.currency,
+		&product.Stock,
+		&product.CategoryId,
+		&product.BrandId,
+		&product.Category.Id,
+		&product.Category.Name,
+		&product.Category.Slug,
+		&product.Brand.Id,
+		&product.Brand.Name,
+		&product.Brand.Slug,
+		&product.Thumbnail,
+		&product.UpdatedAt,
+	}
+
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return product, err
+	}
+
+	if err := prices.apply(&product.BaseProduct); err != nil {
+		return product, err
+	}
+
+	return product, nil
+}
+
+func reverseDirection(direction string) string {
+	if direction == "asc" {
+		return "desc"
+	}
+	return "asc"
+}
+
+// touchProduct moves a product's version and updated_at on when one of its
+// variants or images changes, since those are part of its representation.
+// It runs before the child row is written, so the product row is locked
+// ahead of the child as it is at checkout, and the two can't deadlock.
+func touchProduct(ctx context.Context, tx *postgres.Tx, productId int64) error {
+	query := `
+		UPDATE products SET updated_at = NOW(), version = version + 1 WHERE id = $1;
+	`
+
+	_, err := tx.ExecContext(ctx, query, productId)
+	return err
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+	"slices"
+)
+
+// Facets counts brand, category, price and attribute values over the
+// products matching q. Each facet is computed without its own filter.
+func (r *ProductRepository) Facets(ctx context.Context, q domain.PaginatedProductsQuery) (domain.Facets, error) {
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	var facets domain.Facets
+	var err error
+
+	if q.HasFacet(domain.FacetBrand) {
+		facets.Brands, err = r.countValues(ctx, q, excludeBrands, "b.slug", "b.name", "b.id IS NOT NULL")
+		if err != nil {
+			return facets, err
+		}
+	}
+
+	if q.HasFacet(domain.FacetCategory) {
+		facets.Categories, err = r.countValues(ctx, q, excludeCategories, "c.slug", "c.name", "c.id IS NOT NULL")
+		if err != nil {
+			return facets, err
+		}
+	}
+
+	if q.HasFacet(domain.FacetPrice) {
+		facets.Price, err = r.priceHistogram(ctx, q)
+		if err != nil {
+			return facets, err
+		}
+	}
+
+	if q.HasFacet(domain.FacetAttributes) {
+		facets.Attributes, err = r.attributeFacets(ctx, q)
+		if err != nil {
+			return facets, err
+		}
+	}
+
+	return facets, nil
+}
+
+func (r *ProductRepository) countValues(ctx context.Context, q domain.PaginatedProductsQuery, exclude, valueColumn, labelColumn, condition string) ([]domain.FacetValue, error) {
+	filter, err := newProductFilter(q, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + valueColumn + `, ` + labelColumn + `, COUNT(*)` +
+		productListFrom +
+		filter.where.String() + ` AND ` + condition +
+		` GROUP BY ` + valueColumn + `, ` + labelColumn +
+		` ORDER BY COUNT(*) DESC, ` + labelColumn
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query, filter.params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []domain.FacetValue{}
+	for rows.Next() {
+		var value domain.FacetValue
+		if err = rows.Scan(&value.Value, &value.Label, &value.Count); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// priceHistogram buckets effective prices per currency, matching what the
+// min_price and max_price filters compare against. Bounds are fetched
+// first so bucket edges can be computed exactly in minor units.
+func (r *ProductRepository) priceHistogram(ctx context.Context, q domain.PaginatedProductsQuery) ([]domain.PriceFacet, error) {
+	filter, err := newProductFilter(q, excludePrice)
+	if err != nil {
+		return nil, err
+	}
+
+	boundsQuery := `SELECT p.currency, MIN(` + effectivePrice + `)::text, MAX(` + effectivePrice + `)::text` +
+		productListFrom +
+		filter.where.String() +
+		` GROUP BY p.currency ORDER BY p.currency`
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, boundsQuery, filter.params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	facets := []domain.PriceFacet{}
+	for rows.Next() {
+		var currency, min, max string
+		if err = rows.Scan(&currency, &min, &max); err != nil {
+			return nil, err
+		}
+
+		facet := domain.PriceFacet{Currency: currency}
+		if facet.Min, err = domain.ParseMoney(min, currency); err != nil {
+			return nil, err
+		}
+		if facet.Max, err = domain.ParseMoney(max, currency); err != nil {
+			return nil, err
+		}
+		facet.Buckets = domain.PriceBuckets(facet.Min, facet.Max, domain.PriceHistogramBuckets)
+
+		facets = append(facets, facet)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range facets {
+		if err = r.countPriceBuckets(ctx, q, &facets[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return facets, nil
+}
+
+func (r *ProductRepository) countPriceBuckets(ctx context.Context, q domain.PaginatedProductsQuery, facet *domain.PriceFacet) error {
+	filter, err := newProductFilter(q, excludePrice)
+	if err != nil {
+		return err
+	}
+
+	thresholds := make([]string, 0, len(facet.Buckets))
+	for _, bucket := range facet.Buckets {
+		thresholds = append(thresholds, bucket.From.Decimal())
+	}
+
+	query := `SELECT width_bucket(` + effectivePrice + `, ` + filter.param(pq.Array(thresholds)) + `::numeric[]), COUNT(*)` +
+		productListFrom +
+		filter.where.String() + ` AND p.currency = ` + filter.param(facet.Currency) +
+		` GROUP BY 1`
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query, filter.params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket, count int
+		if err = rows.Scan(&bucket, &count); err != nil {
+			return err
+		}
+		// width_bucket numbers buckets from 1; 0 would mean below the minimum.
+		if bucket >= 1 && bucket <= len(facet.Buckets) {
+			facet.Buckets[bucket-1].Count = count
+		}
+	}
+
+	return rows.Err()
+}
+
+// attributeFacets counts attribute values. Attributes the query filters on
+// are counted separately, each without its own filter, so multi-select
+// keeps working; all others share one query with every filter applied.
+func (r *ProductRepository) attributeFacets(ctx context.Context, q domain.PaginatedProductsQuery) ([]domain.AttributeFacet, error) {
+	var filteredCodes []string
+	for _, filter := range q.Attributes {
+		if !slices.Contains(filteredCodes, filter.Code) {
+			filteredCodes = append(filteredCodes, filter.Code)
+		}
+	}
+
+	facets := []domain.AttributeFacet{}
+
+	for _, code := range filteredCodes {
+		filter, err := newProductFilter(q, excludeNone, code)
+		if err != nil {
+			return nil, err
+		}
+		condition := ` AND ad.code = ` + filter.param(code)
+
+		codeFacets, err := r.countAttributeValues(ctx, filter, condition)
+		if err != nil {
+			return nil, err
+		}
+		facets = append(facets, codeFacets...)
+	}
+
+	filter, err := newProductFilter(q, excludeNone)
+	if err != nil {
+		return nil, err
+	}
+	condition := ` AND ad.code <> ALL(` + filter.param(pq.Array(filteredCodes)) + `::text[])`
+
+	otherFacets, err := r.countAttributeValues(ctx, filter, condition)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(facets, otherFacets...), nil
+}
+
+func (r *ProductRepository) countAttributeValues(ctx context.Context, filter *productFilter, condition string) ([]domain.AttributeFacet, error) {
+	query := `
+		SELECT 
+			ad.code, ad.name, ad.unit,
+			COALESCE(pav.value_text, pav.value_boolean::text, pav.value_number::text) AS value,
+			COUNT(*)
+	` + productListFrom + `
+		JOIN product_attribute_values pav ON pav.product_id = p.id
+		JOIN attribute_definitions ad ON pav.attribute_id = ad.id
+	` + filter.where.String() + condition + ` AND ad.type <> 'text'
+		GROUP BY ad.code, ad.name, ad.unit, value
+		ORDER BY ad.name, ad.code, COUNT(*) DESC, value
+	`
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query, filter.params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	facets := []domain.AttributeFacet{}
+	for rows.Next() {
+		var code, name, value string
+		var unit sql.NullString
+		var count int
+		if err = rows.Scan(&code, &name, &unit, &value, &count); err != nil {
+			return nil, err
+		}
+
+		if len(facets) == 0 || facets[len(facets)-1].Code != code {
+			facet := domain.AttributeFacet{Code: code, Name: name, Values: []domain.FacetValue{}}
+			if unit.Valid {
+				facet.Unit = &unit.String
+			}
+			facets = append(facets, facet)
+		}
+
+		last := &facets[len(facets)-1]
+		last.Values = append(last.Values, domain.FacetValue{Value: value, Label: value, Count: count})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
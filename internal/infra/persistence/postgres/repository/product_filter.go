@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"encoding/json"
+	"github.com/lib/pq"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// productListFrom joins everything product listings filter and sort on.
+// pv holds the price and stock derived from a product's active variants;
+// its effective_price lets variants without their own price inherit the
+// product's sale price. pi is the primary image, of which there is at most
+// one.
+const productListFrom = `
+		FROM products p
+		LEFT JOIN brands b ON p.brand_id = b.id
+		LEFT JOIN categories c ON p.category_id = c.id 
+		LEFT JOIN product_images pi ON pi.product_id = p.id AND pi.is_primary
+		LEFT JOIN LATERAL (
+			SELECT
+				MIN(COALESCE(v.price, p.price)) AS price,
+				MIN(COALESCE(v.price, p.sale_price, p.price)) AS effective_price,
+				SUM(v.stock) AS stock
+			FROM product_variants v
+			WHERE v.product_id = p.id AND v.is_active = true
+		) pv ON true
+`
+
+// effectivePrice is what a product actually sells for: its cheapest
+// variant, otherwise its sale price, otherwise its regular price.
+const effectivePrice = "COALESCE(pv.effective_price, p.sale_price, p.price)"
+
+// summarySalePrice mirrors Product.DeriveFromVariants: with variants, the
+// sale price is their lowest effective price when it undercuts their
+// lowest regular price.
+const summarySalePrice = "CASE WHEN pv.price IS NULL THEN p.sale_price WHEN pv.effective_price < pv.price THEN pv.effective_price END"
+
+// Filters that can be left out of a productFilter, so facets can count
+// values as if their own selection had not been made.
+const (
+	excludeNone       = ""
+	excludeBrands     = "brands"
+	excludeCategories = "categories"
+	excludePrice      = "price"
+	excludeAttributes = "attributes"
+)
+
+type productFilter struct {
+	where  strings.Builder
+	params []any
+	search string
+}
+
+// param registers a query parameter and returns its placeholder.
+func (f *productFilter) param(value any) string {
+	f.params = append(f.params, value)
+	return "$" + strconv.Itoa(len(f.params))
+}
+
+// newProductFilter builds the WHERE clause shared by product listings and
+// their facets. exclude names a filter to leave out; attribute filters can
+// also be skipped one code at a time via excludeCodes.
+func newProductFilter(q domain.PaginatedProductsQuery, exclude string, excludeCodes ...string) (*productFilter, error) {
+	f := &productFilter{}
+
+	f.where.WriteString(" WHERE p.is_active = true ")
+
+	if q.Search != "" {
+		f.search = f.param(q.Search)
+		// Names also match despite typos through <%, whose cutoff is the
+		// database's pg_trgm.word_similarity_threshold; unlike a
+		// word_similarity() comparison it can use the trigram index.
+		f.where.WriteString(`
+			AND (
+				p.search_vector @@ websearch_to_tsquery('english', ` + f.search + `)
+				OR ` + f.search + ` <% p.name
+			)
+		`)
+	}
+
+	if len(q.Categories) > 0 && exclude != excludeCategories {
+		f.where.WriteString(`
+			AND p.category_id IN (
+				WITH RECURSIVE category_tree AS (
+					SELECT id FROM categories
+					WHERE slug = ANY(` + f.param(pq.Array(q.Categories)) + `)
+					UNION
+					SELECT child.id FROM categories child
+					JOIN category_tree ct ON child.parent_id = ct.id
+				)
+				SELECT id FROM category_tree
+			)
+		`)
+	}
+
+	if len(q.Brands) > 0 && exclude != excludeBrands {
+		f.where.WriteString(" AND b.slug = ANY(" + f.param(pq.Array(q.Brands)) + ") ")
+	}
+
+	if (q.MinPrice != nil || q.MaxPrice != nil) && exclude != excludePrice {
+		if q.MinPrice != nil {
+			f.where.WriteString(" AND p.currency = " + f.param(q.MinPrice.Currency))
+			f.where.WriteString(" AND " + effectivePrice + " >= " + f.param(q.MinPrice.Decimal()) + "::numeric ")
+		}
+		if q.MaxPrice != nil {
+			f.where.WriteString(" AND p.currency = " + f.param(q.MaxPrice.Currency))
+			f.where.WriteString(" AND " + effectivePrice + " <= " + f.param(q.MaxPrice.Decimal()) + "::numeric ")
+		}
+	}
+
+	if q.InStock {
+		f.where.WriteString(" AND COALESCE(pv.stock, p.stock) > 0 ")
+	}
+
+	if q.OnSale {
+		f.where.WriteString(" AND " + effectivePrice + " < COALESCE(pv.price, p.price) ")
+	}
+
+	if q.CreatedAfter != nil {
+		f.where.WriteString(" AND p.created_at > " + f.param(*q.CreatedAfter) + " ")
+	}
+
+	if q.Sku != "" || len(q.Options) > 0 {
+		f.where.WriteString(`
+			AND EXISTS (
+				SELECT 1 FROM product_variants v
+				WHERE v.product_id = p.id AND v.is_active = true
+		`)
+		if q.Sku != "" {
+			f.where.WriteString(" AND v.sku = " + f.param(q.Sku))
+		}
+		if len(q.Options) > 0 {
+			options, err := json.Marshal(q.Options)
+			if err != nil {
+				return nil, err
+			}
+			f.where.WriteString(" AND v.options @> " + f.param(string(options)) + "::jsonb")
+		}
+		f.where.WriteString(") ")
+	}
+
+	if exclude != excludeAttributes {
+		for _, filter := range q.Attributes {
+			if slices.Contains(excludeCodes, filter.Code) {
+				continue
+			}
+			f.where.WriteString(f.attributeCondition(filter))
+		}
+	}
+
+	return f, nil
+}
+
+// relevance returns the expression ranking products against the search
+// text: full-text rank weighted name > brand > category > description, plus
+// trigram similarity so near misses still rank. Without a search it falls
+// back to the product name.
+func (f *productFilter) relevance() string {
+	if f.search == "" {
+		return "p.name"
+	}
+	return "(ts_rank(p.search_vector, websearch_to_tsquery('english', " + f.search + ")) + word_similarity(" + f.search + ", p.name))"
+}
+
+// sortColumn is the expression a product listing is ordered by and the
+// type cursor values for it are cast back to.
+type sortColumn struct {
+	expr string
+	cast string
+}
+
+// sortColumn returns the column for a sort field, falling back to the
+// product name for unknown fields and for relevance without a search.
+func (f *productFilter) sortColumn(field string) sortColumn {
+	switch {
+	case field == "price":
+		return sortColumn{expr: "COALESCE(pv.price, p.price)", cast: "numeric"}
+	case field == "effective_price":
+		return sortColumn{expr: effectivePrice, cast: "numeric"}
+	case field == "stock":
+		return sortColumn{expr: "COALESCE(pv.stock, p.stock)", cast: "numeric"}
+	case field == "created_at":
+		return sortColumn{expr: "p.created_at", cast: "timestamptz"}
+	case field == "relevance" && f.search != "":
+		return sortColumn{expr: f.relevance(), cast: "real"}
+	default:
+		return sortColumn{expr: "p.name", cast: "text"}
+	}
+}
+
+// attributeCondition builds the WHERE fragment for a specification filter.
+// Range operators compare numeric values; equality matches the stored value
+// whatever its type.
+func (f *productFilter) attributeCondition(filter domain.AttributeFilter) string {
+	code := f.param(filter.Code)
+	value := f.param(filter.Value)
+
+	var match string
+	if filter.IsNumeric() {
+		match = "pav.value_number " + string(filter.Operator) + " " + value + "::numeric"
+	} else {
+		match = "(pav.value_text = " + value +
+			" OR pav.value_boolean::text = " + value +
+			" OR (pav.value_number IS NOT NULL AND pav.value_number::text = " + value + "))"
+	}
+
+	exists := " AND EXISTS "
+	if filter.Operator == domain.AttributeOperatorNeq {
+		exists = " AND NOT EXISTS "
+	}
+
+	return exists + `(
+		SELECT 1 FROM product_attribute_values pav
+		JOIN attribute_definitions ad ON pav.attribute_id = ad.id
+		WHERE pav.product_id = p.id AND ad.code = ` + code + ` AND ` + match + `
+	) `
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+	"strings"
+)
+
+type SearchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{
+		db: db,
+	}
+}
+
+// Suggest returns active products, categories and brands whose names
+// contain the query, names starting with it first. The ILIKE patterns are
+// served by the trigram indexes on the name columns.
+func (r *SearchRepository) Suggest(ctx context.Context, q domain.SuggestQuery) (*domain.Suggestions, error) {
+	query := `
+		(
+			SELECT 'product', id, name, slug FROM products
+			WHERE is_active = true AND name ILIKE '%' || $1 || '%'
+			ORDER BY name ILIKE $1 || '%' DESC, similarity(name, $2) DESC, name
+			LIMIT $3
+		)
+		UNION ALL
+		(
+			SELECT 'category', id, name, slug FROM categories
+			WHERE is_active = true AND name ILIKE '%' || $1 || '%'
+			ORDER BY name ILIKE $1 || '%' DESC, similarity(name, $2) DESC, name
+			LIMIT $3
+		)
+		UNION ALL
+		(
+			SELECT 'brand', id, name, slug FROM brands
+			WHERE is_active = true AND name ILIKE '%' || $1 || '%'
+			ORDER BY name ILIKE $1 || '%' DESC, similarity(name, $2) DESC, name
+			LIMIT $3
+		);
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query, escapeLike(q.Query), q.Query, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := &domain.Suggestions{
+		Products:   []domain.ProductSuggestion{},
+		Categories: []domain.CategorySummary{},
+		Brands:     []domain.BrandSummary{},
+	}
+	for rows.Next() {
+		var kind, name, slug string
+		var id int64
+		if err = rows.Scan(&kind, &id, &name, &slug); err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case "product":
+			suggestions.Products = append(suggestions.Products, domain.ProductSuggestion{Id: id, Name: name, Slug: slug})
+		case "category":
+			suggestions.Categories = append(suggestions.Categories, domain.CategorySummary{Id: id, Name: name, Slug: slug})
+		case "brand":
+			suggestions.Brands = append(suggestions.Brands, domain.BrandSummary{Id: id, Name: name, Slug: slug})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes user input match literally inside a LIKE pattern.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
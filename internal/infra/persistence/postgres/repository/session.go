@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+)
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+func (r *SessionRepository) GetById(ctx context.Context, id int64) (*domain.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE id = $1;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	session, err := scanSession(postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, domain.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return session, nil
+}
+
+func (r *SessionRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `
+		SELECT 
+			id, session_id, token_hash, expires_at, used_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	var token domain.RefreshToken
+	err := postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.Id,
+		&token.SessionId,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, domain.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &token, nil
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *domain.Session, token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO 
+		    sessions (user_id, user_agent, ip, two_factor, expires_at)
+		VALUES 
+		    ($1, $2, $3, $4, $5)
+		RETURNING
+			id, created_at, last_seen_at;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		session.UserId,
+		session.UserAgent,
+		session.Ip,
+		session.TwoFactor,
+		session.ExpiresAt,
+	).Scan(&session.Id, &session.CreatedAt, &session.LastSeenAt)
+	if err != nil {
+		return err
+	}
+
+	token.SessionId = session.Id
+	if err = insertRefreshToken(ctx, tx, token); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Rotate spends the used token and stores its successor. The spend only
+// succeeds once, so two requests racing with the same token can't both
+// get a new one.
+func (r *SessionRepository) Rotate(ctx context.Context, used *domain.RefreshToken, next *domain.RefreshToken, client domain.SessionClient) error {
+	spendQuery := `
+		UPDATE refresh_tokens SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL;
+	`
+
+	touchQuery := `
+		UPDATE sessions 
+		SET last_seen_at = NOW(), ip = $1, user_agent = $2, expires_at = $3
+		WHERE id = $4;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, spendQuery, used.Id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrConflict
+	}
+
+	if err = insertRefreshToken(ctx, tx, next); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, touchQuery, client.Ip, client.UserAgent, next.ExpiresAt, next.SessionId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SessionRepository) ListActive(ctx context.Context, userId int64) ([]domain.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_seen_at DESC;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := postgres.Conn(ctx, r.db).QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []domain.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, userId, sessionId int64) error {
+	query := `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, sessionId, userId)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeAll(ctx context.Context, userId int64) (int64, error) {
+	query := `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+const sessionColumns = `
+	id, user_id, user_agent, ip, two_factor, created_at, last_seen_at, expires_at, revoked_at
+`
+
+func scanSession(row rowScanner) (*domain.Session, error) {
+	var session domain.Session
+
+	err := row.Scan(
+		&session.Id,
+		&session.UserId,
+		&session.UserAgent,
+		&session.Ip,
+		&session.TwoFactor,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func insertRefreshToken(ctx context.Context, tx *postgres.Tx, token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO 
+		    refresh_tokens (session_id, token_hash, expires_at)
+		VALUES 
+		    ($1, $2, $3)
+		RETURNING
+			id, created_at;
+	`
+
+	return tx.QueryRowContext(ctx, query, token.SessionId, token.TokenHash, token.ExpiresAt).Scan(&token.Id, &token.CreatedAt)
+}
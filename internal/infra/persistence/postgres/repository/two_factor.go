@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+)
+
+type TwoFactorRepository struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		db: db,
+	}
+}
+
+func (r *TwoFactorRepository) GetTOTP(ctx context.Context, userId int64) (*domain.TOTPEnrolment, error) {
+	query := `
+		SELECT 
+			user_id, secret, confirmed_at, last_used_step
+		FROM user_totp
+		WHERE user_id = $1;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	var enrolment domain.TOTPEnrolment
+	err := postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, userId).Scan(
+		&enrolment.UserId,
+		&enrolment.Secret,
+		&enrolment.ConfirmedAt,
+		&enrolment.LastUsedStep,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, domain.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &enrolment, nil
+}
+
+// SaveTOTP never touches a confirmed enrolment, so a stray enrol request
+// can't swap the secret out from under an active one.
+func (r *TwoFactorRepository) SaveTOTP(ctx context.Context, enrolment *domain.TOTPEnrolment) error {
+	query := `
+		INSERT INTO 
+		    user_totp (user_id, secret)
+		VALUES 
+		    ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, created_at = NOW()
+		WHERE user_totp.confirmed_at IS NULL;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, enrolment.UserId, enrolment.Secret)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrTwoFactorAlreadyActive
+	}
+
+	return nil
+}
+
+func (r *TwoFactorRepository) ConfirmTOTP(ctx context.Context, userId, step int64, codes []domain.RecoveryCode) error {
+	query := `
+		UPDATE user_totp SET confirmed_at = NOW(), last_used_step = $2
+		WHERE user_id = $1 AND confirmed_at IS NULL;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, query, userId, step)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrConflict
+	}
+
+	if err = replaceRecoveryCodes(ctx, tx, userId, codes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TwoFactorRepository) DeleteTOTP(ctx context.Context, userId int64) error {
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1;`, userId); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1;`, userId)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep only moves forward, so of two requests racing with the same
+// code only one gets through.
+func (r *TwoFactorRepository) UseTOTPStep(ctx context.Context, userId, step int64) error {
+	query := `
+		UPDATE user_totp SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, userId, step)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrConflict
+	}
+
+	return nil
+}
+
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userId int64, codes []domain.RecoveryCode) error {
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = replaceRecoveryCodes(ctx, tx, userId, codes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userId int64, codeHash string) error {
+	query := `
+		UPDATE recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, userId, codeHash)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *postgres.Tx, userId int64, codes []domain.RecoveryCode) error {
+	insertQuery := `
+		INSERT INTO 
+		    recovery_codes (user_id, code_hash)
+		VALUES 
+		    ($1, $2)
+		RETURNING
+			id;
+	`
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1;`, userId); err != nil {
+		return err
+	}
+
+	for i := range codes {
+		if err := tx.QueryRowContext(ctx, insertQuery, userId, codes[i].CodeHash).Scan(&codes[i].Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{
+		db: db,
+	}
+}
+
+func (r *UserRepository) GetById(ctx context.Context, id int64) (*domain.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = $1;
+	`
+	return r.get(ctx, query, id)
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE email = $1;
+	`
+	return r.get(ctx, query, email)
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	query := `
+		INSERT INTO 
+		    users (email, name, role, password_hash)
+		VALUES 
+		    ($1, $2, $3, $4)
+		RETURNING
+			id, created_at, updated_at;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	err := postgres.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		user.Email,
+		user.Name,
+		user.Role,
+		user.PasswordHash,
+	).Scan(&user.Id, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err, "users_email_unique"):
+			return fmt.Errorf("%w: email %q is already registered", domain.ErrConflict, user.Email)
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	query := `
+		UPDATE users
+		SET email = $1, name = $2, password_hash = $3, email_verified_at = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING updated_at;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	err := postgres.Conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		user.Email,
+		user.Name,
+		user.PasswordHash,
+		user.EmailVerifiedAt,
+		user.Id,
+	).Scan(&user.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return domain.ErrNotFound
+		case isUniqueViolation(err, "users_email_unique"):
+			return fmt.Errorf("%w: email %q is already registered", domain.ErrConflict, user.Email)
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+const userColumns = `
+	id, email, name, role, email_verified_at, password_hash, created_at, updated_at
+`
+
+func (r *UserRepository) get(ctx context.Context, query string, args ...any) (*domain.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	var user domain.User
+	err := postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
+		&user.Id,
+		&user.Email,
+		&user.Name,
+		&user.Role,
+		&user.EmailVerifiedAt,
+		&user.PasswordHash,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, domain.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
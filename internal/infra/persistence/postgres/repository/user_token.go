@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+)
+
+type UserTokenRepository struct {
+	db *sql.DB
+}
+
+func NewUserTokenRepository(db *sql.DB) *UserTokenRepository {
+	return &UserTokenRepository{
+		db: db,
+	}
+}
+
+// Create writes the token and its email together, so an email is queued
+// exactly when its token exists. Only the newest token of each kind works.
+func (r *UserTokenRepository) Create(ctx context.Context, token *domain.UserToken, email *domain.Email) error {
+	voidQuery := `
+		UPDATE user_tokens SET used_at = NOW()
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL;
+	`
+
+	insertQuery := `
+		INSERT INTO 
+		    user_tokens (user_id, purpose, token_hash, expires_at, email)
+		VALUES 
+		    ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING
+			id, created_at;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, voidQuery, token.UserId, token.Purpose); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		insertQuery,
+		token.UserId,
+		token.Purpose,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.Email,
+	).Scan(&token.Id, &token.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if email != nil {
+		if err = enqueueEmail(ctx, tx, email); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Consume spends the token in the same statement that finds it, so it
+// can't be used twice however many requests race for it.
+func (r *UserTokenRepository) Consume(ctx context.Context, purpose domain.UserTokenPurpose, tokenHash string) (*domain.UserToken, error) {
+	query := `
+		UPDATE user_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING
+			id, user_id, purpose, token_hash, COALESCE(email, ''), expires_at, used_at, created_at;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	var token domain.UserToken
+	err := postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, tokenHash, purpose).Scan(
+		&token.Id,
+		&token.UserId,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.Email,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, domain.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &token, nil
+}
@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/skiba-mateusz/ecom-api/internal/app/domain"
+	"github.com/skiba-mateusz/ecom-api/internal/infra/persistence/postgres"
+)
+
+type VariantRepository struct {
+	db *sql.DB
+}
+
+func NewVariantRepository(db *sql.DB) *VariantRepository {
+	return &VariantRepository{
+		db: db,
+	}
+}
+
+func (r *VariantRepository) GetById(ctx context.Context, productId, id int64) (*domain.ProductVariant, error) {
+	query := `
+		SELECT 
+			v.id, v.product_id, v.sku, v.barcode, v.price, p.currency, v.stock, v.options
+		FROM product_variants v
+		JOIN products p ON v.product_id = p.id
+		WHERE v.id = $1 AND v.product_id = $2 AND v.is_active = true AND p.is_active = true;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	variant, err := scanVariant(postgres.Conn(ctx, r.db).QueryRowContext(ctx, query, id, productId))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, domain.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return variant, nil
+}
+
+func (r *VariantRepository) ListByProduct(ctx context.Context, productId int64) ([]domain.ProductVariant, error) {
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	return listVariants(ctx, postgres.Conn(ctx, r.db), productId)
+}
+
+func (r *VariantRepository) Create(ctx context.Context, variant *domain.ProductVariant) error {
+	query := `
+		INSERT INTO 
+		    product_variants (product_id, sku, barcode, price, stock, options)
+		VALUES 
+		    ($1, $2, $3, $4, $5, $6)
+		RETURNING
+			id;
+	`
+
+	options, err := json.Marshal(variant.Options)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = touchProduct(ctx, tx, variant.ProductId); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		variant.ProductId,
+		variant.Sku,
+		variant.Barcode,
+		nullDecimal(variant.Price),
+		variant.Stock,
+		string(options),
+	).Scan(&variant.Id)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err, "product_variants_sku_unique"):
+			return fmt.Errorf("%w: sku %q is already taken", domain.ErrConflict, variant.Sku)
+		default:
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *VariantRepository) Update(ctx context.Context, variant *domain.ProductVariant) error {
+	query := `
+		UPDATE 
+		    product_variants 
+		SET
+		    sku = $1,
+		    barcode = $2,
+		    price = $3,
+		    stock = $4,
+		    options = $5,
+		    updated_at = NOW()
+		WHERE 
+		    id = $6 AND product_id = $7 AND is_active = true
+	`
+
+	options, err := json.Marshal(variant.Options)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = touchProduct(ctx, tx, variant.ProductId); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		query,
+		variant.Sku,
+		variant.Barcode,
+		nullDecimal(variant.Price),
+		variant.Stock,
+		string(options),
+		variant.Id,
+		variant.ProductId,
+	)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err, "product_variants_sku_unique"):
+			return fmt.Errorf("%w: sku %q is already taken", domain.ErrConflict, variant.Sku)
+		default:
+			return err
+		}
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+func (r *VariantRepository) Delete(ctx context.Context, productId, id int64) error {
+	query := `
+		UPDATE product_variants SET is_active = false, updated_at = NOW() 
+		WHERE id = $1 AND product_id = $2 AND is_active = true;
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	tx, err := postgres.BeginTx(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = touchProduct(ctx, tx, productId); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, query, id, productId)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+func (r *VariantRepository) SetOptions(ctx context.Context, productId int64, options []domain.ProductOption) error {
+	query := `
+		UPDATE products SET options = $1, updated_at = NOW(), version = version + 1 WHERE id = $2 AND is_active = true;
+	`
+
+	if options == nil {
+		options = []domain.ProductOption{}
+	}
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postgres.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := postgres.Conn(ctx, r.db).ExecContext(ctx, query, string(data), productId)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// listVariants is shared with ProductRepository, which loads variants as
+// part of the product aggregate.
+func listVariants(ctx context.Context, db queryer, productId int64) ([]domain.ProductVariant, error) {
+	query := `
+		SELECT 
+			v.id, v.product_id, v.sku, v.barcode, v.price, p.currency, v.stock, v.options
+		FROM product_variants v
+		JOIN products p ON v.product_id = p.id
+		WHERE v.product_id = $1 AND v.is_active = true
+		ORDER BY v.id;
+	`
+
+	rows, err := db.QueryContext(ctx, query, productId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []domain.ProductVariant{}
+	for rows.Next() {
+		variant, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, *variant)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return variants, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx, so helpers can read
+// inside or outside a transaction.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func scanVariant(row rowScanner) (*domain.ProductVariant, error) {
+	var variant domain.ProductVariant
+	var price sql.NullString
+	var currency string
+	var options []byte
+
+	err := row.Scan(
+		&variant.Id,
+		&variant.ProductId,
+		&variant.Sku,
+		&variant.Barcode,
+		&price,
+		&currency,
+		&variant.Stock,
+		&options,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if price.Valid {
+		money, err := domain.ParseMoney(price.String, currency)
+		if err != nil {
+			return nil, err
+		}
+		variant.Price = &money
+	}
+
+	if err = json.Unmarshal(options, &variant.Options); err != nil {
+		return nil, err
+	}
+
+	return &variant, nil
+}
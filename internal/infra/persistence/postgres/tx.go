@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// maxTxAttempts is how many times a unit of work runs before a
+	// serialization failure is given up on and returned.
+	maxTxAttempts = 3
+
+	savepoint = "repository"
+)
+
+type txKey struct{}
+
+// DBTX is what repositories run queries on: the pool, or the transaction
+// of the unit of work the call is part of.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Conn returns the transaction carried by ctx, or db outside one.
+func Conn(ctx context.Context, db *sql.DB) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// Tx is a transaction a repository method opens for its own statements.
+// Inside a unit of work it is a savepoint in the surrounding transaction
+// instead, so committing it only commits once the unit of work does.
+type Tx struct {
+	*sql.Tx
+	ctx    context.Context
+	nested bool
+	done   bool
+}
+
+func BeginTx(ctx context.Context, db *sql.DB) (*Tx, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+			return nil, err
+		}
+		return &Tx{Tx: tx, ctx: ctx, nested: true}, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, ctx: ctx}, nil
+}
+
+func (t *Tx) Commit() error {
+	if !t.nested {
+		return t.Tx.Commit()
+	}
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	_, err := t.Tx.ExecContext(t.ctx, "RELEASE SAVEPOINT "+savepoint)
+	return err
+}
+
+// Rollback is safe to defer; after Commit it does nothing.
+func (t *Tx) Rollback() error {
+	if !t.nested {
+		return t.Tx.Rollback()
+	}
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	_, err := t.Tx.ExecContext(t.ctx, "ROLLBACK TO SAVEPOINT "+savepoint)
+	return err
+}
+
+// Transactor runs units of work in serializable transactions.
+type Transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{
+		db: db,
+	}
+}
+
+// WithinTransaction runs fn in a transaction that repositories called with
+// the ctx it is given take part in. It commits when fn returns nil and
+// rolls back otherwise. A transaction that loses a serialization conflict
+// is run again from the start, so fn must not have effects outside the
+// database. Called inside another unit of work, fn joins it.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	return Retry(ctx, func() error {
+		return t.run(ctx, fn)
+	})
+}
+
+// Retry runs fn, which opens and finishes its own transaction, again when
+// it loses a serialization conflict or deadlock, up to maxTxAttempts times.
+// Inside a unit of work fn runs once: the failure aborts the surrounding
+// transaction, so only the unit of work can retry.
+func Retry(ctx context.Context, fn func() error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn()
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		if err = fn(); !isSerializationFailure(err) || attempt == maxTxAttempts {
+			return err
+		}
+
+		backoff := time.Duration(attempt*10+rand.IntN(10)) * time.Millisecond
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+	return err
+}
+
+func (t *Transactor) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := t.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// isSerializationFailure reports whether err is Postgres giving up on a
+// transaction that a retry may well get through.
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	// serialization_failure, deadlock_detected
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
@@ -0,0 +1,259 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+// fakeDB records the statements run against it and fails commits with
+// commitErrs, in order, so transactions can be tested without Postgres.
+type fakeDB struct {
+	mu         sync.Mutex
+	log        []string
+	commitErrs []error
+}
+
+func newFakeDB(t *testing.T, commitErrs ...error) (*sql.DB, *fakeDB) {
+	fake := &fakeDB{commitErrs: commitErrs}
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() { db.Close() })
+	return db, fake
+}
+
+func (f *fakeDB) record(statement string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, statement)
+}
+
+func (f *fakeDB) statements() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.log...)
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return f }
+func (f *fakeDB) Open(string) (driver.Conn, error)             { return &fakeConn{db: f}, nil }
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if sql.IsolationLevel(opts.Isolation) == sql.LevelSerializable {
+		c.db.record("BEGIN SERIALIZABLE")
+	} else {
+		c.db.record("BEGIN")
+	}
+	return &fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (t *fakeTx) Commit() error {
+	t.db.record("COMMIT")
+
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	if len(t.db.commitErrs) == 0 {
+		return nil
+	}
+	err := t.db.commitErrs[0]
+	t.db.commitErrs = t.db.commitErrs[1:]
+	return err
+}
+
+func (t *fakeTx) Rollback() error {
+	t.db.record("ROLLBACK")
+	return nil
+}
+
+func TestWithinTransaction(t *testing.T) {
+	t.Run("should_retry_serialization_failures_and_deadlocks", func(t *testing.T) {
+		db, _ := newFakeDB(t, &pq.Error{Code: "40001"}, &pq.Error{Code: "40P01"})
+
+		attempts := 0
+		err := NewTransactor(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+			attempts++
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("should_give_up_after_max_attempts", func(t *testing.T) {
+		db, _ := newFakeDB(t, &pq.Error{Code: "40001"}, &pq.Error{Code: "40001"}, &pq.Error{Code: "40001"}, &pq.Error{Code: "40001"})
+
+		attempts := 0
+		err := NewTransactor(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+			attempts++
+			return nil
+		})
+
+		var pqErr *pq.Error
+		assert.ErrorAs(t, err, &pqErr)
+		assert.Equal(t, maxTxAttempts, attempts)
+	})
+
+	t.Run("should_roll_back_without_retrying_other_errors", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+		fail := errors.New("boom")
+
+		attempts := 0
+		err := NewTransactor(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+			attempts++
+			return fail
+		})
+
+		assert.ErrorIs(t, err, fail)
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, []string{"BEGIN SERIALIZABLE", "ROLLBACK"}, fake.statements())
+	})
+
+	t.Run("should_join_outer_unit_of_work", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+		transactor := NewTransactor(db)
+
+		err := transactor.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			outer := Conn(ctx, db)
+			return transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+				assert.Same(t, outer, Conn(ctx, db))
+				_, err := Conn(ctx, db).ExecContext(ctx, "UPDATE inner")
+				return err
+			})
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"BEGIN SERIALIZABLE", "UPDATE inner", "COMMIT"}, fake.statements())
+	})
+}
+
+func TestBeginTx(t *testing.T) {
+	t.Run("should_begin_own_transaction_outside_unit_of_work", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+
+		tx, err := BeginTx(context.Background(), db)
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+
+		assert.Equal(t, []string{"BEGIN", "COMMIT"}, fake.statements())
+	})
+
+	t.Run("should_release_savepoint_on_nested_commit", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+
+		err := NewTransactor(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+			tx, err := BeginTx(ctx, db)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback()
+
+			if _, err = tx.ExecContext(ctx, "INSERT nested"); err != nil {
+				return err
+			}
+			return tx.Commit()
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"BEGIN SERIALIZABLE",
+			"SAVEPOINT " + savepoint,
+			"INSERT nested",
+			"RELEASE SAVEPOINT " + savepoint,
+			"COMMIT",
+		}, fake.statements())
+	})
+
+	t.Run("should_roll_back_to_savepoint_and_keep_outer_transaction", func(t *testing.T) {
+		db, fake := newFakeDB(t)
+
+		err := NewTransactor(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+			tx, err := BeginTx(ctx, db)
+			if err != nil {
+				return err
+			}
+			if _, err = tx.ExecContext(ctx, "INSERT nested"); err != nil {
+				return err
+			}
+			if err = tx.Rollback(); err != nil {
+				return err
+			}
+			assert.ErrorIs(t, tx.Commit(), sql.ErrTxDone)
+
+			_, err = Conn(ctx, db).ExecContext(ctx, "UPDATE outer")
+			return err
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"BEGIN SERIALIZABLE",
+			"SAVEPOINT " + savepoint,
+			"INSERT nested",
+			"ROLLBACK TO SAVEPOINT " + savepoint,
+			"UPDATE outer",
+			"COMMIT",
+		}, fake.statements())
+	})
+}
+
+func TestRetry(t *testing.T) {
+	t.Run("should_rerun_own_transaction_after_deadlock", func(t *testing.T) {
+		db, fake := newFakeDB(t, &pq.Error{Code: "40P01"})
+
+		attempts := 0
+		err := Retry(context.Background(), func() error {
+			attempts++
+			tx, err := BeginTx(context.Background(), db)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback()
+			return tx.Commit()
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+		assert.Equal(t, []string{"BEGIN", "COMMIT", "BEGIN", "COMMIT"}, fake.statements())
+	})
+
+	t.Run("should_leave_retrying_to_unit_of_work", func(t *testing.T) {
+		db, _ := newFakeDB(t)
+		deadlock := &pq.Error{Code: "40P01"}
+
+		attempts := 0
+		err := NewTransactor(db).WithinTransaction(context.Background(), func(ctx context.Context) error {
+			return Retry(ctx, func() error {
+				attempts++
+				if attempts == 1 {
+					return deadlock
+				}
+				return nil
+			})
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+	})
+}
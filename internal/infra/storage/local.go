@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage keeps files in a directory on disk. The directory is
+// expected to be served at baseUrl.
+type LocalStorage struct {
+	dir     string
+	baseUrl string
+}
+
+func NewLocalStorage(dir, baseUrl string) *LocalStorage {
+	return &LocalStorage{
+		dir:     dir,
+		baseUrl: strings.TrimSuffix(baseUrl, "/"),
+	}
+}
+
+func (s *LocalStorage) Save(ctx context.Context, key string, content io.Reader, contentType string) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err = io.Copy(file, content); err != nil {
+		return "", err
+	}
+
+	return s.baseUrl + "/" + key, file.Close()
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// path resolves key inside the storage directory, refusing keys that
+// would escape it.
+func (s *LocalStorage) path(key string) (string, error) {
+	if !fs.ValidPath(key) {
+		return "", fs.ErrInvalid
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}